@@ -60,18 +60,22 @@ func AddMultipleHotels() error {
 		name := fmt.Sprintf("%s %s", prefixes[rand.Intn(len(prefixes))], suffixes[rand.Intn(len(suffixes))])
 		desc := fmt.Sprintf("%s located in the heart of %s. Comfortable rooms, free WiFi and great service.", name, c.Name)
 
+		standardCost := float32(1500 + rand.Intn(8500)) // ₹1500 - ₹10000
 		req := hotelsystem.AddHotelRequest{
-			Name:         name,
-			Description:  desc,
-			Images:       imageFor(c.Name),
-			CostPerNight: int64(1500 + rand.Intn(8500)), // ₹1500 - ₹10000
-			TotalRooms:   int64(10 + rand.Intn(90)),     // 10 - 99 rooms
-			Street:       streets[rand.Intn(len(streets))],
-			Landmark:     landmarks[rand.Intn(len(landmarks))],
-			Locality:     localities[rand.Intn(len(localities))],
-			City:         c.Name,
-			State:        c.State,
-			Pincode:      fmt.Sprintf("%06d", 400000+rand.Intn(99999)), // string field in request is fine (struct has string pincode)
+			Name:        name,
+			Description: desc,
+			Images:      imageFor(c.Name),
+			Street:      streets[rand.Intn(len(streets))],
+			Landmark:    landmarks[rand.Intn(len(landmarks))],
+			Locality:    localities[rand.Intn(len(localities))],
+			City:        c.Name,
+			State:       c.State,
+			Pincode:     fmt.Sprintf("%06d", 400000+rand.Intn(99999)), // string field in request is fine (struct has string pincode)
+			RoomTypes: []*hotelsystem.AddRoomTypeRequest{
+				{Name: "Standard", TotalRooms: int64(10 + rand.Intn(60)), MaxOccupancy: 2, CostPerNight: standardCost},
+				{Name: "Deluxe", TotalRooms: int64(5 + rand.Intn(25)), MaxOccupancy: 3, CostPerNight: standardCost * 1.5},
+				{Name: "Suite", TotalRooms: int64(1 + rand.Intn(5)), MaxOccupancy: 4, CostPerNight: standardCost * 3},
+			},
 		}
 
 		// Note: your AddHotelRequest declares Pincode as string in earlier snippet — above we produce string.
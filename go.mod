@@ -12,6 +12,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/robfig/cron/v3 v3.0.1
 	github.com/stretchr/testify v1.10.0
+	github.com/stripe/stripe-go/v82 v82.5.0
 	golang.org/x/crypto v0.39.0
 	google.golang.org/protobuf v1.36.2
 )
@@ -19,6 +20,5 @@ require (
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stripe/stripe-go/v82 v82.5.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,152 @@
+-- Upgrade steps for databases created from an earlier schema.sql. A new database gets its final shape
+-- from schema.sql alone and needs none of this.
+--
+-- To upgrade, first create the tables and indexes that schema.sql has and the database lacks, then run
+-- the steps below that the database has not had yet, in order and in one transaction. Each step fills
+-- in the rows already in a table before adding the constraints that need them.
+
+
+-- ROOM TYPES: bookings.room_type_id
+-- Every hotel created before room types gets a single Standard room type, and its bookings move to it
+INSERT INTO public.room_types (hotel_id, name, total_rooms, max_occupancy, cost_per_night, image_urls)
+SELECT h.id, 'Standard', h.total_rooms, 2, h.cost_per_night, h.image_urls
+FROM public.hotels h
+WHERE NOT EXISTS (SELECT 1 FROM public.room_types rt WHERE rt.hotel_id = h.id);
+
+ALTER TABLE public.bookings ADD COLUMN room_type_id integer;
+
+UPDATE public.bookings b
+SET room_type_id = (SELECT MIN(rt.id) FROM public.room_types rt WHERE rt.hotel_id = b.hotel_id)
+WHERE b.room_type_id IS NULL;
+
+ALTER TABLE public.bookings ALTER COLUMN room_type_id SET NOT NULL;
+
+ALTER TABLE public.bookings
+  ADD CONSTRAINT fk_bookings_room_types FOREIGN KEY (room_type_id)
+    REFERENCES public.room_types (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+
+-- HOTEL INVENTORY: nights already sold
+-- Bookings made before the inventory calendar still hold their rooms; without this they would be sold again
+INSERT INTO public.hotel_inventory (hotel_id, room_type_id, stay_date, rooms_sold)
+SELECT b.hotel_id, b.room_type_id, night::date, SUM(b.number_of_rooms)
+FROM public.bookings b, generate_series(b.check_in_date, b.check_out_date - 1, INTERVAL '1 day') AS night
+WHERE b.status IN ('pending', 'confirmed')
+GROUP BY b.hotel_id, b.room_type_id, night::date;
+
+
+-- LATE PAYMENTS: bookings.late_payment_resolution and payments.late_payment_resolution
+-- Empty for every existing row; nothing paid before this step has been reinstated or refunded as late
+ALTER TABLE public.bookings ADD COLUMN late_payment_resolution text NOT NULL DEFAULT '';
+
+ALTER TABLE public.payments ADD COLUMN late_payment_resolution text NOT NULL DEFAULT '';
+
+
+-- ROLES: users.role
+-- Every existing user becomes a guest; the first admin is promoted by hand as described in schema.sql
+ALTER TABLE public.users ADD COLUMN role text NOT NULL DEFAULT 'guest';
+
+
+-- BOOKING REFERENCES: bookings.reference
+-- Existing bookings get a reference drawn from the same alphabet as new ones (no 0, O, 1 or I), retried on a clash
+ALTER TABLE public.bookings ADD COLUMN reference text;
+
+DO $$
+DECLARE
+  alphabet  CONSTANT text := 'ABCDEFGHJKLMNPQRSTUVWXYZ23456789';
+  booking   record;
+  candidate text;
+BEGIN
+  FOR booking IN SELECT booking_id FROM public.bookings WHERE reference IS NULL LOOP
+    LOOP
+      SELECT string_agg(substr(alphabet, 1 + floor(random() * length(alphabet))::integer, 1), '')
+      INTO candidate
+      FROM generate_series(1, 6);
+      EXIT WHEN NOT EXISTS (SELECT 1 FROM public.bookings WHERE reference = candidate);
+    END LOOP;
+    UPDATE public.bookings SET reference = candidate WHERE booking_id = booking.booking_id;
+  END LOOP;
+END $$;
+
+ALTER TABLE public.bookings ALTER COLUMN reference SET NOT NULL;
+
+ALTER TABLE public.bookings ADD CONSTRAINT bookings_reference_key UNIQUE (reference);
+
+
+-- EMAILS: users.email and users.email_verified_at
+-- Existing accounts have no email yet; the empty ones are left out of the unique index
+ALTER TABLE public.users
+  ADD COLUMN email text NOT NULL DEFAULT '',
+  ADD COLUMN email_verified_at timestamptz;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email ON public.users (email) WHERE email <> '';
+
+
+-- HOTEL STATUS: hotels.status
+-- Every existing hotel stays on sale
+ALTER TABLE public.hotels ADD COLUMN status text NOT NULL DEFAULT 'active';
+
+CREATE INDEX IF NOT EXISTS idx_hotels_status ON public.hotels (status);
+
+
+-- HOTEL SEARCH: hotels.search_vector and the trigram indexes
+-- Requires the pg_trgm extension (see schema.sql). Adding the generated column rewrites the hotels table.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE public.hotels
+  ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(to_tsvector('simple', coalesce(city, '') || ' ' || coalesce(locality, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(street, '') || ' ' || coalesce(landmark, '') || ' ' || coalesce(state, '') || ' ' || coalesce(pincode::text, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'D')
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_hotels_search_vector ON public.hotels USING gin (search_vector);
+CREATE INDEX IF NOT EXISTS idx_hotels_name_trgm ON public.hotels USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_hotels_city_trgm ON public.hotels USING gin (city gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_hotels_locality_trgm ON public.hotels USING gin (locality gin_trgm_ops);
+
+
+-- HOTEL LOCATION: hotels.latitude and hotels.longitude
+-- Existing hotels have no location until they are updated with one, and radius searches leave them out
+ALTER TABLE public.hotels
+  ADD COLUMN latitude double precision CHECK (latitude BETWEEN -90 AND 90),
+  ADD COLUMN longitude double precision CHECK (longitude BETWEEN -180 AND 180);
+
+CREATE INDEX IF NOT EXISTS idx_hotels_location ON public.hotels (latitude, longitude);
+
+
+-- REVIEWS: hotels.review_count and hotels.rating_total
+-- No hotel has been reviewed before the reviews table exists, so every rating starts empty
+ALTER TABLE public.hotels
+  ADD COLUMN review_count integer NOT NULL DEFAULT 0 CHECK (review_count >= 0),
+  ADD COLUMN rating_total integer NOT NULL DEFAULT 0 CHECK (rating_total >= 0);
+
+
+-- MY BOOKINGS: idx_bookings_user_check_in
+-- Replaces the index on user_id alone, which the new one covers
+DROP INDEX IF EXISTS public.idx_bookings_user_id;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_user_check_in ON public.bookings (user_id, check_in_date);
+
+
+-- BOOKING MODIFICATIONS: bookings.total_cost
+-- Until bookings could be modified, a booking's price was the amount of its first payment
+ALTER TABLE public.bookings
+  ADD COLUMN total_cost numeric(10,2) NOT NULL DEFAULT 0 CHECK (total_cost >= 0);
+
+UPDATE public.bookings b
+SET total_cost = p.amount
+FROM (
+  SELECT DISTINCT ON (booking_id) booking_id, amount
+  FROM public.payments
+  ORDER BY booking_id, created_at, id
+) p
+WHERE p.booking_id = b.booking_id;
+
+
+-- WEBHOOK INBOX: webhook_events.gateway_type and raw_body
+-- Events received before these columns leave them empty; their parsed payload is all that was kept
+ALTER TABLE public.webhook_events
+  ADD COLUMN gateway_type text NOT NULL DEFAULT '',
+  ADD COLUMN raw_body text NOT NULL DEFAULT '';
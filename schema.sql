@@ -1,9 +1,14 @@
+-- Fuzzy matching for hotel search (similarity() and the % operator). pg_trgm ships with PostgreSQL's contrib
+-- modules and must be available to the server; creating it needs a role allowed to create extensions.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+
 -- HOTELS
 CREATE TABLE public.hotels (
   id               integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
   name             text NOT NULL,
   description      text,
-  available_rooms  integer NOT NULL CHECK (available_rooms >= 0),
+  available_rooms  integer NOT NULL CHECK (available_rooms >= 0), -- superseded by hotel_inventory
   total_rooms      integer NOT NULL CHECK (total_rooms >= 0),
   street           text,
   landmark         text,
@@ -13,13 +18,159 @@ CREATE TABLE public.hotels (
   state            text,
   image_urls       text[] DEFAULT ARRAY[]::text[],
   cost_per_night   numeric(10,2) NOT NULL CHECK (cost_per_night >= 0),
+  status           text NOT NULL DEFAULT 'active', -- active, inactive (off sale) or deleted (kept for booking history)
+  latitude         double precision CHECK (latitude BETWEEN -90 AND 90),     -- NULL for hotels added without a location
+  longitude        double precision CHECK (longitude BETWEEN -180 AND 180),
+  -- Rating, kept up to date as reviews are added, hidden and shown; the average is rating_total / review_count
+  review_count     integer NOT NULL DEFAULT 0 CHECK (review_count >= 0),
+  rating_total     integer NOT NULL DEFAULT 0 CHECK (rating_total >= 0),
   created_at       timestamptz NOT NULL DEFAULT now(),
-  updated_at       timestamptz NOT NULL DEFAULT now()
+  updated_at       timestamptz NOT NULL DEFAULT now(),
+  -- Full-text search index, weighted name > city and locality > rest of the address > description.
+  -- Names and addresses are not stemmed; the description is, as English.
+  search_vector    tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') ||
+    setweight(to_tsvector('simple', coalesce(city, '') || ' ' || coalesce(locality, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(street, '') || ' ' || coalesce(landmark, '') || ' ' || coalesce(state, '') || ' ' || coalesce(pincode::text, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'D')
+  ) STORED
 );
 
+CREATE INDEX IF NOT EXISTS idx_hotels_search_vector ON public.hotels USING gin (search_vector);
+-- Trigram indexes for queries with typos, which the full-text index misses
+CREATE INDEX IF NOT EXISTS idx_hotels_name_trgm ON public.hotels USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_hotels_city_trgm ON public.hotels USING gin (city gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_hotels_locality_trgm ON public.hotels USING gin (locality gin_trgm_ops);
+
 CREATE INDEX IF NOT EXISTS idx_hotels_city ON public.hotels (city);
 CREATE INDEX IF NOT EXISTS idx_hotels_locality ON public.hotels (locality);
 CREATE INDEX IF NOT EXISTS idx_hotels_pincode ON public.hotels (pincode);
+CREATE INDEX IF NOT EXISTS idx_hotels_status ON public.hotels (status);
+CREATE INDEX IF NOT EXISTS idx_hotels_state ON public.hotels (state);
+-- Bounding-box prefilter of radius searches; the exact distance is computed in the query
+CREATE INDEX IF NOT EXISTS idx_hotels_location ON public.hotels (latitude, longitude);
+
+
+-- LANDMARKS (named places hotels can be searched around, managed by admins)
+CREATE TABLE public.landmarks (
+  id          integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  name        text NOT NULL,
+  city        text NOT NULL,
+  latitude    double precision NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+  longitude   double precision NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+  created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_landmarks_name ON public.landmarks (LOWER(name));
+
+
+-- ROOM TYPES
+CREATE TABLE public.room_types (
+  id              integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  hotel_id        integer NOT NULL,
+  name            text NOT NULL,
+  description     text,
+  total_rooms     integer NOT NULL CHECK (total_rooms >= 0),
+  max_occupancy   integer NOT NULL CHECK (max_occupancy > 0),
+  cost_per_night  numeric(10,2) NOT NULL CHECK (cost_per_night >= 0),
+  image_urls      text[] DEFAULT ARRAY[]::text[],
+  created_at      timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.room_types
+  ADD CONSTRAINT fk_room_types_hotels FOREIGN KEY (hotel_id)
+    REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_room_types_hotel_id ON public.room_types (hotel_id);
+
+-- Hotels created before room types are given a single Standard room type by migrations.sql
+
+
+-- CANCELLATION POLICIES (hotels without a row use the default: free until 24h before check-in)
+CREATE TABLE public.cancellation_policies (
+  hotel_id                 integer PRIMARY KEY,
+  free_cancellation_hours  integer NOT NULL DEFAULT 24 CHECK (free_cancellation_hours >= 0),
+  penalty_percent          numeric(5,2) NOT NULL DEFAULT 100 CHECK (penalty_percent BETWEEN 0 AND 100),
+  non_refundable           boolean NOT NULL DEFAULT false,
+  updated_at               timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.cancellation_policies
+  ADD CONSTRAINT fk_cancellation_policies_hotels FOREIGN KEY (hotel_id)
+    REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+
+-- HOTEL IMAGES (a hotel's gallery; hotels.image_urls holds the same URLs in gallery order)
+-- Uploaded images are kept in the blob store under blob_key, with a JPEG thumbnail under
+-- thumbnail_key; images given by URL when the hotel was added have neither.
+CREATE TABLE public.hotel_images (
+  id             text PRIMARY KEY,
+  hotel_id       integer NOT NULL,
+  position       integer NOT NULL,
+  url            text NOT NULL,
+  thumbnail_url  text NOT NULL,
+  blob_key       text NOT NULL DEFAULT '',
+  thumbnail_key  text NOT NULL DEFAULT '',
+  content_type   text NOT NULL DEFAULT '',
+  size_bytes     bigint NOT NULL DEFAULT 0,
+  width          integer NOT NULL DEFAULT 0,
+  height         integer NOT NULL DEFAULT 0,
+  created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.hotel_images
+  ADD CONSTRAINT fk_hotel_images_hotels FOREIGN KEY (hotel_id)
+    REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_hotel_images_hotel_position ON public.hotel_images (hotel_id, position);
+
+
+-- AMENITIES (admin-managed catalog, offered by hotels and room types)
+CREATE TABLE public.amenities (
+  id          integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  code        text NOT NULL UNIQUE,
+  name        text NOT NULL,
+  category    text NOT NULL,
+  icon        text NOT NULL DEFAULT '',
+  created_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE public.hotel_amenities (
+  hotel_id    integer NOT NULL REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  amenity_id  integer NOT NULL REFERENCES public.amenities (id) ON DELETE CASCADE,
+  PRIMARY KEY (hotel_id, amenity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotel_amenities_amenity ON public.hotel_amenities (amenity_id);
+
+CREATE TABLE public.room_type_amenities (
+  room_type_id  integer NOT NULL REFERENCES public.room_types (id) ON UPDATE CASCADE ON DELETE CASCADE,
+  amenity_id    integer NOT NULL REFERENCES public.amenities (id) ON DELETE CASCADE,
+  PRIMARY KEY (room_type_id, amenity_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_type_amenities_amenity ON public.room_type_amenities (amenity_id);
+
+
+-- HOTEL INVENTORY (one row per room type per night that has been sold)
+-- Nights sold by bookings made before this table existed are filled in by migrations.sql
+CREATE TABLE public.hotel_inventory (
+  hotel_id      integer NOT NULL,
+  room_type_id  integer NOT NULL,
+  stay_date     date NOT NULL,
+  rooms_sold    integer NOT NULL DEFAULT 0 CHECK (rooms_sold >= 0),
+  PRIMARY KEY (room_type_id, stay_date)
+);
+
+ALTER TABLE public.hotel_inventory
+  ADD CONSTRAINT fk_hotel_inventory_hotels FOREIGN KEY (hotel_id)
+    REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+ALTER TABLE public.hotel_inventory
+  ADD CONSTRAINT fk_hotel_inventory_room_types FOREIGN KEY (room_type_id)
+    REFERENCES public.room_types (id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_hotel_inventory_hotel_date ON public.hotel_inventory (hotel_id, stay_date);
 
 
 -- USERS
@@ -27,14 +178,125 @@ CREATE TABLE public.users (
   id         integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
   username   text NOT NULL UNIQUE,
   password   text NOT NULL, -- store password hashes, not plaintext
+  role       text NOT NULL DEFAULT 'guest', -- guest, hotel_staff, admin or partner
+  email      text NOT NULL DEFAULT '', -- lower-cased; empty for accounts created before emails were required
+  email_verified_at timestamptz,
   created_at timestamptz NOT NULL DEFAULT now()
 );
 
+CREATE UNIQUE INDEX IF NOT EXISTS ux_users_email ON public.users (email) WHERE email <> '';
+
+-- Every later admin is appointed through /setUserRole; the first one has to be promoted by hand:
+-- UPDATE public.users SET role = 'admin' WHERE username = '<username>';
+
+
+-- USER TOKENS (single-use emailed tokens for email verification and password reset, stored by hash)
+CREATE TABLE public.user_tokens (
+  id         text PRIMARY KEY,
+  user_id    integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+  purpose    text NOT NULL, -- email_verification or password_reset
+  token_hash text NOT NULL UNIQUE,
+  email      text NOT NULL, -- address the token was sent to
+  expires_at timestamptz NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  used_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_purpose ON public.user_tokens (user_id, purpose);
+
+
+-- LOGIN THROTTLES (recent failed logins per key: "user:<username>" or "ip:<address>")
+CREATE TABLE public.login_throttles (
+  key            text PRIMARY KEY,
+  failed_count   integer NOT NULL,
+  last_failed_at timestamptz NOT NULL,
+  locked_until   timestamptz
+);
+
+
+-- AUTH AUDIT EVENTS (lockouts, unlocks and two-factor changes)
+CREATE TABLE public.auth_audit_events (
+  id            text PRIMARY KEY,
+  type          text NOT NULL, -- login_lockout, account_unlock, totp_enabled, totp_disabled or recovery_code_used
+  username      text NOT NULL DEFAULT '',
+  ip            text NOT NULL DEFAULT '',
+  actor_user_id integer,
+  detail        text NOT NULL DEFAULT '',
+  created_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_audit_events_username ON public.auth_audit_events (username, created_at);
+
+
+-- API KEYS (partner credentials for machine-to-machine calls, stored by hash)
+CREATE TABLE public.api_keys (
+  id           text PRIMARY KEY,
+  user_id      integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE, -- the partner account
+  name         text NOT NULL,
+  prefix       text NOT NULL, -- first characters of the key, to recognise it by
+  key_hash     text NOT NULL UNIQUE,
+  scopes       text[] NOT NULL, -- search, book, read_bookings
+  created_at   timestamptz NOT NULL DEFAULT now(),
+  last_used_at timestamptz,
+  revoked_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON public.api_keys (user_id);
+
+
+-- USER TOTP (authenticator secrets; two-factor login is on once enabled_at is set)
+CREATE TABLE public.user_totp (
+  user_id        integer PRIMARY KEY REFERENCES public.users (id) ON DELETE CASCADE,
+  secret         text NOT NULL,
+  enabled_at     timestamptz,
+  last_used_step bigint NOT NULL DEFAULT 0, -- codes of this time step and earlier are refused
+  created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+
+-- TOTP RECOVERY CODES (single-use, stored by hash)
+CREATE TABLE public.totp_recovery_codes (
+  user_id    integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+  code_hash  text NOT NULL,
+  used_at    timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (user_id, code_hash)
+);
+
+
+-- HOTEL STAFF (the hotels a hotel_staff user manages)
+CREATE TABLE public.hotel_staff (
+  hotel_id   integer NOT NULL REFERENCES public.hotels (id) ON DELETE CASCADE,
+  user_id    integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (hotel_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotel_staff_user_id ON public.hotel_staff (user_id);
+
+
+-- REFRESH TOKENS (single-use, stored by hash; a family is one login session)
+CREATE TABLE public.refresh_tokens (
+  id         text PRIMARY KEY,
+  user_id    integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+  family_id  text NOT NULL,
+  token_hash text NOT NULL UNIQUE,
+  expires_at timestamptz NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  revoked_at timestamptz,
+  mfa        boolean NOT NULL DEFAULT false -- the session was started with a second factor
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON public.refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON public.refresh_tokens (user_id);
+
 
 -- BOOKINGS
 CREATE TABLE public.bookings (
   booking_id      integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  reference       text NOT NULL UNIQUE, -- PNR-style code shown to guests instead of booking_id
   hotel_id        integer NOT NULL,
+  room_type_id    integer NOT NULL,
   user_id         integer NOT NULL,
   number_of_rooms integer NOT NULL CHECK (number_of_rooms > 0),
   number_of_days  integer NOT NULL CHECK (number_of_days > 0),
@@ -42,6 +304,8 @@ CREATE TABLE public.bookings (
   check_in_date   date NOT NULL,
   check_out_date  date NOT NULL,
   status          text NOT NULL,
+  late_payment_resolution text NOT NULL DEFAULT '', -- reinstated or refunded when paid after lapsing
+  total_cost      numeric(10,2) NOT NULL DEFAULT 0 CHECK (total_cost >= 0), -- price of the stay as last booked or modified
   created_at      timestamptz NOT NULL DEFAULT now(),
   updated_at      timestamptz NOT NULL DEFAULT now()
 );
@@ -50,15 +314,67 @@ ALTER TABLE public.bookings
   ADD CONSTRAINT fk_bookings_hotels FOREIGN KEY (hotel_id)
     REFERENCES public.hotels (id) ON UPDATE CASCADE ON DELETE RESTRICT;
 
+ALTER TABLE public.bookings
+  ADD CONSTRAINT fk_bookings_room_types FOREIGN KEY (room_type_id)
+    REFERENCES public.room_types (id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
 ALTER TABLE public.bookings
   ADD CONSTRAINT fk_bookings_users FOREIGN KEY (user_id)
     REFERENCES public.users (id) ON UPDATE CASCADE ON DELETE RESTRICT;
 
-CREATE INDEX IF NOT EXISTS idx_bookings_user_id ON public.bookings (user_id);
+-- A guest's bookings, listed by stay dates
+CREATE INDEX IF NOT EXISTS idx_bookings_user_check_in ON public.bookings (user_id, check_in_date);
 CREATE INDEX IF NOT EXISTS idx_bookings_hotel_id ON public.bookings (hotel_id);
+-- Popularity sort: recent bookings per hotel
+CREATE INDEX IF NOT EXISTS idx_bookings_hotel_booking_time ON public.bookings (hotel_id, booking_time);
 CREATE INDEX IF NOT EXISTS idx_bookings_status ON public.bookings (status);
 
 
+-- BOOKING MODIFICATIONS (changes of dates or room count; pending ones wait for a top-up payment)
+CREATE TABLE public.booking_modifications (
+  id                  integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  booking_id          integer NOT NULL REFERENCES public.bookings (booking_id) ON DELETE CASCADE,
+  old_check_in_date   date NOT NULL,
+  old_check_out_date  date NOT NULL,
+  old_number_of_rooms integer NOT NULL CHECK (old_number_of_rooms > 0),
+  old_total_cost      numeric(10,2) NOT NULL CHECK (old_total_cost >= 0),
+  new_check_in_date   date NOT NULL,
+  new_check_out_date  date NOT NULL,
+  new_number_of_rooms integer NOT NULL CHECK (new_number_of_rooms > 0),
+  new_total_cost      numeric(10,2) NOT NULL CHECK (new_total_cost >= 0),
+  status              text NOT NULL, -- pending, applied, failed
+  payment_id          text UNIQUE, -- the top-up payment, if the change costs more
+  created_at          timestamptz NOT NULL DEFAULT now(),
+  resolved_at         timestamptz
+);
+
+-- At most one modification of a booking waits for its payment at a time
+CREATE UNIQUE INDEX IF NOT EXISTS ux_booking_modifications_pending ON public.booking_modifications (booking_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_booking_modifications_status_created_at ON public.booking_modifications (status, created_at);
+
+
+-- REVIEWS (one per completed booking; scores from 1 to 5)
+CREATE TABLE public.reviews (
+  id             integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+  booking_id     integer NOT NULL UNIQUE REFERENCES public.bookings (booking_id) ON DELETE CASCADE,
+  hotel_id       integer NOT NULL REFERENCES public.hotels (id) ON DELETE CASCADE,
+  user_id        integer NOT NULL REFERENCES public.users (id) ON DELETE CASCADE,
+  overall        smallint NOT NULL CHECK (overall BETWEEN 1 AND 5),
+  cleanliness    smallint NOT NULL CHECK (cleanliness BETWEEN 1 AND 5),
+  service        smallint NOT NULL CHECK (service BETWEEN 1 AND 5),
+  location       smallint NOT NULL CHECK (location BETWEEN 1 AND 5),
+  value          smallint NOT NULL CHECK (value BETWEEN 1 AND 5),
+  comment        text NOT NULL DEFAULT '',
+  reply          text NOT NULL DEFAULT '', -- the hotel's public reply
+  reply_user_id  integer REFERENCES public.users (id) ON DELETE SET NULL,
+  replied_at     timestamptz,
+  hidden_at      timestamptz, -- hidden by an admin; left out of listings and the hotel's rating
+  created_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_created_at ON public.reviews (hotel_id, created_at DESC);
+
+
 -- PAYMENTS
 CREATE TABLE public.payments (
   id                  integer GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
@@ -68,7 +384,8 @@ CREATE TABLE public.payments (
   currency            text NOT NULL DEFAULT 'INR',
   status              text NOT NULL, 
   created_at          timestamptz NOT NULL DEFAULT now(),
-  checkout_session_id text
+  checkout_session_id text,
+  late_payment_resolution text NOT NULL DEFAULT '' -- reinstated or refunded when paid after the booking lapsed
 );
 
 ALTER TABLE public.payments
@@ -79,6 +396,68 @@ CREATE INDEX IF NOT EXISTS idx_payments_booking_id ON public.payments (booking_i
 CREATE INDEX IF NOT EXISTS idx_payments_order_id ON public.payments (order_id);
 
 
+-- REFUNDS (gateway_refund_id stays empty until the gateway accepts the refund)
+CREATE TABLE public.refunds (
+  id                text PRIMARY KEY,
+  payment_id        text NOT NULL,
+  booking_id        integer NOT NULL,
+  gateway_refund_id text NOT NULL DEFAULT '',
+  status            text NOT NULL, -- pending, processing, success, failed
+  reason            text NOT NULL DEFAULT '',
+  amount            numeric(10,2) NOT NULL CHECK (amount > 0),
+  attempts          integer NOT NULL DEFAULT 0,
+  last_attempt_at   timestamptz,
+  failure_reason    text NOT NULL DEFAULT '',
+  created_at        timestamptz NOT NULL DEFAULT now(),
+  updated_at        timestamptz NOT NULL DEFAULT now()
+);
+
+ALTER TABLE public.refunds
+  ADD CONSTRAINT fk_refunds_bookings FOREIGN KEY (booking_id)
+    REFERENCES public.bookings (booking_id) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_id ON public.refunds (payment_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_booking_id ON public.refunds (booking_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_status ON public.refunds (status);
+
+
+-- WEBHOOK EVENTS (inbox of every verified gateway notification, keyed by the gateway's event id)
+CREATE TABLE public.webhook_events (
+  id              text PRIMARY KEY,
+  type            text NOT NULL,
+  payload         jsonb NOT NULL, -- the event in gateway-neutral form, as applied
+  gateway_type    text NOT NULL DEFAULT '', -- the gateway's own event type
+  raw_body        text NOT NULL DEFAULT '', -- the verified request body, exactly as the gateway sent it
+  status          text NOT NULL, -- pending, processing, processed, dead
+  attempts        integer NOT NULL DEFAULT 0,
+  last_error      text NOT NULL DEFAULT '',
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  received_at     timestamptz NOT NULL DEFAULT now(),
+  processed_at    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_due ON public.webhook_events (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received_at ON public.webhook_events (received_at);
+
+
+-- NOTIFICATION OUTBOX (emails written with the change they report, sent afterwards with retries)
+CREATE TABLE public.notification_outbox (
+  id              text PRIMARY KEY,
+  user_id         integer NOT NULL,
+  recipient       text NOT NULL,
+  subject         text NOT NULL,
+  body            text NOT NULL,
+  status          text NOT NULL, -- pending, sent, dead
+  attempts        integer NOT NULL DEFAULT 0,
+  last_error      text NOT NULL DEFAULT '',
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  created_at      timestamptz NOT NULL DEFAULT now(),
+  sent_at         timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON public.notification_outbox (status, next_attempt_at);
+
+
 -- IDEMPOTENCY KEYS
 CREATE TABLE public.idempotency_keys (
   id               uuid PRIMARY KEY,
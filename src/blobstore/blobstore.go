@@ -0,0 +1,112 @@
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Store keeps uploaded files, such as hotel images, under keys like "hotels/12/<id>.jpg" and says
+// where clients can fetch them
+type Store interface {
+	// Put stores a blob, replacing any blob with the same key. contentType is for stores that serve
+	// blobs themselves and must send it along.
+	Put(key string, content io.Reader, contentType string) error
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(key string) error
+	URL(key string) string
+}
+
+const (
+	StoreLocal = "local"
+)
+
+type Config struct {
+	Name     string // StoreLocal, the default
+	LocalDir string // directory the local store writes to
+	BaseURL  string // URL the stored blobs are served from, e.g. http://localhost:8080/blobs
+}
+
+func NewStore(config Config) (Store, error) {
+	if config.BaseURL == "" {
+		return nil, errors.New("blob base URL not set")
+	}
+	switch config.Name {
+	case "", StoreLocal:
+		if config.LocalDir == "" {
+			return nil, errors.New("blob directory not set")
+		}
+		return NewLocalStore(config.LocalDir, config.BaseURL), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", config.Name)
+	}
+}
+
+type localStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore returns a Store that keeps blobs as files under dir. The files are expected to be
+// served at baseURL, e.g. with http.FileServer.
+func NewLocalStore(dir string, baseURL string) Store {
+	return &localStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *localStore) Put(key string, content io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Written under a temporary name first so a failed upload never leaves half a file behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *localStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *localStore) URL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/" + strings.Join(segments, "/")
+}
+
+// path maps a key to a file under the store's directory, refusing keys that would escape it
+func (s *localStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,42 @@
+package blobstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorePutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(Config{Name: StoreLocal, LocalDir: dir, BaseURL: "http://localhost:8080/blobs/"})
+	assert.NoError(t, err)
+
+	err = store.Put("hotels/12/a.jpg", strings.NewReader("image bytes"), "image/jpeg")
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, "hotels", "12", "a.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "image bytes", string(content))
+	assert.Equal(t, "http://localhost:8080/blobs/hotels/12/a.jpg", store.URL("hotels/12/a.jpg"))
+
+	assert.NoError(t, store.Delete("hotels/12/a.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "hotels", "12", "a.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, store.Delete("hotels/12/a.jpg"), "deleting a missing blob is not an error")
+}
+
+func TestLocalStoreRefusesKeysOutsideItsDirectory(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/blobs")
+	for _, key := range []string{"", "../escape.jpg", "/etc/passwd", "hotels/../../escape.jpg"} {
+		assert.Error(t, store.Put(key, strings.NewReader("x"), "image/jpeg"), key)
+	}
+}
+
+func TestNewStoreRequiresSettings(t *testing.T) {
+	_, err := NewStore(Config{Name: StoreLocal, LocalDir: t.TempDir()})
+	assert.Error(t, err)
+	_, err = NewStore(Config{Name: "tape", BaseURL: "http://localhost:8080/blobs"})
+	assert.Error(t, err)
+}
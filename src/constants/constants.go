@@ -1,21 +1,250 @@
 package constants
 
-import "github.com/stripe/stripe-go/v82"
+import (
+	"time"
+
+	"github.com/stripe/stripe-go/v82"
+)
 
 const BookingIdField = "booking_id"
 const MaxBodyBytes = int64(65536)
 const DateFormat = "2006-01-02"
 const IdempotencyKeyHeader = "Idempotency-Key"
+const MaxSearchQueryLength = 200
+
+const (
+	DefaultPageSize  = 10
+	MaxPageSize      = 50
+	PopularityWindow = 90 * 24 * time.Hour // bookings made in this window count towards popularity
+)
+
+// HotelSort orders the hotel listing and search results
+type HotelSort string
+
+const (
+	SORT_RELEVANCE  HotelSort = "relevance" // the default; ties, and everything without a search query, by id for the listing and by price for a search
+	SORT_PRICE_ASC  HotelSort = "price_asc"
+	SORT_PRICE_DESC HotelSort = "price_desc"
+	SORT_POPULARITY HotelSort = "popularity" // most bookings made within PopularityWindow first
+	SORT_DISTANCE   HotelSort = "distance"   // nearest first; the default when searching near a point
+	SORT_RATING     HotelSort = "rating"     // best rated first, then most reviewed; hotels without reviews last
+)
+
+var HotelSorts = []HotelSort{SORT_RELEVANCE, SORT_PRICE_ASC, SORT_PRICE_DESC, SORT_POPULARITY, SORT_DISTANCE, SORT_RATING}
+
+// MaxAmenities caps the amenities of a hotel or room type, and of a search filter
+const MaxAmenities = 50
+
+// BookingPeriod narrows a guest's bookings to the stays that are not over yet or those that are
+type BookingPeriod string
+
+const (
+	PERIOD_UPCOMING BookingPeriod = "upcoming" // checking out after today; soonest check-in first
+	PERIOD_PAST     BookingPeriod = "past"     // checked out today or earlier
+)
+
+// Guest reviews; every score runs from MinReviewScore to MaxReviewScore
+const (
+	MinReviewScore         = 1
+	MaxReviewScore         = 5
+	MaxReviewCommentLength = 4000
+	MaxReviewReplyLength   = 2000
+)
+
+// Radius search around a point or a named landmark
+const (
+	EarthRadiusKm         = 6371.0
+	DefaultSearchRadiusKm = 10.0
+	MaxSearchRadiusKm     = 200.0
+	KmPerDegreeOfLatitude = 111.045
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// UserRole decides which endpoints a user may call; it is carried in the access token
+type UserRole string
+
+const (
+	ROLE_GUEST       UserRole = "guest"
+	ROLE_HOTEL_STAFF UserRole = "hotel_staff" // manages only the hotels they are assigned to
+	ROLE_ADMIN       UserRole = "admin"
+	ROLE_PARTNER     UserRole = "partner" // travel agent integrating through API keys
+)
+
+// HotelStatus decides whether a hotel is listed and bookable. Deleted hotels are kept, so that their
+// bookings stay intact, but are hidden everywhere else.
+type HotelStatus string
+
+const (
+	HOTEL_ACTIVE   HotelStatus = "active"
+	HOTEL_INACTIVE HotelStatus = "inactive" // off sale, e.g. for renovation; can be reactivated
+	HOTEL_DELETED  HotelStatus = "deleted"
+)
+
+// Hotel gallery uploads. Images are decoded before they are stored, so the pixel limit guards against
+// small files that expand into huge images.
+const (
+	MaxImageUploadBytes  = 5 << 20
+	MaxImagePixels       = 40_000_000
+	MaxHotelImages       = 20
+	ThumbnailMaxSize     = 320 // longest side of a thumbnail, in pixels
+	ThumbnailJPEGQuality = 80
+)
+
+// APIKeyScope is a group of endpoints a partner API key may call
+type APIKeyScope string
+
+const (
+	SCOPE_SEARCH        APIKeyScope = "search"
+	SCOPE_BOOK          APIKeyScope = "book"
+	SCOPE_READ_BOOKINGS APIKeyScope = "read_bookings"
+)
+
+var APIKeyScopes = []APIKeyScope{SCOPE_SEARCH, SCOPE_BOOK, SCOPE_READ_BOOKINGS}
+
+const (
+	APIKeyPrefix          = "hsk_"      // marks the string as a hotel system key, e.g. for secret scanners
+	APIKeyDisplayLength   = 12          // leading characters of a key kept in clear to tell keys apart
+	APIKeyUsageResolution = time.Minute // last_used_at is written at most this often per key
+)
+
+// Booking references are drawn from 32 characters, so 6 of them give about a billion codes
+const (
+	BookingReferenceLength   = 6
+	BookingReferenceAttempts = 5 // new references tried when one is already taken
+)
+
+const DefaultRoomTypeName = "Standard"
+const DefaultMaxOccupancy = 2
+const MaxStayNights = 30
+
+// Guests check in at noon on the check-in date; cancellation windows count back from then
+const CheckInHour = 12
+
+// Hotels without their own cancellation policy allow free cancellation until a day before check-in
+const DefaultFreeCancellationHours = 24
+const DefaultCancellationPenaltyPercent = 100
 
 type PaymentStatus string
 
 const (
-	PAYMENT_SUCCESS PaymentStatus = "success"
-	PAYMENT_FAILED  PaymentStatus = "failed"
-	PAYMENT_PENDING PaymentStatus = "pending"
+	PAYMENT_SUCCESS  PaymentStatus = "success"
+	PAYMENT_FAILED   PaymentStatus = "failed"
+	PAYMENT_PENDING  PaymentStatus = "pending"
+	PAYMENT_REFUNDED PaymentStatus = "refunded" // the whole amount has been refunded
 	//	add processing?
 )
 
+type RefundStatus string
+
+const (
+	REFUND_PENDING    RefundStatus = "pending"    // not yet accepted by the gateway, retried by the scheduler
+	REFUND_PROCESSING RefundStatus = "processing" // accepted by the gateway, waiting for the refund webhook
+	REFUND_SUCCESS    RefundStatus = "success"
+	REFUND_FAILED     RefundStatus = "failed"
+)
+
+// LatePaymentResolution records how a payment that succeeded after its booking had expired, failed or
+// been cancelled was settled
+type LatePaymentResolution string
+
+const (
+	LATE_PAYMENT_REINSTATED LatePaymentResolution = "reinstated" // rooms reserved again, booking confirmed
+	LATE_PAYMENT_REFUNDED   LatePaymentResolution = "refunded"   // no rooms left, payment refunded in full
+)
+
+// Pending payments older than this are checked against the gateway in case their webhook was lost
+const PaymentReconcileAfter = 5 * time.Minute
+
+// A modification that costs more holds its extra rooms this long for the guest to pay the difference
+const BookingModificationHold = 15 * time.Minute
+
+// A refund the gateway keeps rejecting is marked failed after this many attempts
+const MaxRefundAttempts = 5
+
+type WebhookEventStatus string
+
+const (
+	WEBHOOK_PENDING    WebhookEventStatus = "pending"    // waiting for its first or a retried attempt
+	WEBHOOK_PROCESSING WebhookEventStatus = "processing" // claimed by a worker until its lease runs out
+	WEBHOOK_PROCESSED  WebhookEventStatus = "processed"
+	WEBHOOK_DEAD       WebhookEventStatus = "dead" // gave up; only replayed by an admin
+)
+
+const (
+	MaxWebhookAttempts     = 8
+	WebhookRetryBaseDelay  = 30 * time.Second // doubled after every failed attempt
+	WebhookRetryMaxDelay   = time.Hour
+	WebhookProcessingLease = 5 * time.Minute
+	WebhookBatchSize       = 50
+)
+
+// Failed logins are counted per username and per client IP. Past the free attempts every failure
+// makes the next attempt wait longer, and reaching the lockout threshold locks the key for a while.
+const (
+	LoginFailureWindow      = time.Hour // failures older than this are forgotten
+	LoginFreeAttempts       = 3
+	LoginLockoutThreshold   = 10
+	LoginIPFreeAttempts     = 20 // an IP may be shared by many guests, so it gets more room
+	LoginIPLockoutThreshold = 50
+	LoginBaseDelay          = time.Second // doubled after every further failure
+	LoginMaxDelay           = 30 * time.Second
+	LoginLockoutDuration    = 15 * time.Minute
+)
+
+type AuthAuditEventType string
+
+const (
+	AUDIT_LOGIN_LOCKOUT  AuthAuditEventType = "login_lockout"
+	AUDIT_ACCOUNT_UNLOCK AuthAuditEventType = "account_unlock"
+	AUDIT_TOTP_ENABLED   AuthAuditEventType = "totp_enabled"
+	AUDIT_TOTP_DISABLED  AuthAuditEventType = "totp_disabled"
+	AUDIT_RECOVERY_USED  AuthAuditEventType = "recovery_code_used"
+)
+
+// Two-factor login uses TOTP (RFC 6238) codes from an authenticator app, with recovery codes for
+// when the app is lost
+const (
+	TOTPIssuer        = "HotelSystem"
+	TOTPDigits        = 6
+	TOTPPeriod        = 30 * time.Second
+	TOTPSkewSteps     = 1 // codes of the previous and next step are accepted too
+	RecoveryCodeCount = 10
+	PreAuthTokenTTL   = 5 * time.Minute // time to enter the TOTP code after the password
+)
+
+// UserTokenPurpose is what an emailed single-use token may be redeemed for
+type UserTokenPurpose string
+
+const (
+	TOKEN_EMAIL_VERIFICATION UserTokenPurpose = "email_verification"
+	TOKEN_PASSWORD_RESET     UserTokenPurpose = "password_reset"
+)
+
+const (
+	EmailVerificationTTL = 48 * time.Hour
+	PasswordResetTTL     = time.Hour
+)
+
+type OutboxStatus string
+
+const (
+	OUTBOX_PENDING OutboxStatus = "pending" // waiting for its first or a retried attempt
+	OUTBOX_SENT    OutboxStatus = "sent"
+	OUTBOX_DEAD    OutboxStatus = "dead" // gave up after MaxOutboxAttempts
+)
+
+const (
+	MaxOutboxAttempts    = 6
+	OutboxRetryBaseDelay = time.Minute // doubled after every failed attempt
+	OutboxRetryMaxDelay  = time.Hour
+	OutboxSendingLease   = 5 * time.Minute
+	OutboxBatchSize      = 50
+)
+
 const (
 	StripePaymentSucceeded = "payment_intent.succeeded"
 	StripePaymentFailed    = "payment_intent.payment_failed"
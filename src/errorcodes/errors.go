@@ -20,4 +20,6 @@ const (
 	ErrPaymentCreateFailed  = "ERR_PAYMENT_CREATE_FAILED"
 	ErrPaymentNotFoundByOID = "ERR_PAYMENT_NOT_FOUND_BY_ORDER_ID"
 	ErrPaymentStatusUpdate  = "ERR_PAYMENT_UPDATE_STATUS_FAILED"
+
+	ErrRefundUpdateFailed = "ERR_REFUND_UPDATE_FAILED"
 )
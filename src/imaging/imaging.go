@@ -0,0 +1,100 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+	"net/http"
+)
+
+// ContentTypes are the image formats accepted for upload, with the file extension they are stored under
+var ContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+var ErrUnsupportedImage = errors.New("unsupported image type; upload a JPEG, PNG or GIF")
+
+// Decode checks uploaded data and decodes it. The content type is sniffed from the data rather than
+// taken from the client, and images with more than maxPixels pixels are refused before decoding.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := ContentTypes[contentType]; !ok {
+		return nil, "", ErrUnsupportedImage
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, "", fmt.Errorf("image is %dx%d pixels, more than the %d allowed", config.Width, config.Height, maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image: %w", err)
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail scales an image down so that its longest side is at most maxSize, keeping the aspect
+// ratio. Each thumbnail pixel is the average of the source pixels it covers, read straight from the
+// source image so that no full-size copy is made. Images that are small enough already are only copied.
+func Thumbnail(img image.Image, maxSize int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > maxSize || srcH > maxSize {
+		if srcW >= srcH {
+			dstW, dstH = maxSize, max(1, srcH*maxSize/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*maxSize/srcH), maxSize
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	if dstW == srcW && dstH == srcH {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+		return dst
+	}
+
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			// Sums of 16-bit premultiplied channels, as returned by color.Color.RGBA
+			var sum [4]uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					r, g, b, a := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					sum[0] += uint64(r)
+					sum[1] += uint64(g)
+					sum[2] += uint64(b)
+					sum[3] += uint64(a)
+				}
+			}
+			n := uint64((y1 - y0) * (x1 - x0))
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(sum[0] / n >> 8),
+				G: uint8(sum[1] / n >> 8),
+				B: uint8(sum[2] / n >> 8),
+				A: uint8(sum[3] / n >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG encodes an image as JPEG, the format thumbnails are stored in
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
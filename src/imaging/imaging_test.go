@@ -0,0 +1,80 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img, contentType, err := Decode(encodePNG(t, 40, 20, color.White), 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, 40, img.Bounds().Dx())
+
+	_, _, err = Decode([]byte("<html>not an image</html>"), 1000)
+	assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+	_, _, err = Decode(encodePNG(t, 40, 30, color.White), 1000)
+	assert.Error(t, err, "1200 pixels is over the limit")
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{"landscape", 640, 480, 320, 240},
+		{"portrait", 300, 900, 106, 320},
+		{"already small", 100, 50, 100, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, _, err := Decode(encodePNG(t, tt.width, tt.height, color.RGBA{R: 200, G: 100, B: 50, A: 255}), 1_000_000)
+			assert.NoError(t, err)
+			thumb := Thumbnail(img, 320)
+			assert.Equal(t, tt.wantWidth, thumb.Bounds().Dx())
+			assert.Equal(t, tt.wantHeight, thumb.Bounds().Dy())
+			assert.Equal(t, color.RGBA{R: 200, G: 100, B: 50, A: 255}, thumb.RGBAAt(thumb.Bounds().Dx()/2, thumb.Bounds().Dy()/2))
+		})
+	}
+}
+
+func TestThumbnailAveragesSourcePixels(t *testing.T) {
+	// Left half black, right half white, read through a sub-image that does not start at the origin
+	img := image.NewRGBA(image.Rect(0, 0, 12, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 12; x++ {
+			img.Set(x, y, color.Black)
+			if x >= 6 {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	sub := img.SubImage(image.Rect(2, 1, 10, 5))
+
+	thumb := Thumbnail(sub, 2)
+	assert.Equal(t, image.Rect(0, 0, 2, 1), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 0, G: 0, B: 0, A: 255}, thumb.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thumb.RGBAAt(1, 0))
+
+	thumb = Thumbnail(sub, 1)
+	assert.Equal(t, color.RGBA{R: 127, G: 127, B: 127, A: 255}, thumb.RGBAAt(0, 0))
+}
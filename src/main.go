@@ -3,8 +3,12 @@ package main
 import (
 	"database/sql"
 	"fmt"
+	"hotel-system/src/blobstore"
+	"hotel-system/src/notifications"
+	"hotel-system/src/payments"
 	"hotel-system/src/routes"
 	"hotel-system/src/services"
+	"hotel-system/src/utils"
 	"log"
 	"net/http"
 	"os"
@@ -12,7 +16,6 @@ import (
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
-	"github.com/stripe/stripe-go/v82"
 )
 
 func main() {
@@ -29,18 +32,55 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable not set")
 	}
 
-	stripeKey := os.Getenv("STRIPE_API_KEY")
-	if stripeKey == "" {
-		log.Fatal("STRIPE_API_KEY environment variable not set")
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET environment variable not set")
+	}
+	utils.SetJWTSecret(jwtSecret)
+
+	paymentGateway, err := payments.NewGateway(payments.GatewayConfig{
+		Name:                os.Getenv("PAYMENT_GATEWAY"),
+		StripeAPIKey:        os.Getenv("STRIPE_API_KEY"),
+		StripeWebhookSecret: os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		FakeCheckoutURL:     "http://localhost:8080/test/fakeCheckout",
+	})
+	if err != nil {
+		log.Fatal("Error configuring payment gateway: ", err)
+	}
+
+	emailSender, err := notifications.NewSender(notifications.SenderConfig{
+		Name:         os.Getenv("EMAIL_SENDER"),
+		From:         getEnvOrDefault("EMAIL_FROM", "noreply@hotel-system.local"),
+		FileDir:      getEnvOrDefault("EMAIL_FILE_DIR", "mail"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+	})
+	if err != nil {
+		log.Fatal("Error configuring email sender: ", err)
+	}
+
+	appBaseURL := getEnvOrDefault("APP_BASE_URL", "http://localhost:8080")
+	blobConfig := blobstore.Config{
+		Name:     os.Getenv("BLOB_STORE"),
+		LocalDir: getEnvOrDefault("BLOB_DIR", "uploads"),
+		BaseURL:  getEnvOrDefault("BLOB_BASE_URL", appBaseURL+"/blobs"),
+	}
+	blobStore, err := blobstore.NewStore(blobConfig)
+	if err != nil {
+		log.Fatal("Error configuring blob store: ", err)
 	}
-	stripe.Key = stripeKey
 
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		log.Fatal(err)
 	}
-	service := services.NewService(db)
+	service := services.NewService(db, paymentGateway, emailSender, blobStore, appBaseURL)
 	routes.RegisterRoutes(mux, service)
+	if blobConfig.Name == "" || blobConfig.Name == blobstore.StoreLocal {
+		routes.ServeLocalBlobs(mux, "/blobs/", blobConfig.LocalDir)
+	}
 
 	server := http.Server{
 		Addr:              ":8080",
@@ -57,3 +97,10 @@ func main() {
 		fmt.Printf("Error starting server: %s\n", err)
 	}
 }
+
+func getEnvOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
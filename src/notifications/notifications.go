@@ -0,0 +1,20 @@
+package notifications
+
+import "log"
+
+// Notifier sends a message to a user of the platform
+type Notifier interface {
+	Notify(userId int, subject string, body string) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier returns a Notifier that only writes messages to the log
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(userId int, subject string, body string) error {
+	log.Printf("Notification for user %d: %s: %s", userId, subject, body)
+	return nil
+}
@@ -0,0 +1,103 @@
+package notifications
+
+import (
+	"database/sql"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"log"
+	"time"
+)
+
+// Outbox queues emails in the notification outbox and sends them through a Sender, retrying failed
+// attempts with exponential backoff. Emails queued inside a transaction are only sent if it commits.
+type Outbox struct {
+	storage store.StorageService
+	sender  Sender
+}
+
+func NewOutbox(storage store.StorageService, sender Sender) *Outbox {
+	return &Outbox{
+		storage: storage,
+		sender:  sender,
+	}
+}
+
+// EnqueueTx queues an email as part of the given transaction
+func (o *Outbox) EnqueueTx(tx *sql.Tx, userId int, to string, subject string, body string) error {
+	return o.storage.CreateOutboxMessageTx(tx, store.NewOutboxMessage(utils.NewUuid(), userId, to, subject, body))
+}
+
+// Notify emails a user at the address on their account. Users without one only get the message logged.
+func (o *Outbox) Notify(userId int, subject string, body string) error {
+	user, err := o.storage.GetUserById(userId)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		log.Printf("Notification for user %d without email: %s: %s", userId, subject, body)
+		return nil
+	}
+	return o.storage.CreateOutboxMessage(store.NewOutboxMessage(utils.NewUuid(), userId, user.Email, subject, body))
+}
+
+// DispatchDue sends the queued emails that are due for an attempt. It is run by the outbox scheduler.
+func (o *Outbox) DispatchDue() error {
+	messages, err := o.storage.GetDueOutboxMessages(time.Now(), constants.OutboxBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		o.dispatch(message)
+	}
+	return nil
+}
+
+// dispatch claims a message and sends it. A message that keeps failing is marked dead after
+// MaxOutboxAttempts.
+func (o *Outbox) dispatch(message *store.OutboxMessage) {
+	now := time.Now()
+	claimed, err := o.storage.ClaimOutboxMessage(message.ID, now, now.Add(constants.OutboxSendingLease))
+	if err != nil {
+		log.Printf("Error claiming outbox message %s: %v", message.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	err = o.sender.Send(&Email{
+		ID:      message.ID,
+		To:      message.Recipient,
+		Subject: message.Subject,
+		Body:    message.Body,
+	})
+
+	message.Attempts++
+	switch {
+	case err == nil:
+		message.Status = constants.OUTBOX_SENT
+		message.LastError = ""
+		message.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	case message.Attempts >= constants.MaxOutboxAttempts:
+		log.Printf("Outbox message %s dead after %d attempts: %v", message.ID, message.Attempts, err)
+		message.Status = constants.OUTBOX_DEAD
+		message.LastError = err.Error()
+	default:
+		log.Printf("Outbox message %s attempt %d failed: %v", message.ID, message.Attempts, err)
+		message.LastError = err.Error()
+		message.NextAttemptAt = time.Now().Add(retryDelay(message.Attempts))
+	}
+	if err = o.storage.UpdateOutboxMessage(message); err != nil {
+		log.Printf("Error updating outbox message %s: %v", message.ID, err)
+	}
+}
+
+// retryDelay is the wait before the next attempt after the given number of failed attempts
+func retryDelay(attempts int) time.Duration {
+	delay := constants.OutboxRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.OutboxRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, constants.OutboxRetryMaxDelay)
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Email is a plain-text email to a single recipient
+type Email struct {
+	ID      string // outbox message id, used to name files and as Message-ID
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails. Implementations must be safe to call again for an email whose earlier
+// attempt may have gone through, since the outbox retries on any error.
+type Sender interface {
+	Send(email *Email) error
+}
+
+const (
+	SenderFile = "file"
+	SenderSMTP = "smtp"
+)
+
+type SenderConfig struct {
+	Name         string // SenderFile (the default) or SenderSMTP
+	From         string
+	FileDir      string // directory the file sender writes emails to
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string // leave empty for SMTP sinks that take mail without authentication
+	SMTPPassword string
+}
+
+func NewSender(config SenderConfig) (Sender, error) {
+	if config.From == "" {
+		return nil, errors.New("email from address not set")
+	}
+	switch config.Name {
+	case "", SenderFile:
+		if config.FileDir == "" {
+			return nil, errors.New("email file directory not set")
+		}
+		return NewFileSender(config.FileDir, config.From), nil
+	case SenderSMTP:
+		if config.SMTPHost == "" || config.SMTPPort == "" {
+			return nil, errors.New("smtp host and port not set")
+		}
+		return NewSMTPSender(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.From), nil
+	default:
+		return nil, fmt.Errorf("unknown email sender %q", config.Name)
+	}
+}
+
+type fileSender struct {
+	dir  string
+	from string
+}
+
+// NewFileSender returns a Sender that writes every email to an .eml file in dir instead of sending
+// it, for exercising email flows in development
+func NewFileSender(dir string, from string) Sender {
+	return &fileSender{dir: dir, from: from}
+}
+
+func (s *fileSender) Send(email *Email) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), email.ID)
+	return os.WriteFile(filepath.Join(s.dir, name), formatEmail(s.from, email), 0o644)
+}
+
+type smtpSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPSender returns a Sender that hands emails to an SMTP server, such as a local sink like
+// MailHog in development
+func NewSMTPSender(host string, port string, username string, password string, from string) Sender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpSender{addr: net.JoinHostPort(host, port), auth: auth, from: from}
+}
+
+func (s *smtpSender) Send(email *Email) error {
+	return smtp.SendMail(s.addr, s.auth, s.from, []string{email.To}, formatEmail(s.from, email))
+}
+
+// formatEmail renders an email as an RFC 5322 message
+func formatEmail(from string, email *Email) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + email.To + "\r\n")
+	b.WriteString("Subject: " + email.Subject + "\r\n")
+	b.WriteString("Message-ID: <" + email.ID + "@hotel-system>\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,42 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSenderWritesEmail(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	sender, err := NewSender(SenderConfig{Name: SenderFile, From: "noreply@example.com", FileDir: dir})
+	assert.NoError(t, err)
+
+	err = sender.Send(&Email{ID: "m1", To: "guest@example.com", Subject: "Verify your email", Body: "line one\nline two"})
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*-m1.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "To: guest@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Verify your email\r\n")
+	assert.True(t, strings.HasSuffix(string(content), "\r\n\r\nline one\r\nline two"))
+}
+
+func TestNewSenderRequiresSettings(t *testing.T) {
+	_, err := NewSender(SenderConfig{Name: SenderSMTP, From: "noreply@example.com"})
+	assert.Error(t, err)
+	_, err = NewSender(SenderConfig{Name: "carrier-pigeon", From: "noreply@example.com"})
+	assert.Error(t, err)
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Minute, retryDelay(1))
+	assert.Equal(t, 4*time.Minute, retryDelay(3))
+	assert.Equal(t, time.Hour, retryDelay(20))
+}
@@ -0,0 +1,152 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const FakeSignatureHeader = "Fake-Signature"
+
+// FakeGateway is an in-process gateway for running the booking flow offline and in tests. Checkouts
+// stay open until Pay settles them, and refunds succeed immediately.
+type FakeGateway struct {
+	mu            sync.Mutex
+	checkoutURL   string
+	webhookSecret []byte
+	sessions      map[string]*fakeSession
+}
+
+type fakeSession struct {
+	checkout *CheckoutSession
+	amount   float32
+	refunded float32
+}
+
+func NewFakeGateway(checkoutURL string) *FakeGateway {
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return &FakeGateway{
+		checkoutURL:   checkoutURL,
+		webhookSecret: secret,
+		sessions:      make(map[string]*fakeSession),
+	}
+}
+
+func (g *FakeGateway) CreateCheckout(req *CheckoutRequest) (*CheckoutSession, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := "fake_cs_" + uuid.New().String()
+	checkout := &CheckoutSession{
+		ID:       id,
+		URL:      fmt.Sprintf("%s?session_id=%s", g.checkoutURL, id),
+		Status:   CHECKOUT_OPEN,
+		Metadata: req.Metadata,
+	}
+	g.sessions[id] = &fakeSession{checkout: checkout, amount: req.Amount}
+	copied := *checkout
+	return &copied, nil
+}
+
+func (g *FakeGateway) GetCheckout(checkoutSessionId string) (*CheckoutSession, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[checkoutSessionId]
+	if !ok {
+		return nil, fmt.Errorf("checkout session %s not found", checkoutSessionId)
+	}
+	copied := *session.checkout
+	return &copied, nil
+}
+
+func (g *FakeGateway) ExpireCheckout(checkoutSessionId string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[checkoutSessionId]
+	if !ok {
+		return fmt.Errorf("checkout session %s not found", checkoutSessionId)
+	}
+	if session.checkout.Status != CHECKOUT_OPEN {
+		return fmt.Errorf("checkout session %s is already %s", checkoutSessionId, session.checkout.Status)
+	}
+	session.checkout.Status = CHECKOUT_EXPIRED
+	return nil
+}
+
+func (g *FakeGateway) Refund(req *RefundRequest) (*RefundResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[req.CheckoutSessionID]
+	if !ok {
+		return nil, fmt.Errorf("checkout session %s not found", req.CheckoutSessionID)
+	}
+	if session.checkout.Status != CHECKOUT_PAID {
+		return nil, errors.New("checkout session has no payment to refund")
+	}
+	if session.refunded+req.Amount > session.amount {
+		return nil, errors.New("refund exceeds the amount paid")
+	}
+	session.refunded += req.Amount
+	return &RefundResult{
+		RefundID: "fake_re_" + req.RefundID,
+		Status:   "succeeded",
+	}, nil
+}
+
+// VerifyWebhook accepts events produced by Pay and signed with SignWebhook
+func (g *FakeGateway) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	expected := g.SignWebhook(payload)
+	if !hmac.Equal([]byte(header.Get(FakeSignatureHeader)), []byte(expected)) {
+		return nil, errors.New("invalid webhook signature")
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("parse webhook event: %w", err)
+	}
+	return &event, nil
+}
+
+// SignWebhook returns the FakeSignatureHeader value VerifyWebhook expects for payload
+func (g *FakeGateway) SignWebhook(payload []byte) string {
+	mac := hmac.New(sha256.New, g.webhookSecret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Pay settles an open checkout as paid or declined, the way a guest finishing the hosted checkout
+// would, and returns the webhook event the gateway sends for it.
+func (g *FakeGateway) Pay(checkoutSessionId string, succeed bool) (*WebhookEvent, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	session, ok := g.sessions[checkoutSessionId]
+	if !ok {
+		return nil, fmt.Errorf("checkout session %s not found", checkoutSessionId)
+	}
+	if session.checkout.Status != CHECKOUT_OPEN {
+		return nil, fmt.Errorf("checkout session %s is already %s", checkoutSessionId, session.checkout.Status)
+	}
+
+	event := &WebhookEvent{ID: "fake_evt_" + uuid.New().String(), Type: EventCheckoutCompleted}
+	session.checkout.Status = CHECKOUT_PAID
+	if !succeed {
+		event.Type = EventCheckoutFailed
+		session.checkout.Status = CHECKOUT_FAILED
+	}
+	event.GatewayType = string(event.Type)
+	copied := *session.checkout
+	event.Checkout = &copied
+	return event, nil
+}
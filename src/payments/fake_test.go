@@ -0,0 +1,68 @@
+package payments
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeGatewayCheckoutAndRefund(t *testing.T) {
+	gateway := NewFakeGateway("http://localhost/pay")
+
+	checkout, err := gateway.CreateCheckout(&CheckoutRequest{Amount: 100, Metadata: map[string]string{"booking_id": "7"}})
+	assert.NoError(t, err)
+	assert.Equal(t, CHECKOUT_OPEN, checkout.Status)
+
+	_, err = gateway.Refund(&RefundRequest{CheckoutSessionID: checkout.ID, Amount: 10, RefundID: "r0"})
+	assert.Error(t, err, "an unpaid checkout cannot be refunded")
+
+	event, err := gateway.Pay(checkout.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, EventCheckoutCompleted, event.Type)
+	assert.Equal(t, "7", event.Checkout.Metadata["booking_id"])
+
+	_, err = gateway.Pay(checkout.ID, false)
+	assert.Error(t, err, "a settled checkout cannot be paid again")
+
+	result, err := gateway.Refund(&RefundRequest{CheckoutSessionID: checkout.ID, Amount: 60, RefundID: "r1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "succeeded", result.Status)
+
+	_, err = gateway.Refund(&RefundRequest{CheckoutSessionID: checkout.ID, Amount: 50, RefundID: "r2"})
+	assert.Error(t, err, "refunds cannot exceed the amount paid")
+}
+
+func TestFakeGatewayVerifyWebhook(t *testing.T) {
+	gateway := NewFakeGateway("http://localhost/pay")
+	checkout, _ := gateway.CreateCheckout(&CheckoutRequest{Amount: 100})
+	event, _ := gateway.Pay(checkout.ID, false)
+	payload, _ := json.Marshal(event)
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, gateway.SignWebhook(payload))
+	verified, err := gateway.VerifyWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, EventCheckoutFailed, verified.Type)
+	assert.Equal(t, CHECKOUT_FAILED, verified.Checkout.Status)
+
+	header.Set(FakeSignatureHeader, "forged")
+	_, err = gateway.VerifyWebhook(payload, header)
+	assert.Error(t, err)
+}
+
+func TestFakeGatewayExpireCheckout(t *testing.T) {
+	gateway := NewFakeGateway("http://localhost/pay")
+	checkout, _ := gateway.CreateCheckout(&CheckoutRequest{Amount: 100})
+
+	assert.NoError(t, gateway.ExpireCheckout(checkout.ID))
+	expired, _ := gateway.GetCheckout(checkout.ID)
+	assert.Equal(t, CHECKOUT_EXPIRED, expired.Status)
+	_, err := gateway.Pay(checkout.ID, true)
+	assert.Error(t, err, "an expired checkout cannot be paid")
+
+	paid, _ := gateway.CreateCheckout(&CheckoutRequest{Amount: 100})
+	_, _ = gateway.Pay(paid.ID, true)
+	assert.Error(t, gateway.ExpireCheckout(paid.ID), "a paid checkout cannot be expired")
+}
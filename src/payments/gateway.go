@@ -0,0 +1,35 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	GatewayStripe = "stripe"
+	GatewayFake   = "fake"
+)
+
+type GatewayConfig struct {
+	Name                string // GatewayStripe (the default) or GatewayFake
+	StripeAPIKey        string
+	StripeWebhookSecret string
+	FakeCheckoutURL     string // page the fake gateway sends guests to for paying
+}
+
+func NewGateway(config GatewayConfig) (Gateway, error) {
+	switch config.Name {
+	case "", GatewayStripe:
+		if config.StripeAPIKey == "" {
+			return nil, errors.New("stripe api key not set")
+		}
+		if config.StripeWebhookSecret == "" {
+			return nil, errors.New("stripe webhook secret not set")
+		}
+		return NewStripeGateway(config.StripeAPIKey, config.StripeWebhookSecret), nil
+	case GatewayFake:
+		return NewFakeGateway(config.FakeCheckoutURL), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", config.Name)
+	}
+}
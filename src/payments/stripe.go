@@ -0,0 +1,152 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/webhook"
+)
+
+type stripeGateway struct {
+	client        *stripe.Client
+	webhookSecret string
+}
+
+func NewStripeGateway(apiKey string, webhookSecret string) Gateway {
+	return &stripeGateway{
+		client:        stripe.NewClient(apiKey),
+		webhookSecret: webhookSecret,
+	}
+}
+
+func (g *stripeGateway) CreateCheckout(req *CheckoutRequest) (*CheckoutSession, error) {
+	params := &stripe.CheckoutSessionCreateParams{
+		LineItems: []*stripe.CheckoutSessionCreateLineItemParams{
+			{
+				PriceData: &stripe.CheckoutSessionCreateLineItemPriceDataParams{
+					Currency:          stripe.String(req.Currency),
+					UnitAmountDecimal: stripe.Float64(float64(req.Amount * 100)),
+					ProductData: &stripe.CheckoutSessionCreateLineItemPriceDataProductDataParams{
+						Name: stripe.String(req.Description),
+					},
+				},
+				Quantity: stripe.Int64(1),
+			},
+		},
+		Metadata:      req.Metadata,
+		Mode:          stripe.String(string(stripe.CheckoutSessionModePayment)),
+		CustomerEmail: stripe.String(req.CustomerEmail),
+		SuccessURL:    stripe.String(req.SuccessURL),
+		CancelURL:     stripe.String(req.CancelURL),
+	}
+	checkoutSession, err := g.client.V1CheckoutSessions.Create(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	return stripeCheckoutSession(checkoutSession), nil
+}
+
+func (g *stripeGateway) GetCheckout(checkoutSessionId string) (*CheckoutSession, error) {
+	checkoutSession, err := g.client.V1CheckoutSessions.Retrieve(context.Background(), checkoutSessionId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return stripeCheckoutSession(checkoutSession), nil
+}
+
+func (g *stripeGateway) ExpireCheckout(checkoutSessionId string) error {
+	_, err := g.client.V1CheckoutSessions.Expire(context.Background(), checkoutSessionId, nil)
+	return err
+}
+
+// Refund refunds the payment intent behind the checkout. The refund id is sent as metadata so refund
+// webhooks can be matched back, and as idempotency key so that retrying an attempt whose response
+// was lost never refunds twice.
+func (g *stripeGateway) Refund(req *RefundRequest) (*RefundResult, error) {
+	checkoutSession, err := g.client.V1CheckoutSessions.Retrieve(context.Background(), req.CheckoutSessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+	if checkoutSession.PaymentIntent == nil {
+		return nil, errors.New("checkout session has no payment to refund")
+	}
+
+	params := &stripe.RefundCreateParams{
+		PaymentIntent: stripe.String(checkoutSession.PaymentIntent.ID),
+		Amount:        stripe.Int64(int64(math.Round(float64(req.Amount) * 100))),
+		Metadata: map[string]string{
+			RefundIdMetadataKey: req.RefundID,
+		},
+	}
+	params.SetIdempotencyKey(req.RefundID)
+
+	r, err := g.client.V1Refunds.Create(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	return &RefundResult{
+		RefundID: r.ID,
+		Status:   string(r.Status),
+	}, nil
+}
+
+func (g *stripeGateway) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	event, err := webhook.ConstructEvent(payload, header.Get("Stripe-Signature"), g.webhookSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookEvent := &WebhookEvent{ID: event.ID, Type: EventOther, GatewayType: string(event.Type)}
+	switch event.Type {
+	case stripe.EventTypeCheckoutSessionCompleted, stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+		stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+		var checkoutSession stripe.CheckoutSession
+		if err = json.Unmarshal(event.Data.Raw, &checkoutSession); err != nil {
+			return nil, fmt.Errorf("parse checkout session: %w", err)
+		}
+		webhookEvent.Checkout = stripeCheckoutSession(&checkoutSession)
+		// A checkout paid with a delayed method, such as a bank debit, completes unpaid and is left as
+		// EventOther; its booking waits for async_payment_succeeded or async_payment_failed
+		switch {
+		case event.Type == stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
+			webhookEvent.Type = EventCheckoutFailed
+			webhookEvent.Checkout.Status = CHECKOUT_FAILED
+		case webhookEvent.Checkout.Status == CHECKOUT_PAID:
+			webhookEvent.Type = EventCheckoutCompleted
+		}
+	case stripe.EventTypeRefundCreated, stripe.EventTypeRefundUpdated, stripe.EventTypeRefundFailed:
+		var r stripe.Refund
+		if err = json.Unmarshal(event.Data.Raw, &r); err != nil {
+			return nil, fmt.Errorf("parse refund: %w", err)
+		}
+		webhookEvent.Type = EventRefundUpdated
+		webhookEvent.Refund = &RefundEvent{
+			GatewayRefundID: r.ID,
+			RefundID:        r.Metadata[RefundIdMetadataKey],
+			Status:          string(r.Status),
+			FailureReason:   string(r.FailureReason),
+		}
+	}
+	return webhookEvent, nil
+}
+
+func stripeCheckoutSession(checkoutSession *stripe.CheckoutSession) *CheckoutSession {
+	status := CHECKOUT_OPEN
+	switch {
+	case checkoutSession.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid:
+		status = CHECKOUT_PAID
+	case checkoutSession.Status == stripe.CheckoutSessionStatusExpired:
+		status = CHECKOUT_EXPIRED
+	}
+	return &CheckoutSession{
+		ID:       checkoutSession.ID,
+		URL:      checkoutSession.URL,
+		Status:   status,
+		Metadata: checkoutSession.Metadata,
+	}
+}
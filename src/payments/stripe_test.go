@@ -0,0 +1,83 @@
+package payments
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/webhook"
+)
+
+func TestStripeGatewayVerifyCheckoutWebhook(t *testing.T) {
+	const secret = "whsec_test"
+	gateway := NewStripeGateway("sk_test", secret)
+
+	tests := []struct {
+		name           string
+		eventType      stripe.EventType
+		paymentStatus  stripe.CheckoutSessionPaymentStatus
+		expectedType   EventType
+		expectedStatus CheckoutStatus
+	}{
+		{
+			name:           "completed and paid",
+			eventType:      stripe.EventTypeCheckoutSessionCompleted,
+			paymentStatus:  stripe.CheckoutSessionPaymentStatusPaid,
+			expectedType:   EventCheckoutCompleted,
+			expectedStatus: CHECKOUT_PAID,
+		},
+		{
+			name:           "completed with a delayed payment",
+			eventType:      stripe.EventTypeCheckoutSessionCompleted,
+			paymentStatus:  stripe.CheckoutSessionPaymentStatusUnpaid,
+			expectedType:   EventOther,
+			expectedStatus: CHECKOUT_OPEN,
+		},
+		{
+			name:           "delayed payment succeeded",
+			eventType:      stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded,
+			paymentStatus:  stripe.CheckoutSessionPaymentStatusPaid,
+			expectedType:   EventCheckoutCompleted,
+			expectedStatus: CHECKOUT_PAID,
+		},
+		{
+			name:           "delayed payment failed",
+			eventType:      stripe.EventTypeCheckoutSessionAsyncPaymentFailed,
+			paymentStatus:  stripe.CheckoutSessionPaymentStatusUnpaid,
+			expectedType:   EventCheckoutFailed,
+			expectedStatus: CHECKOUT_FAILED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, _ := json.Marshal(map[string]any{
+				"id":          "evt_1",
+				"object":      "event",
+				"type":        tt.eventType,
+				"api_version": stripe.APIVersion,
+				"data": map[string]any{
+					"object": map[string]any{
+						"id":             "cs_1",
+						"object":         "checkout.session",
+						"status":         "complete",
+						"payment_status": tt.paymentStatus,
+						"metadata":       map[string]string{"booking_id": "7"},
+					},
+				},
+			})
+			signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: secret})
+			header := http.Header{}
+			header.Set("Stripe-Signature", signed.Header)
+
+			event, err := gateway.VerifyWebhook(payload, header)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedType, event.Type)
+			assert.Equal(t, string(tt.eventType), event.GatewayType)
+			assert.Equal(t, tt.expectedStatus, event.Checkout.Status)
+			assert.Equal(t, "7", event.Checkout.Metadata["booking_id"])
+		})
+	}
+}
@@ -1,30 +1,85 @@
 package payments
 
-type Client interface {
-	CreatePaymentOrder(req *CreatePaymentOrderRequest) (*CreatePaymentOrderResponse, error)
+import "net/http"
+
+// Gateway is the payment provider bookings are paid and refunded through. Implementations translate
+// between the provider's API and the gateway-neutral types below.
+type Gateway interface {
+	// CreateCheckout starts a hosted checkout the guest is redirected to for paying
+	CreateCheckout(req *CheckoutRequest) (*CheckoutSession, error)
+	// GetCheckout fetches the current state of a checkout
+	GetCheckout(checkoutSessionId string) (*CheckoutSession, error)
+	// ExpireCheckout closes an open checkout so it can no longer be paid. It fails if the checkout
+	// has already been settled.
+	ExpireCheckout(checkoutSessionId string) error
+	// Refund returns part or all of the amount collected by a checkout
+	Refund(req *RefundRequest) (*RefundResult, error)
+	// VerifyWebhook checks that a webhook request was sent by the gateway and parses its event
+	VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
+
+type CheckoutRequest struct {
+	Amount        float32
+	Currency      string
+	Description   string
+	CustomerEmail string
+	SuccessURL    string
+	CancelURL     string
+	Metadata      map[string]string // returned unchanged in the checkout and its webhook events
 }
 
-type CreatePaymentOrderRequest struct {
-	Amount   float32           `json:"amount"`
-	Currency string            `json:"currency"`
-	Receipt  string            `json:"receipt"`
-	Metadata map[string]string `json:"metadata,omitempty"` // optional metadata for additional info
+type CheckoutStatus string
+
+const (
+	CHECKOUT_OPEN    CheckoutStatus = "open"    // waiting for the guest to pay
+	CHECKOUT_PAID    CheckoutStatus = "paid"    // payment collected
+	CHECKOUT_FAILED  CheckoutStatus = "failed"  // payment attempted and declined
+	CHECKOUT_EXPIRED CheckoutStatus = "expired" // closed without a payment
+)
+
+type CheckoutSession struct {
+	ID       string            `json:"id"`
+	URL      string            `json:"url"`
+	Status   CheckoutStatus    `json:"status"`
+	Metadata map[string]string `json:"metadata"`
 }
 
-type CreatePaymentOrderResponse struct {
-	ID          string            `json:"id"`
-	Amount      float64           `json:"amount"`
-	Currency    string            `json:"currency"`
-	Receipt     string            `json:"receipt"`
-	Status      string            `json:"status"`
-	CreatedAt   int64             `json:"created_at"`
-	PaymentID   string            `json:"payment_id"`
-	WebhookSent bool              `json:"webhook_sent"`
-	Metadata    map[string]string `json:"metadata,omitempty"`
+type RefundRequest struct {
+	CheckoutSessionID string
+	Amount            float32
+	RefundID          string // our refund id; also used as idempotency key so retries never refund twice
+}
+
+// RefundResult is the gateway's answer to a refund request
+type RefundResult struct {
+	RefundID string
+	Status   string // gateway refund status, e.g. "pending", "succeeded", "failed"
+}
+
+type EventType string
+
+const (
+	EventCheckoutCompleted EventType = "checkout.completed"
+	EventCheckoutFailed    EventType = "checkout.failed"
+	EventRefundUpdated     EventType = "refund.updated"
+	EventOther             EventType = "other" // events the booking flow does not act on
+)
+
+// WebhookEvent is a verified gateway notification. Checkout is set for checkout events and Refund
+// for refund events.
+type WebhookEvent struct {
+	ID          string           `json:"id"`
+	Type        EventType        `json:"type"`
+	GatewayType string           `json:"gateway_type"` // the gateway's own name for the event, e.g. "checkout.session.completed"
+	Checkout    *CheckoutSession `json:"checkout,omitempty"`
+	Refund      *RefundEvent     `json:"refund,omitempty"`
 }
 
-type PaymentsMetadata struct {
-	BookingID int64 `json:"booking_id"`
+type RefundEvent struct {
+	GatewayRefundID string `json:"gateway_refund_id"`
+	RefundID        string `json:"refund_id"` // empty for refunds not issued through Gateway.Refund
+	Status          string `json:"status"`
+	FailureReason   string `json:"failure_reason"`
 }
 
-const BASE_URL = "http://localhost:4000"
+const RefundIdMetadataKey = "refund_id"
@@ -0,0 +1,158 @@
+package refunds
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"log"
+	"time"
+)
+
+// Processor sends refunds to the payment gateway and keeps the refunds table in step with the
+// gateway's view of them.
+type Processor struct {
+	storage store.StorageService
+	gateway payments.Gateway
+}
+
+func NewProcessor(storage store.StorageService, gateway payments.Gateway) *Processor {
+	return &Processor{
+		storage: storage,
+		gateway: gateway,
+	}
+}
+
+// Attempt sends a pending refund to the gateway and records the attempt. A refund the gateway rejects
+// stays pending so RetryPendingRefunds can try again, until it runs out of attempts and is marked failed.
+func (p *Processor) Attempt(refund *store.Refund) error {
+	payment, err := p.storage.GetPaymentById(refund.PaymentID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return fmt.Errorf("payment %s of refund %s not found", refund.PaymentID, refund.ID)
+	}
+
+	attemptedStatus := refund.Status
+	refund.Attempts++
+	refund.LastAttemptAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	result, gatewayErr := p.gateway.Refund(&payments.RefundRequest{
+		CheckoutSessionID: payment.CheckoutSessionId,
+		Amount:            refund.Amount,
+		RefundID:          refund.ID,
+	})
+	if gatewayErr != nil {
+		refund.FailureReason = gatewayErr.Error()
+		if refund.Attempts >= constants.MaxRefundAttempts {
+			refund.Status = constants.REFUND_FAILED
+		}
+		updated, err := p.storage.UpdateRefund(refund, attemptedStatus)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			log.Printf("Refund %s was settled while it was being attempted, leaving it as it is", refund.ID)
+			return nil
+		}
+		return fmt.Errorf("gateway rejected refund %s: %w", refund.ID, gatewayErr)
+	}
+
+	refund.GatewayRefundID = result.RefundID
+	refund.FailureReason = ""
+	refund.Status = constants.REFUND_PROCESSING
+	updated, err := p.storage.UpdateRefund(refund, attemptedStatus)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// A refund webhook got here first; what it wrote is newer than the gateway's response
+		log.Printf("Refund %s was settled while it was being attempted, leaving it as it is", refund.ID)
+		return nil
+	}
+	return p.UpdateFromGateway(refund.ID, result.RefundID, result.Status, "")
+}
+
+// RetryPendingRefunds attempts every refund the gateway has not accepted yet
+func (p *Processor) RetryPendingRefunds() error {
+	pendingRefunds, err := p.storage.GetPendingRefunds()
+	if err != nil {
+		return err
+	}
+	for _, refund := range pendingRefunds {
+		if err = p.Attempt(refund); err != nil {
+			log.Printf("Refund %s attempt %d failed: %v", refund.ID, refund.Attempts, err)
+		}
+	}
+	return nil
+}
+
+// UpdateFromGateway applies a refund status reported by the gateway, either in its response to
+// Attempt or through a refund webhook. Once all of a payment has been refunded the payment is
+// marked refunded. Refunds that already reached a final status are left untouched.
+func (p *Processor) UpdateFromGateway(refundId string, gatewayRefundId string, gatewayStatus string, failureReason string) (err error) {
+	tx, err := p.storage.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	refund, err := p.storage.GetRefundByIdTx(tx, refundId)
+	if err != nil {
+		return err
+	}
+	if refund == nil {
+		return fmt.Errorf("refund %s not found", refundId)
+	}
+	if refund.Status == constants.REFUND_SUCCESS || refund.Status == constants.REFUND_FAILED {
+		return nil
+	}
+
+	refund.Status = refundStatusFromGateway(gatewayStatus)
+	if gatewayRefundId != "" {
+		refund.GatewayRefundID = gatewayRefundId
+	}
+	if failureReason != "" {
+		refund.FailureReason = failureReason
+	}
+	if err = p.storage.UpdateRefundTx(tx, refund); err != nil {
+		return err
+	}
+	if refund.Status != constants.REFUND_SUCCESS {
+		return nil
+	}
+
+	payment, err := p.storage.GetPaymentByIdTx(tx, refund.PaymentID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return fmt.Errorf("payment %s of refund %s not found", refund.PaymentID, refund.ID)
+	}
+	refunded, err := p.storage.SumRefundsByPaymentIdTx(tx, payment.ID, []constants.RefundStatus{constants.REFUND_SUCCESS})
+	if err != nil {
+		return err
+	}
+	if refunded >= payment.Amount {
+		err = p.storage.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_REFUNDED)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func refundStatusFromGateway(gatewayStatus string) constants.RefundStatus {
+	switch gatewayStatus {
+	case "succeeded":
+		return constants.REFUND_SUCCESS
+	case "failed", "canceled":
+		return constants.REFUND_FAILED
+	default:
+		return constants.REFUND_PROCESSING
+	}
+}
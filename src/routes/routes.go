@@ -2,44 +2,177 @@ package routes
 
 import (
 	"context"
+	"errors"
+	"hotel-system/src/constants"
 	"hotel-system/src/services"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 func RegisterRoutes(mux *http.ServeMux, service *services.Service) {
 	mux.HandleFunc("/login", service.Login)
 	mux.HandleFunc("/register", service.Register)
+	mux.HandleFunc("/refresh", CORSMiddleware(service.Refresh))
+	mux.HandleFunc("/logout", CORSMiddleware(service.Logout))
+	mux.HandleFunc("/verifyEmail", CORSMiddleware(service.VerifyEmail))
+	mux.HandleFunc("/resendVerificationEmail", Middleware(service.ResendVerificationEmail))
+	mux.HandleFunc("/forgotPassword", CORSMiddleware(service.ForgotPassword))
+	mux.HandleFunc("/resetPassword", CORSMiddleware(service.ResetPassword))
+	mux.HandleFunc("/loginTotp", CORSMiddleware(service.LoginTOTP))
+	mux.HandleFunc("/enrollTotp", Middleware(service.EnrollTOTP))
+	mux.HandleFunc("/confirmTotp", Middleware(service.ConfirmTOTP))
+	mux.HandleFunc("/disableTotp", Middleware(service.DisableTOTP))
 
-	mux.HandleFunc("/addHotel", Middleware(service.AddHotel))
-	mux.HandleFunc("/getHotelsList", Middleware(service.GetHotelsList))
-	mux.HandleFunc("/getHotelById", Middleware(service.GetHotelById))
+	mux.HandleFunc("/addHotel", Middleware(RequireRoles(service.AddHotel, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getHotelsList", PartnerMiddleware(service, constants.SCOPE_SEARCH, service.GetHotelsList))
+	mux.HandleFunc("/getHotelById", PartnerMiddleware(service, constants.SCOPE_SEARCH, service.GetHotelById))
+	mux.HandleFunc("/updateHotel", Middleware(RequireRoles(service.UpdateHotel, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/deactivateHotel", Middleware(RequireRoles(service.DeactivateHotel, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/reactivateHotel", Middleware(RequireRoles(service.ReactivateHotel, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/deleteHotel", Middleware(RequireRoles(service.DeleteHotel, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/setCancellationPolicy", Middleware(RequireRoles(service.SetCancellationPolicy, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/uploadHotelImage", Middleware(RequireRoles(service.UploadHotelImage, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getHotelImages", PartnerMiddleware(service, constants.SCOPE_SEARCH, service.GetHotelImages))
+	mux.HandleFunc("/reorderHotelImages", Middleware(RequireRoles(service.ReorderHotelImages, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/deleteHotelImage", Middleware(RequireRoles(service.DeleteHotelImage, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/addLandmark", Middleware(RequireRoles(service.AddLandmark, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getLandmarks", CORSMiddleware(service.GetLandmarks))
+	mux.HandleFunc("/addAmenity", Middleware(RequireRoles(service.AddAmenity, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/updateAmenity", Middleware(RequireRoles(service.UpdateAmenity, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/deleteAmenity", Middleware(RequireRoles(service.DeleteAmenity, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getAmenities", CORSMiddleware(service.GetAmenities))
+	mux.HandleFunc("/setHotelAmenities", Middleware(RequireRoles(service.SetHotelAmenities, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getHotelReviews", PartnerMiddleware(service, constants.SCOPE_SEARCH, service.GetHotelReviews))
+	mux.HandleFunc("/replyToReview", Middleware(RequireRoles(service.ReplyToReview, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/hideReview", Middleware(RequireRoles(service.HideReview, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/searchHotels", PartnerMiddleware(service, constants.SCOPE_SEARCH, service.SearchHotels))
+	mux.HandleFunc("/getHotelBookings", Middleware(RequireRoles(service.GetHotelBookings, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
 
-	mux.HandleFunc("/bookHotel", Middleware(service.BookHotel))
-	mux.HandleFunc("/getBookingById", Middleware(service.GetBookingDetailsById))
-	mux.HandleFunc("/paymentStatus", Middleware(service.GetPaymentStatus))
+	mux.HandleFunc("/bookHotel", PartnerMiddleware(service, constants.SCOPE_BOOK, service.BookHotel))
+	mux.HandleFunc("/getBookingById", PartnerMiddleware(service, constants.SCOPE_READ_BOOKINGS, service.GetBookingDetailsById))
+	mux.HandleFunc("/getMyBookings", PartnerMiddleware(service, constants.SCOPE_READ_BOOKINGS, service.GetMyBookings))
+	mux.HandleFunc("/paymentStatus", PartnerMiddleware(service, constants.SCOPE_READ_BOOKINGS, service.GetPaymentStatus))
+	mux.HandleFunc("/cancelBooking", PartnerMiddleware(service, constants.SCOPE_BOOK, service.CancelBooking))
+	mux.HandleFunc("/modifyBooking", PartnerMiddleware(service, constants.SCOPE_BOOK, service.ModifyBooking))
+	mux.HandleFunc("/addReview", Middleware(service.AddReview))
+	mux.HandleFunc("/requestRefund", Middleware(RequireRoles(service.RequestRefund, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getRefunds", Middleware(service.GetRefunds))
 
-	mux.HandleFunc("/payment-webhook", Middleware(service.PaymentWebhookHandler))
+	// Called by the payment gateway, which authenticates with the webhook signature instead of a token
+	mux.HandleFunc("/payment-webhook", service.PaymentWebhookHandler)
+	mux.HandleFunc("/getWebhookEvents", Middleware(RequireRoles(service.GetWebhookEvents, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/replayWebhookEvent", Middleware(RequireRoles(service.ReplayWebhookEvent, constants.ROLE_ADMIN)))
+
+	mux.HandleFunc("/createApiKey", Middleware(RequireRoles(service.CreateAPIKey, constants.ROLE_PARTNER, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getApiKeys", Middleware(RequireRoles(service.GetAPIKeys, constants.ROLE_PARTNER, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/revokeApiKey", Middleware(RequireRoles(service.RevokeAPIKey, constants.ROLE_PARTNER, constants.ROLE_ADMIN)))
+
+	mux.HandleFunc("/unlockAccount", Middleware(RequireRoles(service.UnlockAccount, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/getAuthAuditEvents", Middleware(RequireRoles(service.GetAuthAuditEvents, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/setUserRole", Middleware(RequireRoles(service.SetUserRole, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/addHotelStaff", Middleware(RequireRoles(service.AddHotelStaff, constants.ROLE_ADMIN)))
+	mux.HandleFunc("/removeHotelStaff", Middleware(RequireRoles(service.RemoveHotelStaff, constants.ROLE_ADMIN)))
 
 	// New route that redirects clients to the checkout URL
 	mux.HandleFunc("/test/client/checkoutUrl", CORSMiddleware(service.ClientCheckoutRedirect))
+	mux.HandleFunc("/test/fakeCheckout", CORSMiddleware(service.FakeCheckout))
 
 }
 
+// ServeLocalBlobs serves the files of a local blob store under prefix, e.g. "/blobs/". Directories are
+// not listed.
+func ServeLocalBlobs(mux *http.ServeMux, prefix string, dir string) {
+	fileServer := http.StripPrefix(prefix, http.FileServer(http.Dir(dir)))
+	mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// AuthMiddleware accepts requests carrying a valid access token as "Authorization: Bearer <token>" and
+// puts the user id, role and two-factor status from the token in the request context. Anything else
+// gets a 401.
 func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		//tokenStr := r.Header.Get("Authorization")
-		//claims, err := utils.ValidateJWT(tokenStr)
-		//if err != nil {
-		//	http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		//	return
-		//}
-
-		//ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		ctx := context.WithValue(r.Context(), "user_id", 6)
+		tokenStr := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		claims, err := utils.ValidateJWT(tokenStr)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
+		ctx = context.WithValue(ctx, "role", claims.Role)
+		ctx = context.WithValue(ctx, "mfa", claims.MFA)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+// apiKeyAuthenticator resolves a partner API key to the stored key
+type apiKeyAuthenticator interface {
+	AuthenticateAPIKey(apiKey string) (*store.APIKey, error)
+}
+
+// APIKeyMiddleware accepts requests carrying a partner API key with the scope as
+// "Authorization: ApiKey <key>" and puts the partner owning the key in the request context, the same
+// way AuthMiddleware does for a user, along with the key id. Requests without an API key are handed
+// to AuthMiddleware.
+func APIKeyMiddleware(keys apiKeyAuthenticator, scope constants.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey ")
+		if !ok {
+			AuthMiddleware(next).ServeHTTP(w, r)
+			return
+		}
+		key, err := keys.AuthenticateAPIKey(apiKey)
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			w.Header().Set("WWW-Authenticate", `ApiKey error="invalid_key"`)
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Println("Error authenticating API key:", err)
+			http.Error(w, "Something went wrong", http.StatusInternalServerError)
+			return
+		}
+		if !key.HasScope(scope) {
+			http.Error(w, "API key lacks the "+string(scope)+" scope", http.StatusForbidden)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user_id", key.UserID)
+		ctx = context.WithValue(ctx, "role", constants.ROLE_PARTNER)
+		ctx = context.WithValue(ctx, "api_key_id", key.ID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
+// RequireRoles lets through only callers with one of the given roles and answers 403 to the rest. It
+// must run after AuthMiddleware. Admins are also refused unless they logged in with a second factor.
+// Checks scoped to a hotel or a booking are left to the handler.
+func RequireRoles(next http.HandlerFunc, roles ...constants.UserRole) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role, _ := r.Context().Value("role").(constants.UserRole)
+		if !slices.Contains(roles, role) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if mfa, _ := r.Context().Value("mfa").(bool); role == constants.ROLE_ADMIN && !mfa {
+			http.Error(w, "Two-factor authentication required: enable it at /enrollTotp and log in again", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
 func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -58,3 +191,8 @@ func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 func Middleware(next http.HandlerFunc) http.HandlerFunc {
 	return CORSMiddleware(AuthMiddleware(next))
 }
+
+// PartnerMiddleware is Middleware for endpoints partners may also call with an API key of the scope
+func PartnerMiddleware(keys apiKeyAuthenticator, scope constants.APIKeyScope, next http.HandlerFunc) http.HandlerFunc {
+	return CORSMiddleware(APIKeyMiddleware(keys, scope, next))
+}
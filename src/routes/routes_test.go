@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/services"
+	"hotel-system/src/store"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAPIKeys authenticates "good" as a key of partner 7 with the search scope, fails "broken" and
+// refuses every other key
+type stubAPIKeys struct{}
+
+func (stubAPIKeys) AuthenticateAPIKey(apiKey string) (*store.APIKey, error) {
+	switch apiKey {
+	case "good":
+		return store.NewAPIKey("k1", 7, "booking engine", "good", "hash", []constants.APIKeyScope{constants.SCOPE_SEARCH}), nil
+	case "broken":
+		return nil, errors.New("database unavailable")
+	}
+	return nil, services.ErrInvalidAPIKey
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		authorization  string
+		scope          constants.APIKeyScope
+		expectedStatus int
+	}{
+		{"key with the scope", "ApiKey good", constants.SCOPE_SEARCH, http.StatusOK},
+		{"key without the scope", "ApiKey good", constants.SCOPE_BOOK, http.StatusForbidden},
+		{"revoked or unknown key", "ApiKey revoked", constants.SCOPE_SEARCH, http.StatusUnauthorized},
+		{"key lookup failing", "ApiKey broken", constants.SCOPE_SEARCH, http.StatusInternalServerError},
+		{"no key falls back to the login token", "Bearer not-a-jwt", constants.SCOPE_SEARCH, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userId any
+			var role any
+			handler := APIKeyMiddleware(stubAPIKeys{}, tt.scope, func(w http.ResponseWriter, r *http.Request) {
+				userId, role = r.Context().Value("user_id"), r.Context().Value("role")
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/searchHotels", nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, 7, userId)
+				assert.Equal(t, constants.ROLE_PARTNER, role)
+			}
+		})
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"context"
 	"fmt"
 	"hotel-system/src/store"
+	"hotel-system/src/utils"
 	"log"
 
 	"github.com/robfig/cron/v3"
 )
 
 type BookingScheduler struct {
-	storage store.StorageService
+	storage    store.StorageService
+	reconciler PaymentReconciler
 }
 
-func NewBookingScheduler(storage store.StorageService) *BookingScheduler {
+func NewBookingScheduler(storage store.StorageService, reconciler PaymentReconciler) *BookingScheduler {
 	return &BookingScheduler{
-		storage: storage,
+		storage:    storage,
+		reconciler: reconciler,
 	}
 }
 
@@ -42,7 +45,7 @@ func (bs *BookingScheduler) ReleaseCompletedBookings() error {
 	if err != nil {
 		return err
 	}
-	err = bs.releaseBookings(bookings, store.BOOKING_COMPLETED)
+	err = bs.releaseBookings(bookings, store.BOOKING_CONFIRMED, store.BOOKING_COMPLETED)
 	if err != nil {
 		return fmt.Errorf("failed to release completed bookings: %w", err)
 	}
@@ -50,45 +53,77 @@ func (bs *BookingScheduler) ReleaseCompletedBookings() error {
 }
 
 func (bs *BookingScheduler) ExpireStaleBookings() error {
-	bookings, err := bs.storage.GetExpiredBookings()
+	staleBookings, err := bs.storage.GetExpiredBookings()
 	if err != nil {
 		return err
 	}
-	err = bs.releaseBookings(bookings, store.BOOKING_EXPIRED)
+	// A stale booking may have been paid with the webhook lost; the gateway has the final say, so a
+	// booking is only expired once the gateway has answered that its checkout is not paid
+	var bookings []*store.Booking
+	for _, b := range staleBookings {
+		settled, reconcileErr := bs.reconciler.ReconcileBooking(int64(b.BookingID))
+		if reconcileErr != nil {
+			log.Printf("Failed to reconcile booking %d before expiring it, retrying on the next run: %v", b.BookingID, reconcileErr)
+			continue
+		}
+		if !settled {
+			bookings = append(bookings, b)
+		}
+	}
+	err = bs.releaseBookings(bookings, store.BOOKING_PENDING, store.BOOKING_EXPIRED)
 	if err != nil {
 		return fmt.Errorf("failed to release expired bookings: %w", err)
 	}
 	return nil
 }
 
-func (bs *BookingScheduler) releaseBookings(bookings []*store.Booking, newStatus store.BookingStatus) error {
+func (bs *BookingScheduler) releaseBookings(bookings []*store.Booking, fromStatus, newStatus store.BookingStatus) error {
 
 	for _, b := range bookings {
-		ctx := context.Background()
-		tx, err := bs.storage.BeginTransaction(ctx)
-		if err != nil {
-			log.Printf("Failed to begin transaction for booking %d: %v\n", b.BookingID, err)
-			continue
+		if err := bs.releaseBooking(b, fromStatus, newStatus); err != nil {
+			log.Printf("Failed to release booking %d: %v", b.BookingID, err)
 		}
-		hotel, err := bs.storage.GetHotelForUpdate(tx, int64(b.HotelID))
-		if err != nil {
-			continue
-		}
-		fmt.Printf("Releasing booking %d for hotel %s\n", b.BookingID, hotel.Name)
-		newRooms := hotel.AvailableRooms + b.NumberOfRooms
-		if newRooms <= hotel.TotalRooms {
-			err = bs.storage.IncrementHotelRoomsTx(tx, int64(hotel.ID), b.NumberOfRooms)
-		}
-		if err != nil {
-			log.Printf("Failed to increment hotel rooms for booking %d: %v", b.BookingID, err)
-			continue
-		}
-		err = bs.storage.UpdateBookingStatusTx(tx, int64(b.BookingID), newStatus)
+	}
+
+	return nil
+}
+
+// releaseBooking moves a booking from fromStatus to newStatus. The booking was listed before the
+// transaction began, and may have been paid, cancelled or modified since, so it is read again once the
+// hotel is locked and left alone unless it is still in fromStatus.
+func (bs *BookingScheduler) releaseBooking(listed *store.Booking, fromStatus, newStatus store.BookingStatus) (err error) {
+	ctx := context.Background()
+	tx, err := bs.storage.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	hotel, err := bs.storage.GetHotelForUpdate(tx, int64(listed.HotelID))
+	if err != nil {
+		return fmt.Errorf("failed to lock hotel %d: %w", listed.HotelID, err)
+	}
+	b, err := bs.storage.GetBookingByIdTx(tx, int64(listed.BookingID))
+	if err != nil {
+		return fmt.Errorf("failed to lock booking %d: %w", listed.BookingID, err)
+	}
+	if b.Status != fromStatus {
+		log.Printf("Booking %d is %s now, not %s; leaving it", b.BookingID, b.Status, fromStatus)
+		return nil
+	}
+	fmt.Printf("Releasing booking %d for hotel %s\n", b.BookingID, hotel.Name)
+
+	// The nights of a completed stay are in the past, so they stay sold on the calendar
+	if newStatus != store.BOOKING_COMPLETED {
+		err = bs.storage.ReleaseHotelInventoryTx(tx, int64(b.RoomTypeID), b.CheckInDate, b.CheckOutDate, b.NumberOfRooms)
 		if err != nil {
-			log.Printf("Failed to update booking status for booking %d: %v", b.BookingID, err)
-			continue
+			return fmt.Errorf("failed to release hotel inventory: %w", err)
 		}
 	}
 
+	err = bs.storage.UpdateBookingStatusTx(tx, int64(b.BookingID), newStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// OutboxDispatcher sends the queued notification emails that are due for an attempt
+type OutboxDispatcher interface {
+	DispatchDue() error
+}
+
+type OutboxScheduler struct {
+	dispatcher OutboxDispatcher
+}
+
+func NewOutboxScheduler(dispatcher OutboxDispatcher) *OutboxScheduler {
+	return &OutboxScheduler{
+		dispatcher: dispatcher,
+	}
+}
+
+func (ob *OutboxScheduler) Start() {
+	c := cron.New()
+	// Runs every 15 seconds
+	c.AddFunc("@every 15s", func() {
+		if err := ob.dispatcher.DispatchDue(); err != nil {
+			log.Printf("Failed to dispatch outbox messages: %v", err)
+		}
+	})
+
+	c.Start()
+}
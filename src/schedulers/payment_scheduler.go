@@ -1,49 +1,42 @@
 package scheduler
 
 import (
-	"hotel-system/src/constants"
-	"hotel-system/src/store"
 	"log"
 
 	"github.com/robfig/cron/v3"
 )
 
+// PaymentReconciler settles pending payments from the gateway's view of their checkouts
+type PaymentReconciler interface {
+	ReconcilePendingPayments() error
+	ReconcileBooking(bookingId int64) (bool, error)
+	ExpireBookingModifications() error
+}
+
 type PaymentScheduler struct {
-	storage store.StorageService
+	reconciler PaymentReconciler
 }
 
-func NewPaymentScheduler(storage store.StorageService) *PaymentScheduler {
+func NewPaymentScheduler(reconciler PaymentReconciler) *PaymentScheduler {
 	return &PaymentScheduler{
-		storage: storage,
+		reconciler: reconciler,
 	}
 }
 
-// TODO: Implement payment scheduler to mark pending payments as failed after certain time period
-
 func (ps *PaymentScheduler) Start() {
 	c := cron.New()
-	// Runs every 10 minutes
-	c.AddFunc("*/10 * * * *", func() {
-		err := ps.MarkPendingPaymentsAsFailure()
+	// Runs every 5 minutes
+	c.AddFunc("*/5 * * * *", func() {
+		err := ps.reconciler.ReconcilePendingPayments()
 		if err != nil {
-			log.Printf("Failed to release payments: %v", err)
+			log.Printf("Failed to reconcile pending payments: %v", err)
+		}
+	})
+	c.AddFunc("*/1 * * * *", func() {
+		if err := ps.reconciler.ExpireBookingModifications(); err != nil {
+			log.Printf("Failed to expire booking modifications: %v", err)
 		}
 	})
 
 	c.Start()
 }
-
-func (ps *PaymentScheduler) MarkPendingPaymentsAsFailure() error {
-	expiredPayments, err := ps.storage.GetExpiredPayments()
-	if err != nil {
-		return err
-	}
-	for _, payment := range expiredPayments {
-		updateErr := ps.storage.UpdatePaymentStatus(payment.ID, constants.PAYMENT_FAILED)
-		if updateErr != nil {
-
-			log.Printf("Failed to update payment %v to failed: %v", payment.ID, updateErr)
-		}
-	}
-	return nil
-}
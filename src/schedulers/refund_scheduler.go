@@ -0,0 +1,30 @@
+package scheduler
+
+import (
+	"hotel-system/src/refunds"
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+type RefundScheduler struct {
+	processor *refunds.Processor
+}
+
+func NewRefundScheduler(processor *refunds.Processor) *RefundScheduler {
+	return &RefundScheduler{
+		processor: processor,
+	}
+}
+
+func (rs *RefundScheduler) Start() {
+	c := cron.New()
+	// Runs every 5 minutes
+	c.AddFunc("*/5 * * * *", func() {
+		if err := rs.processor.RetryPendingRefunds(); err != nil {
+			log.Printf("Failed to retry pending refunds: %v", err)
+		}
+	})
+
+	c.Start()
+}
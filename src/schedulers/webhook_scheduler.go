@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// WebhookProcessor applies the webhook inbox events that are due for an attempt
+type WebhookProcessor interface {
+	ProcessDueWebhookEvents() error
+}
+
+type WebhookScheduler struct {
+	processor WebhookProcessor
+}
+
+func NewWebhookScheduler(processor WebhookProcessor) *WebhookScheduler {
+	return &WebhookScheduler{
+		processor: processor,
+	}
+}
+
+func (ws *WebhookScheduler) Start() {
+	c := cron.New()
+	// Runs every 30 seconds
+	c.AddFunc("@every 30s", func() {
+		if err := ws.processor.ProcessDueWebhookEvents(); err != nil {
+			log.Printf("Failed to process webhook events: %v", err)
+		}
+	})
+
+	c.Start()
+}
@@ -4,17 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/pb"
 	"hotel-system/src/store"
 	"hotel-system/src/types/hotelsystem"
 	"hotel-system/src/utils"
 	"log"
 	"strconv"
 	"time"
-
-	"github.com/stripe/stripe-go/v82"
 )
 
-func HotelsResponseSerializer(hotels []store.Hotel) hotelsystem.GetHotelsListResponse {
+// HotelsResponseSerializer converts listed hotels; amenities holds the amenities of each hotel, keyed by
+// hotel id.
+func HotelsResponseSerializer(hotels []store.Hotel, amenities map[int][]*store.Amenity, totalRecords int64) hotelsystem.GetHotelsListResponse {
 	var hotelsList []*hotelsystem.HotelData
 	for _, hotel := range hotels {
 		hotelDataItem := &hotelsystem.HotelData{
@@ -23,27 +25,136 @@ func HotelsResponseSerializer(hotels []store.Hotel) hotelsystem.GetHotelsListRes
 			Description:  hotel.Description,
 			Images:       hotel.ImageUrls,
 			CostPerNight: float32(hotel.CostPerNight),
+			DistanceKm:   hotel.DistanceKm,
+			Amenities:    AmenitiesSerializer(amenities[hotel.ID]),
+			Rating:       hotel.AverageRating(),
+			ReviewCount:  int64(hotel.ReviewCount),
 		}
 		hotelsList = append(hotelsList, hotelDataItem)
 	}
 	return hotelsystem.GetHotelsListResponse{
-		HotelsList: hotelsList,
+		TotalRecords: totalRecords,
+		HotelsList:   hotelsList,
+	}
+}
+
+// AvailableHotelsResponseSerializer converts availability search results, pricing each hotel's
+// room type for the whole stay. amenities holds the amenities of each hotel, keyed by hotel id.
+func AvailableHotelsResponseSerializer(hotels []*store.AvailableHotel, amenities map[int][]*store.Amenity, totalRecords int64, numNights int, numRooms int) hotelsystem.GetHotelsListResponse {
+	var hotelsList []*hotelsystem.HotelData
+	for _, ah := range hotels {
+		hotelsList = append(hotelsList, &hotelsystem.HotelData{
+			ID:             strconv.FormatInt(int64(ah.Hotel.ID), 10),
+			Name:           ah.Hotel.Name,
+			Description:    ah.Hotel.Description,
+			Images:         ah.Hotel.ImageUrls,
+			CostPerNight:   ah.RoomTypeCostPerNight,
+			RoomTypeID:     int64(ah.RoomTypeID),
+			RoomTypeName:   ah.RoomTypeName,
+			AvailableRooms: int64(ah.AvailableRooms),
+			TotalPrice:     ah.RoomTypeCostPerNight * float32(numRooms) * float32(numNights),
+			DistanceKm:     ah.Hotel.DistanceKm,
+			Amenities:      AmenitiesSerializer(amenities[ah.Hotel.ID]),
+			Rating:         ah.Hotel.AverageRating(),
+			ReviewCount:    int64(ah.Hotel.ReviewCount),
+		})
+	}
+	return hotelsystem.GetHotelsListResponse{
+		TotalRecords: totalRecords,
+		HotelsList:   hotelsList,
 	}
 }
 
-func HotelByIdResponseSerializer(hotel store.Hotel) *hotelsystem.GetHotelByIdResponse {
+func HotelByIdResponseSerializer(hotel store.Hotel, roomTypes []*store.RoomType, maxRoomsSold map[int]int, policy *store.CancellationPolicy, amenities []*store.Amenity, roomTypeAmenities map[int][]*store.Amenity) *hotelsystem.GetHotelByIdResponse {
 
 	addressString := fmt.Sprintf("%s, %s, %s, %s, %d, %s", hotel.Street, hotel.Landmark, hotel.Locality, hotel.City, hotel.Pincode, hotel.State)
 
-	return &hotelsystem.GetHotelByIdResponse{
-		ID:             int64(hotel.ID),
-		Name:           hotel.Name,
-		Description:    hotel.Description,
-		Images:         hotel.ImageUrls,
-		CostPerNight:   int64(hotel.CostPerNight),
-		AvailableRooms: int64(hotel.AvailableRooms),
-		Address:        addressString,
+	roomTypesData := RoomTypesResponseSerializer(roomTypes, maxRoomsSold, roomTypeAmenities)
+	var availableRooms int64
+	for _, rt := range roomTypesData {
+		availableRooms += rt.AvailableRooms
 	}
+
+	hotelResponse := &hotelsystem.GetHotelByIdResponse{
+		ID:                 int64(hotel.ID),
+		Name:               hotel.Name,
+		Description:        hotel.Description,
+		Images:             hotel.ImageUrls,
+		CostPerNight:       int64(hotel.CostPerNight),
+		AvailableRooms:     availableRooms,
+		Address:            addressString,
+		RoomTypes:          roomTypesData,
+		CancellationPolicy: CancellationPolicyResponseSerializer(policy),
+		Status:             string(hotel.Status),
+		Amenities:          AmenitiesSerializer(amenities),
+		Rating:             hotel.AverageRating(),
+		ReviewCount:        int64(hotel.ReviewCount),
+	}
+	if hotel.Latitude.Valid && hotel.Longitude.Valid {
+		hotelResponse.Latitude = &hotel.Latitude.Float64
+		hotelResponse.Longitude = &hotel.Longitude.Float64
+	}
+	return hotelResponse
+}
+
+func CancellationPolicySerializer(hotelId int64, policy *hotelsystem.CancellationPolicy) *store.CancellationPolicy {
+	return store.NewCancellationPolicy(
+		int(hotelId),
+		int(policy.FreeCancellationHours),
+		policy.PenaltyPercent,
+		policy.NonRefundable,
+	)
+}
+
+func CancellationPolicyResponseSerializer(policy *store.CancellationPolicy) *hotelsystem.CancellationPolicy {
+	if policy == nil {
+		return nil
+	}
+	return &hotelsystem.CancellationPolicy{
+		FreeCancellationHours: int32(policy.FreeCancellationHours),
+		PenaltyPercent:        policy.PenaltyPercent,
+		NonRefundable:         policy.NonRefundable,
+	}
+}
+
+// RoomTypesResponseSerializer converts room types to their response form. maxRoomsSold holds the
+// highest number of rooms sold per room type over the requested nights; a nil map means nothing is sold.
+// amenities holds the amenities of each room type, keyed by room type id.
+func RoomTypesResponseSerializer(roomTypes []*store.RoomType, maxRoomsSold map[int]int, amenities map[int][]*store.Amenity) []*hotelsystem.RoomTypeData {
+	var roomTypesData []*hotelsystem.RoomTypeData
+	for _, rt := range roomTypes {
+		available := rt.TotalRooms - maxRoomsSold[rt.ID]
+		if available < 0 {
+			available = 0
+		}
+		roomTypesData = append(roomTypesData, &hotelsystem.RoomTypeData{
+			ID:             int64(rt.ID),
+			Name:           rt.Name,
+			Description:    rt.Description,
+			TotalRooms:     int64(rt.TotalRooms),
+			MaxOccupancy:   int32(rt.MaxOccupancy),
+			CostPerNight:   rt.CostPerNight,
+			Images:         rt.ImageUrls,
+			AvailableRooms: int64(available),
+			Amenities:      AmenitiesSerializer(amenities[rt.ID]),
+		})
+	}
+	return roomTypesData
+}
+
+// RoomTypesSerializer builds the room types of a new hotel. A hotel added without room types gets a
+// single "Standard" room type carrying the hotel's own room count and price.
+func RoomTypesSerializer(addHotelRequest *hotelsystem.AddHotelRequest) []*store.RoomType {
+	if len(addHotelRequest.RoomTypes) == 0 {
+		return []*store.RoomType{
+			store.NewRoomType(0, constants.DefaultRoomTypeName, "", int(addHotelRequest.TotalRooms), constants.DefaultMaxOccupancy, float32(addHotelRequest.CostPerNight), addHotelRequest.GetImages()),
+		}
+	}
+	var roomTypes []*store.RoomType
+	for _, rt := range addHotelRequest.RoomTypes {
+		roomTypes = append(roomTypes, store.NewRoomType(0, rt.Name, rt.Description, int(rt.TotalRooms), int(rt.MaxOccupancy), rt.CostPerNight, rt.GetImages()))
+	}
+	return roomTypes
 }
 
 func BookingSerializer(bookHotelRequest *hotelsystem.BookHotelRequest, userId int) *store.Booking {
@@ -54,6 +165,7 @@ func BookingSerializer(bookHotelRequest *hotelsystem.BookHotelRequest, userId in
 	}
 	booking := &store.Booking{
 		HotelID:       int(bookHotelRequest.HotelID),
+		RoomTypeID:    int(bookHotelRequest.RoomTypeID),
 		UserID:        userId,
 		NumberOfRooms: int(bookHotelRequest.NumRooms),
 		NumberOfDays:  int(bookHotelRequest.NumDays),
@@ -94,12 +206,14 @@ func BookHotelResponseSerializer(checkoutUrl string, bookingId int64, booking *s
 	var bookingDetails *hotelsystem.BookHotelResponseBookingDetails
 	if booking != nil {
 		bookingDetails = &hotelsystem.BookHotelResponseBookingDetails{
-			BookingID:   bookingId,
-			HotelID:     int64(booking.HotelID),
-			NumRooms:    int32(booking.NumberOfRooms),
-			NumDays:     int32(booking.NumberOfDays),
-			CheckInDate: booking.CheckInDate.String(),
-			TotalCost:   totalCost,
+			BookingID:        bookingId,
+			HotelID:          int64(booking.HotelID),
+			NumRooms:         int32(booking.NumberOfRooms),
+			NumDays:          int32(booking.NumberOfDays),
+			CheckInDate:      booking.CheckInDate.String(),
+			TotalCost:        totalCost,
+			RoomTypeID:       int64(booking.RoomTypeID),
+			BookingReference: booking.Reference,
 		}
 	}
 	return &hotelsystem.BookHotelResponse{
@@ -122,33 +236,271 @@ func CreatePaymentSerializer(paymentId string, bookingId int64, checkoutSessioni
 	}
 }
 
-func CreateStripeCheckoutSessionParams(bookingId int64, hotel store.Hotel, totalCost float32) *stripe.CheckoutSessionParams {
+func CheckoutRequestSerializer(bookingId int64, paymentId string, hotel store.Hotel, roomType *store.RoomType, totalCost float32) *payments.CheckoutRequest {
 	paymentStatusUrl := "http://localhost:6000/status"
-	params := &stripe.CheckoutSessionParams{
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				// Provide the exact Price ID (for example, price_1234) of the product you want to sell
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency:          stripe.String(stripe.CurrencyINR),
-					UnitAmountDecimal: stripe.Float64(float64(totalCost * 100)),
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String("Hotel Booking for " + hotel.Name),
-					},
-				},
-				Quantity: stripe.Int64(1),
-			},
-		},
-		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
-			Metadata: map[string]string{
-				constants.BookingIdField: fmt.Sprintf("%d", bookingId),
-			},
+	return &payments.CheckoutRequest{
+		Amount:        totalCost,
+		Currency:      "usd",
+		Description:   "Hotel Booking for " + hotel.Name + " (" + roomType.Name + ")",
+		CustomerEmail: "k@gmail.com",
+		SuccessURL:    paymentStatusUrl,
+		CancelURL:     paymentStatusUrl,
+		Metadata: map[string]string{
+			constants.BookingIdField: fmt.Sprintf("%d", bookingId),
+			"payment_id":             paymentId,
 		},
-		Mode:          stripe.String(string(stripe.CheckoutSessionModePayment)),
-		CustomerEmail: stripe.String("k@gmail.com"),
-		SuccessURL:    stripe.String(paymentStatusUrl),
-		CancelURL:     stripe.String(paymentStatusUrl),
 	}
+}
+
+func RefundResponseSerializer(refund *store.Refund) *hotelsystem.RefundData {
+	return &hotelsystem.RefundData{
+		RefundID:      refund.ID,
+		BookingID:     int64(refund.BookingID),
+		PaymentID:     refund.PaymentID,
+		Amount:        refund.Amount,
+		Status:        string(refund.Status),
+		Reason:        refund.Reason,
+		Attempts:      int32(refund.Attempts),
+		FailureReason: refund.FailureReason,
+		CreatedAt:     refund.CreatedAt.String(),
+	}
+}
+
+func RefundsResponseSerializer(refunds []*store.Refund) *hotelsystem.GetRefundsResponse {
+	var refundsData []*hotelsystem.RefundData
+	for _, refund := range refunds {
+		refundsData = append(refundsData, RefundResponseSerializer(refund))
+	}
+	return &hotelsystem.GetRefundsResponse{
+		Refunds: refundsData,
+	}
+}
+
+func WebhookEventResponseSerializer(event *store.WebhookEvent) *hotelsystem.WebhookEventData {
+	eventData := &hotelsystem.WebhookEventData{
+		EventID:       event.ID,
+		Type:          event.Type,
+		GatewayType:   event.GatewayType,
+		Status:        string(event.Status),
+		Attempts:      int32(event.Attempts),
+		LastError:     event.LastError,
+		Payload:       string(event.Payload),
+		ReceivedAt:    event.ReceivedAt.String(),
+		NextAttemptAt: event.NextAttemptAt.String(),
+	}
+	if event.ProcessedAt.Valid {
+		eventData.ProcessedAt = event.ProcessedAt.Time.String()
+	}
+	return eventData
+}
+
+func WebhookEventsResponseSerializer(events []*store.WebhookEvent) *hotelsystem.GetWebhookEventsResponse {
+	var eventsData []*hotelsystem.WebhookEventData
+	for _, event := range events {
+		eventsData = append(eventsData, WebhookEventResponseSerializer(event))
+	}
+	return &hotelsystem.GetWebhookEventsResponse{
+		Events: eventsData,
+	}
+}
+
+func BookingResponseSerializer(booking *store.Booking) *hotelsystem.BookingData {
+	return &hotelsystem.BookingData{
+		BookingID:        int64(booking.BookingID),
+		HotelID:          int64(booking.HotelID),
+		RoomTypeID:       int64(booking.RoomTypeID),
+		UserID:           int64(booking.UserID),
+		NumRooms:         int32(booking.NumberOfRooms),
+		NumDays:          int32(booking.NumberOfDays),
+		CheckInDate:      booking.CheckInDate.Format(constants.DateFormat),
+		CheckOutDate:     booking.CheckOutDate.Format(constants.DateFormat),
+		Status:           string(booking.Status),
+		BookingTime:      booking.BookingTime.String(),
+		BookingReference: booking.Reference,
+	}
+}
 
-	params.AddExpand("payment_intent")
-	return params
+func HotelBookingsResponseSerializer(bookings []*store.Booking) *hotelsystem.GetHotelBookingsResponse {
+	var bookingsData []*hotelsystem.BookingData
+	for _, booking := range bookings {
+		bookingsData = append(bookingsData, BookingResponseSerializer(booking))
+	}
+	return &hotelsystem.GetHotelBookingsResponse{
+		Bookings: bookingsData,
+	}
+}
+
+func MyBookingsResponseSerializer(bookings []*store.UserBooking, totalRecords int64) *hotelsystem.GetMyBookingsResponse {
+	var bookingsData []*hotelsystem.MyBookingData
+	for _, ub := range bookings {
+		bookingsData = append(bookingsData, &hotelsystem.MyBookingData{
+			BookingID:             int64(ub.Booking.BookingID),
+			BookingReference:      ub.Booking.Reference,
+			HotelID:               int64(ub.Booking.HotelID),
+			HotelName:             ub.HotelName,
+			RoomTypeID:            int64(ub.Booking.RoomTypeID),
+			RoomTypeName:          ub.RoomTypeName,
+			NumRooms:              int32(ub.Booking.NumberOfRooms),
+			NumDays:               int32(ub.Booking.NumberOfDays),
+			CheckInDate:           ub.Booking.CheckInDate.Format(constants.DateFormat),
+			CheckOutDate:          ub.Booking.CheckOutDate.Format(constants.DateFormat),
+			Status:                string(ub.Booking.Status),
+			TotalCost:             ub.Booking.TotalCost,
+			PaymentStatus:         ub.PaymentStatus.String,
+			BookingTime:           ub.Booking.BookingTime.String(),
+			LatePaymentResolution: string(ub.Booking.LatePaymentResolution),
+		})
+	}
+	return &hotelsystem.GetMyBookingsResponse{
+		TotalRecords: totalRecords,
+		Bookings:     bookingsData,
+	}
+}
+
+func AuthAuditEventsResponseSerializer(events []*store.AuthAuditEvent) *pb.GetAuthAuditEventsResponse {
+	var eventsData []*pb.AuthAuditEventData
+	for _, event := range events {
+		eventsData = append(eventsData, &pb.AuthAuditEventData{
+			EventId:     event.ID,
+			Type:        string(event.Type),
+			Username:    event.Username,
+			Ip:          event.IP,
+			ActorUserId: event.ActorUserID.Int64,
+			Detail:      event.Detail,
+			CreatedAt:   event.CreatedAt.String(),
+		})
+	}
+	return &pb.GetAuthAuditEventsResponse{
+		Events: eventsData,
+	}
+}
+
+func ApiKeySerializer(key *store.APIKey) *pb.ApiKeyData {
+	keyData := &pb.ApiKeyData{
+		KeyId:         key.ID,
+		PartnerUserId: int64(key.UserID),
+		Name:          key.Name,
+		Prefix:        key.Prefix,
+		Scopes:        key.Scopes,
+		CreatedAt:     key.CreatedAt.String(),
+	}
+	if key.LastUsedAt.Valid {
+		keyData.LastUsedAt = key.LastUsedAt.Time.String()
+	}
+	if key.RevokedAt.Valid {
+		keyData.RevokedAt = key.RevokedAt.Time.String()
+	}
+	return keyData
+}
+
+func ApiKeysResponseSerializer(keys []*store.APIKey) *pb.GetApiKeysResponse {
+	var keysData []*pb.ApiKeyData
+	for _, key := range keys {
+		keysData = append(keysData, ApiKeySerializer(key))
+	}
+	return &pb.GetApiKeysResponse{
+		Keys: keysData,
+	}
+}
+
+func HotelImageResponseSerializer(image *store.HotelImage) *hotelsystem.HotelImageData {
+	return &hotelsystem.HotelImageData{
+		ImageID:      image.ID,
+		HotelID:      int64(image.HotelID),
+		Position:     int32(image.Position),
+		URL:          image.URL,
+		ThumbnailURL: image.ThumbnailURL,
+		ContentType:  image.ContentType,
+		SizeBytes:    image.SizeBytes,
+		Width:        int32(image.Width),
+		Height:       int32(image.Height),
+		CreatedAt:    image.CreatedAt.String(),
+	}
+}
+
+func HotelImagesResponseSerializer(images []*store.HotelImage) *hotelsystem.GetHotelImagesResponse {
+	var imagesData []*hotelsystem.HotelImageData
+	for _, image := range images {
+		imagesData = append(imagesData, HotelImageResponseSerializer(image))
+	}
+	return &hotelsystem.GetHotelImagesResponse{
+		Images: imagesData,
+	}
+}
+
+func LandmarkResponseSerializer(landmark *store.Landmark) *hotelsystem.LandmarkData {
+	return &hotelsystem.LandmarkData{
+		LandmarkID: int64(landmark.ID),
+		Name:       landmark.Name,
+		City:       landmark.City,
+		Latitude:   landmark.Latitude,
+		Longitude:  landmark.Longitude,
+	}
+}
+
+func LandmarksResponseSerializer(landmarks []*store.Landmark) *hotelsystem.GetLandmarksResponse {
+	var landmarksData []*hotelsystem.LandmarkData
+	for _, landmark := range landmarks {
+		landmarksData = append(landmarksData, LandmarkResponseSerializer(landmark))
+	}
+	return &hotelsystem.GetLandmarksResponse{
+		Landmarks: landmarksData,
+	}
+}
+
+func AmenitySerializer(amenity *store.Amenity) *hotelsystem.AmenityData {
+	return &hotelsystem.AmenityData{
+		Code:     amenity.Code,
+		Name:     amenity.Name,
+		Category: amenity.Category,
+		Icon:     amenity.Icon,
+	}
+}
+
+func AmenitiesSerializer(amenities []*store.Amenity) []*hotelsystem.AmenityData {
+	var amenitiesData []*hotelsystem.AmenityData
+	for _, amenity := range amenities {
+		amenitiesData = append(amenitiesData, AmenitySerializer(amenity))
+	}
+	return amenitiesData
+}
+
+func AmenitiesResponseSerializer(amenities []*store.Amenity) *hotelsystem.GetAmenitiesResponse {
+	return &hotelsystem.GetAmenitiesResponse{
+		Amenities: AmenitiesSerializer(amenities),
+	}
+}
+
+func ReviewResponseSerializer(review *store.Review) *hotelsystem.ReviewData {
+	reviewData := &hotelsystem.ReviewData{
+		ReviewID:    int64(review.ID),
+		HotelID:     int64(review.HotelID),
+		Overall:     int32(review.Overall),
+		Cleanliness: int32(review.Cleanliness),
+		Service:     int32(review.Service),
+		Location:    int32(review.Location),
+		Value:       int32(review.Value),
+		Comment:     review.Comment,
+		Reply:       review.Reply,
+		Hidden:      review.HiddenAt.Valid,
+		CreatedAt:   review.CreatedAt.String(),
+	}
+	if review.RepliedAt.Valid {
+		reviewData.RepliedAt = review.RepliedAt.Time.String()
+	}
+	return reviewData
+}
+
+// HotelReviewsResponseSerializer converts a page of a hotel's reviews, along with the hotel's rating
+func HotelReviewsResponseSerializer(hotel store.Hotel, reviews []*store.Review, totalRecords int64) *hotelsystem.GetHotelReviewsResponse {
+	var reviewsData []*hotelsystem.ReviewData
+	for _, review := range reviews {
+		reviewsData = append(reviewsData, ReviewResponseSerializer(review))
+	}
+	return &hotelsystem.GetHotelReviewsResponse{
+		Rating:       hotel.AverageRating(),
+		ReviewCount:  int64(hotel.ReviewCount),
+		TotalRecords: totalRecords,
+		Reviews:      reviewsData,
+	}
 }
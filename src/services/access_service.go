@@ -0,0 +1,164 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// requestRole is the role of the authenticated caller, as put in the context by the auth middleware
+func requestRole(r *http.Request) constants.UserRole {
+	role, _ := r.Context().Value("role").(constants.UserRole)
+	return role
+}
+
+// canManageHotel reports whether the caller may edit a hotel and see its bookings. Admins manage
+// every hotel, hotel staff only the hotels they are assigned to.
+func (s *Service) canManageHotel(r *http.Request, hotelId int64) (bool, error) {
+	switch requestRole(r) {
+	case constants.ROLE_ADMIN:
+		return true, nil
+	case constants.ROLE_HOTEL_STAFF:
+		return s.storageService.IsHotelStaff(hotelId, r.Context().Value("user_id").(int))
+	default:
+		return false, nil
+	}
+}
+
+// canViewHotel reports whether the caller may see a hotel. Hotels off sale are only shown to the
+// people who manage them, and deleted hotels to no one.
+func (s *Service) canViewHotel(r *http.Request, hotel *store.Hotel) (bool, error) {
+	switch hotel.Status {
+	case constants.HOTEL_ACTIVE:
+		return true, nil
+	case constants.HOTEL_DELETED:
+		return false, nil
+	default:
+		return s.canManageHotel(r, int64(hotel.ID))
+	}
+}
+
+// getVisibleBooking looks a booking up by its reference or, without one, by its id. Guests only see
+// their own bookings, hotel staff also those of their hotels and admins all of them. A booking the
+// caller may not see is reported as errBookingNotFound, like a missing one, so that it cannot be probed.
+func (s *Service) getVisibleBooking(r *http.Request, bookingId int64, bookingReference string) (*store.Booking, error) {
+	var (
+		booking *store.Booking
+		err     error
+	)
+	if bookingReference != "" {
+		booking, err = s.storageService.GetBookingByReference(strings.ToUpper(bookingReference))
+	} else {
+		booking, err = s.storageService.GetBookingById(bookingId)
+	}
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && booking == nil) {
+		return nil, errBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.UserID == r.Context().Value("user_id").(int) {
+		return booking, nil
+	}
+	canManage, err := s.canManageHotel(r, int64(booking.HotelID))
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, errBookingNotFound
+	}
+	return booking, nil
+}
+
+func (s *Service) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	var setUserRoleRequest hotelsystem.SetUserRoleRequest
+	err := json.NewDecoder(r.Body).Decode(&setUserRoleRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateSetUserRoleRequest(&setUserRoleRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The new role is picked up when the user's access token is next refreshed
+	updated, err := s.storageService.UpdateUserRole(int(setUserRoleRequest.UserID), constants.UserRole(setUserRoleRequest.Role))
+	if err != nil {
+		log.Println("Error updating user role:", err)
+		http.Error(w, "Failed to update user role", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "User role updated successfully")
+}
+
+func (s *Service) AddHotelStaff(w http.ResponseWriter, r *http.Request) {
+	var hotelStaffRequest hotelsystem.HotelStaffRequest
+	err := json.NewDecoder(r.Body).Decode(&hotelStaffRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateHotelStaffRequest(&hotelStaffRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if hotel, err := s.storageService.GetHotelById(hotelStaffRequest.HotelID); err != nil || hotel.Status == constants.HOTEL_DELETED {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	user, err := s.storageService.GetUserById(int(hotelStaffRequest.UserID))
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if user.Role != constants.ROLE_HOTEL_STAFF {
+		http.Error(w, "Only hotel staff can be assigned to a hotel", http.StatusConflict)
+		return
+	}
+
+	if err = s.storageService.AddHotelStaff(hotelStaffRequest.HotelID, user.ID); err != nil {
+		log.Println("Error adding hotel staff:", err)
+		http.Error(w, "Failed to add hotel staff", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Hotel staff added successfully")
+}
+
+func (s *Service) RemoveHotelStaff(w http.ResponseWriter, r *http.Request) {
+	var hotelStaffRequest hotelsystem.HotelStaffRequest
+	err := json.NewDecoder(r.Body).Decode(&hotelStaffRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateHotelStaffRequest(&hotelStaffRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	removed, err := s.storageService.RemoveHotelStaff(hotelStaffRequest.HotelID, int(hotelStaffRequest.UserID))
+	if err != nil {
+		log.Println("Error removing hotel staff:", err)
+		http.Error(w, "Failed to remove hotel staff", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "User is not on the hotel's staff", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "Hotel staff removed successfully")
+}
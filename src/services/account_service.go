@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/pb"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var errInvalidUserToken = errors.New("invalid or expired token")
+
+// VerifyEmail redeems the token from a verification email and marks the address it was sent to verified
+func (s *Service) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var verifyEmailRequest pb.VerifyEmailRequest
+	err := json.NewDecoder(r.Body).Decode(&verifyEmailRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateVerifyEmailRequest(&verifyEmailRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.verifyEmail(verifyEmailRequest.Token, time.Now())
+	if errors.Is(err, errInvalidUserToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error verifying email:", err)
+		http.Error(w, "Failed to verify email", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Email verified successfully")
+}
+
+// ResendVerificationEmail sends the caller a new verification email, voiding the previous link
+func (s *Service) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("user_id").(int)
+	user, err := s.storageService.GetUserById(userId)
+	if err != nil {
+		log.Println("Error getting user:", err)
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+	if user.Email == "" {
+		http.Error(w, "No email on the account", http.StatusConflict)
+		return
+	}
+	if user.EmailVerifiedAt.Valid {
+		http.Error(w, "Email already verified", http.StatusConflict)
+		return
+	}
+
+	if err = s.sendVerificationEmail(user.ID, user.Email); err != nil {
+		log.Println("Error sending verification email:", err)
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Verification email sent")
+}
+
+// ForgotPassword emails a password reset link to the account with the given email. The response is
+// the same whether or not such an account exists, so it cannot be used to find registered emails.
+func (s *Service) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var forgotPasswordRequest pb.ForgotPasswordRequest
+	err := json.NewDecoder(r.Body).Decode(&forgotPasswordRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateForgotPasswordRequest(&forgotPasswordRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.storageService.GetUserByEmail(strings.ToLower(forgotPasswordRequest.Email))
+	if err != nil {
+		log.Println("Error getting user by email:", err)
+		http.Error(w, "Failed to send password reset email", http.StatusInternalServerError)
+		return
+	}
+	if user != nil {
+		if err = s.sendPasswordResetEmail(user.ID, user.Email); err != nil {
+			log.Println("Error sending password reset email:", err)
+			http.Error(w, "Failed to send password reset email", http.StatusInternalServerError)
+			return
+		}
+	}
+	sendSuccessResponse(w, "If the email is registered, a password reset link has been sent to it")
+}
+
+// ResetPassword redeems the token from a password reset email and sets a new password. Every session
+// of the user is ended, so whoever knew the old password is logged out.
+func (s *Service) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var resetPasswordRequest pb.ResetPasswordRequest
+	err := json.NewDecoder(r.Body).Decode(&resetPasswordRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateResetPasswordRequest(&resetPasswordRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.resetPassword(resetPasswordRequest.Token, resetPasswordRequest.NewPassword, time.Now())
+	if errors.Is(err, errInvalidUserToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error resetting password:", err)
+		http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Password reset successfully")
+}
+
+func (s *Service) sendVerificationEmail(userId int, email string) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	token, err := s.issueUserTokenTx(tx, userId, constants.TOKEN_EMAIL_VERIFICATION, email, constants.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Please confirm your email address by opening this link:\n\n%s/verify-email?token=%s\n\nThe link expires in %s.",
+		s.appBaseURL, token, constants.EmailVerificationTTL)
+	return s.outbox.EnqueueTx(tx, userId, email, "Verify your email", body)
+}
+
+func (s *Service) sendPasswordResetEmail(userId int, email string) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	token, err := s.issueUserTokenTx(tx, userId, constants.TOKEN_PASSWORD_RESET, email, constants.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("To choose a new password, open this link:\n\n%s/reset-password?token=%s\n\n"+
+		"The link expires in %s. If you did not ask for a password reset, you can ignore this email.",
+		s.appBaseURL, token, constants.PasswordResetTTL)
+	return s.outbox.EnqueueTx(tx, userId, email, "Reset your password", body)
+}
+
+// issueUserTokenTx creates a token for the purpose and voids the user's earlier ones, so only the
+// latest emailed link works
+func (s *Service) issueUserTokenTx(tx *sql.Tx, userId int, purpose constants.UserTokenPurpose, email string, ttl time.Duration) (string, error) {
+	if err := s.storageService.UseUserTokensTx(tx, userId, purpose); err != nil {
+		return "", err
+	}
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err = s.storageService.CreateUserTokenTx(tx, store.NewUserToken(utils.NewUuid(), userId, purpose, tokenHash, email, ttl)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// redeemUserTokenTx uses up a token issued for the purpose. It returns errInvalidUserToken for tokens
+// that do not exist, were used already or have expired.
+func (s *Service) redeemUserTokenTx(tx *sql.Tx, purpose constants.UserTokenPurpose, token string, now time.Time) (*store.UserToken, error) {
+	userToken, err := s.storageService.GetUserTokenByHashTx(tx, purpose, utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if userToken == nil || userToken.UsedAt.Valid || !userToken.ExpiresAt.After(now) {
+		return nil, errInvalidUserToken
+	}
+	if err = s.storageService.UseUserTokensTx(tx, userToken.UserID, purpose); err != nil {
+		return nil, err
+	}
+	return userToken, nil
+}
+
+func (s *Service) verifyEmail(token string, now time.Time) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	userToken, err := s.redeemUserTokenTx(tx, constants.TOKEN_EMAIL_VERIFICATION, token, now)
+	if err != nil {
+		return err
+	}
+	verified, err := s.storageService.MarkUserEmailVerifiedTx(tx, userToken.UserID, userToken.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		// The user has changed their email since the link was sent
+		return errInvalidUserToken
+	}
+	return nil
+}
+
+func (s *Service) resetPassword(token string, newPassword string, now time.Time) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	userToken, err := s.redeemUserTokenTx(tx, constants.TOKEN_PASSWORD_RESET, token, now)
+	if err != nil {
+		return err
+	}
+	passwordHash, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	if err = s.storageService.UpdateUserPasswordTx(tx, userToken.UserID, passwordHash); err != nil {
+		return err
+	}
+	if err = s.storageService.RevokeUserRefreshTokensTx(tx, userToken.UserID); err != nil {
+		return err
+	}
+	// Receiving the reset link proves the user owns the address
+	if _, err = s.storageService.MarkUserEmailVerifiedTx(tx, userToken.UserID, userToken.Email); err != nil {
+		return err
+	}
+	body := "The password of your account was just changed. If this was not you, reset your password right away."
+	return s.outbox.EnqueueTx(tx, userToken.UserID, userToken.Email, "Your password was changed", body)
+}
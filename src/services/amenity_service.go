@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+)
+
+// unknownAmenityError rejects an amenity code that is not in the catalog
+type unknownAmenityError struct {
+	code string
+}
+
+func (e *unknownAmenityError) Error() string {
+	return fmt.Sprintf("unknown amenity: %s", e.code)
+}
+
+// AddAmenity adds an amenity to the catalog
+func (s *Service) AddAmenity(w http.ResponseWriter, r *http.Request) {
+	var amenityRequest hotelsystem.AmenityRequest
+	err := json.NewDecoder(r.Body).Decode(&amenityRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateAmenityRequest(&amenityRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	created, err := s.storageService.CreateAmenity(&store.Amenity{
+		Code:     amenityRequest.Code,
+		Name:     amenityRequest.Name,
+		Category: amenityRequest.Category,
+		Icon:     amenityRequest.Icon,
+	})
+	if err != nil {
+		log.Println("Error adding amenity:", err)
+		http.Error(w, "Could not add amenity", http.StatusInternalServerError)
+		return
+	}
+	if !created {
+		http.Error(w, "An amenity with this code already exists", http.StatusConflict)
+		return
+	}
+	sendSuccessResponse(w, "Amenity added successfully")
+}
+
+// UpdateAmenity changes the name, category and icon of an amenity; its code stays the same
+func (s *Service) UpdateAmenity(w http.ResponseWriter, r *http.Request) {
+	var amenityRequest hotelsystem.AmenityRequest
+	err := json.NewDecoder(r.Body).Decode(&amenityRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateAmenityRequest(&amenityRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	updated, err := s.storageService.UpdateAmenity(&store.Amenity{
+		Code:     amenityRequest.Code,
+		Name:     amenityRequest.Name,
+		Category: amenityRequest.Category,
+		Icon:     amenityRequest.Icon,
+	})
+	if err != nil {
+		log.Println("Error updating amenity:", err)
+		http.Error(w, "Could not update amenity", http.StatusInternalServerError)
+		return
+	}
+	if !updated {
+		http.Error(w, "Amenity not found", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "Amenity updated successfully")
+}
+
+// DeleteAmenity removes an amenity from the catalog and from every hotel and room type offering it
+func (s *Service) DeleteAmenity(w http.ResponseWriter, r *http.Request) {
+	var deleteAmenityRequest hotelsystem.DeleteAmenityRequest
+	err := json.NewDecoder(r.Body).Decode(&deleteAmenityRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateDeleteAmenityRequest(&deleteAmenityRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	deleted, err := s.storageService.DeleteAmenity(deleteAmenityRequest.Code)
+	if err != nil {
+		log.Println("Error deleting amenity:", err)
+		http.Error(w, "Could not delete amenity", http.StatusInternalServerError)
+		return
+	}
+	if !deleted {
+		http.Error(w, "Amenity not found", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "Amenity deleted successfully")
+}
+
+// GetAmenities returns the amenities catalog, for clients to show search filters with
+func (s *Service) GetAmenities(w http.ResponseWriter, r *http.Request) {
+	amenities, err := s.storageService.GetAmenities()
+	if err != nil {
+		log.Println("Error getting amenities:", err)
+		http.Error(w, "Could not get amenities", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.AmenitiesResponseSerializer(amenities))
+}
+
+// SetHotelAmenities replaces the amenities of a hotel and of the room types listed in the request
+func (s *Service) SetHotelAmenities(w http.ResponseWriter, r *http.Request) {
+	var setAmenitiesRequest hotelsystem.SetHotelAmenitiesRequest
+	err := json.NewDecoder(r.Body).Decode(&setAmenitiesRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateSetHotelAmenitiesRequest(&setAmenitiesRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, setAmenitiesRequest.HotelID) {
+		return
+	}
+
+	codes := setAmenitiesRequest.Amenities
+	for _, rt := range setAmenitiesRequest.RoomTypes {
+		codes = append(codes, rt.Amenities...)
+	}
+	amenityIds, err := s.amenityIdsByCode(codes)
+	var unknownErr *unknownAmenityError
+	if errors.As(err, &unknownErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error looking up amenities:", err)
+		http.Error(w, "Could not set amenities", http.StatusInternalServerError)
+		return
+	}
+
+	err = s.setHotelAmenities(&setAmenitiesRequest, amenityIds)
+	switch {
+	case errors.Is(err, errHotelNotFound):
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errRoomTypeNotFound):
+		http.Error(w, "Room type not found", http.StatusNotFound)
+		return
+	case err != nil:
+		log.Println("Error setting amenities:", err)
+		http.Error(w, "Could not set amenities", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Amenities updated successfully")
+}
+
+func (s *Service) setHotelAmenities(req *hotelsystem.SetHotelAmenitiesRequest, amenityIds map[string]int64) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	if _, err = s.getHotelForUpdateTx(tx, req.HotelID); err != nil {
+		return err
+	}
+	if err = s.storageService.SetHotelAmenitiesTx(tx, req.HotelID, amenityIdsOf(req.Amenities, amenityIds)); err != nil {
+		return err
+	}
+	for _, rt := range req.RoomTypes {
+		roomType, err := s.storageService.GetRoomTypeByIdTx(tx, req.HotelID, rt.RoomTypeID)
+		if err != nil {
+			return err
+		}
+		if roomType == nil {
+			return errRoomTypeNotFound
+		}
+		if err = s.storageService.SetRoomTypeAmenitiesTx(tx, rt.RoomTypeID, amenityIdsOf(rt.Amenities, amenityIds)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// amenityIdsByCode looks up the ids of the given amenity codes, failing with an unknownAmenityError for
+// a code that is not in the catalog
+func (s *Service) amenityIdsByCode(codes []string) (map[string]int64, error) {
+	amenityIds := make(map[string]int64, len(codes))
+	if len(codes) == 0 {
+		return amenityIds, nil
+	}
+	amenities, err := s.storageService.GetAmenitiesByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+	for _, amenity := range amenities {
+		amenityIds[amenity.Code] = int64(amenity.ID)
+	}
+	for _, code := range codes {
+		if _, ok := amenityIds[code]; !ok {
+			return nil, &unknownAmenityError{code: code}
+		}
+	}
+	return amenityIds, nil
+}
+
+// amenityIdsOf maps amenity codes to the ids looked up by amenityIdsByCode
+func amenityIdsOf(codes []string, amenityIds map[string]int64) []int64 {
+	ids := make([]int64, len(codes))
+	for i, code := range codes {
+		ids[i] = amenityIds[code]
+	}
+	return ids
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAmenityCatalog = []*store.Amenity{
+	{ID: 1, Code: "wifi", Name: "Wi-Fi"},
+	{ID: 2, Code: "pool", Name: "Swimming pool"},
+}
+
+func TestAmenityIdsByCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		codes        []string
+		found        []*store.Amenity
+		expectedIds  map[string]int64
+		expectedCode string // of the unknown amenity reported
+	}{
+		{
+			name:        "no filter",
+			codes:       nil,
+			expectedIds: map[string]int64{},
+		},
+		{
+			name:        "all in the catalog",
+			codes:       []string{"wifi", "pool"},
+			found:       testAmenityCatalog,
+			expectedIds: map[string]int64{"wifi": 1, "pool": 2},
+		},
+		{
+			name:         "one not in the catalog",
+			codes:        []string{"wifi", "sauna"},
+			found:        testAmenityCatalog[:1],
+			expectedCode: "sauna",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			if len(tt.codes) > 0 {
+				mockStore.EXPECT().GetAmenitiesByCodes(tt.codes).Return(tt.found, nil)
+			}
+
+			ids, err := service.amenityIdsByCode(tt.codes)
+			if tt.expectedCode != "" {
+				var unknownErr *unknownAmenityError
+				assert.ErrorAs(t, err, &unknownErr)
+				assert.Equal(t, tt.expectedCode, unknownErr.code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedIds, ids)
+		})
+	}
+}
+
+func TestGetHotelsListAmenityFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		amenities      []string
+		setupMocks     func(m *mocks.MockStorageService)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "known amenities are passed on to the listing",
+			amenities: []string{"wifi", "pool"},
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetAmenitiesByCodes([]string{"wifi", "pool"}).Return(testAmenityCatalog, nil)
+				m.EXPECT().GetHotels(gomock.Any()).DoAndReturn(func(req *hotelsystem.GetHotelsListRequest) ([]store.Hotel, error) {
+					assert.Equal(t, []string{"wifi", "pool"}, req.Amenities)
+					return []store.Hotel{{ID: 4, Name: "Sea View"}}, nil
+				})
+				m.EXPECT().CountHotels(gomock.Any()).Return(int64(1), nil)
+				m.EXPECT().GetHotelAmenities([]int64{4}).Return(map[int][]*store.Amenity{4: testAmenityCatalog}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Sea View",
+		},
+		{
+			name:      "an unknown amenity is refused rather than matching nothing",
+			amenities: []string{"wifi", "sauna"},
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetAmenitiesByCodes([]string{"wifi", "sauna"}).Return(testAmenityCatalog[:1], nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "unknown amenity: sauna",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			tt.setupMocks(mockStore)
+
+			body, _ := json.Marshal(hotelsystem.GetHotelsListRequest{Amenities: tt.amenities})
+			rec := httptest.NewRecorder()
+			service.GetHotelsList(rec, httptest.NewRequest(http.MethodPost, "/getHotels", bytes.NewReader(body)))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
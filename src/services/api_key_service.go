@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/pb"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidAPIKey is returned by AuthenticateAPIKey for keys that do not exist, were revoked or
+// belong to an account that is no longer a partner
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// errNotPartner is returned by apiKeyOwner when the account whose keys are managed is not a partner
+var errNotPartner = errors.New("API keys can only be issued to partner accounts")
+
+// AuthenticateAPIKey looks up the key sent by a partner and records its use
+func (s *Service) AuthenticateAPIKey(apiKey string) (*store.APIKey, error) {
+	key, err := s.storageService.GetAPIKeyByHash(utils.HashToken(apiKey))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.RevokedAt.Valid {
+		return nil, ErrInvalidAPIKey
+	}
+	// Demoting a partner shuts off their keys without having to revoke each one
+	owner, err := s.storageService.GetUserById(key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner.Role != constants.ROLE_PARTNER {
+		return nil, ErrInvalidAPIKey
+	}
+	if err = s.storageService.TouchAPIKey(key.ID, time.Now()); err != nil {
+		log.Printf("Error recording use of API key %s: %v", key.ID, err)
+	}
+	return key, nil
+}
+
+// apiKeyOwner is the partner whose API keys a request manages. Partners manage their own keys; admins
+// name the partner with partnerUserId.
+func (s *Service) apiKeyOwner(r *http.Request, partnerUserId int64) (int, error) {
+	userId := r.Context().Value("user_id").(int)
+	if requestRole(r) != constants.ROLE_ADMIN {
+		if partnerUserId != 0 && partnerUserId != int64(userId) {
+			return 0, errors.New("partners can only manage their own API keys")
+		}
+		return userId, nil
+	}
+
+	if partnerUserId == 0 {
+		return 0, errors.New("partner_user_id is required")
+	}
+	partner, err := s.storageService.GetUserById(int(partnerUserId))
+	if err != nil || partner.Role != constants.ROLE_PARTNER {
+		return 0, errNotPartner
+	}
+	return partner.ID, nil
+}
+
+// CreateAPIKey issues a new API key for a partner. The key itself is only in this response; only its
+// hash is stored.
+func (s *Service) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var createApiKeyRequest pb.CreateApiKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&createApiKeyRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateCreateApiKeyRequest(&createApiKeyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	partnerId, err := s.apiKeyOwner(r, createApiKeyRequest.PartnerUserId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	token, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		log.Println("Error generating API key:", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+	apiKey := constants.APIKeyPrefix + token
+	scopes := make([]constants.APIKeyScope, len(createApiKeyRequest.Scopes))
+	for i, scope := range createApiKeyRequest.Scopes {
+		scopes[i] = constants.APIKeyScope(scope)
+	}
+	key := store.NewAPIKey(utils.NewUuid(), partnerId, createApiKeyRequest.Name, apiKey[:constants.APIKeyDisplayLength], utils.HashToken(apiKey), scopes)
+	if err = s.storageService.CreateAPIKey(key); err != nil {
+		log.Println("Error creating API key:", err)
+		http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, &pb.CreateApiKeyResponse{
+		ApiKey:  apiKey,
+		Key:     serializers.ApiKeySerializer(key),
+		Message: "API key created; store it now, it cannot be shown again",
+	})
+}
+
+func (s *Service) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	var getApiKeysRequest pb.GetApiKeysRequest
+	err := json.NewDecoder(r.Body).Decode(&getApiKeysRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetApiKeysRequest(&getApiKeysRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	partnerId, err := s.apiKeyOwner(r, getApiKeysRequest.PartnerUserId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	keys, err := s.storageService.GetAPIKeysByUserId(partnerId)
+	if err != nil {
+		log.Println("Error getting API keys:", err)
+		http.Error(w, "Failed to get API keys", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.ApiKeysResponseSerializer(keys))
+}
+
+// RevokeAPIKey shuts a key off for good; requests made with it are refused from then on
+func (s *Service) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	var revokeApiKeyRequest pb.RevokeApiKeyRequest
+	err := json.NewDecoder(r.Body).Decode(&revokeApiKeyRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateRevokeApiKeyRequest(&revokeApiKeyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	partnerId, err := s.apiKeyOwner(r, revokeApiKeyRequest.PartnerUserId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := s.storageService.RevokeAPIKey(revokeApiKeyRequest.KeyId, partnerId)
+	if err != nil {
+		log.Println("Error revoking API key:", err)
+		http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Active API key not found", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "API key revoked successfully")
+}
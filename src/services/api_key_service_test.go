@@ -0,0 +1,88 @@
+package services
+
+import (
+	"database/sql"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	"hotel-system/src/utils"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	const apiKey = "hsk_test_key"
+	activeKey := func() *store.APIKey {
+		return store.NewAPIKey("k1", 7, "booking engine", "hsk_test", utils.HashToken(apiKey), []constants.APIKeyScope{constants.SCOPE_SEARCH})
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func(m *mocks.MockStorageService)
+		expectedErr error
+	}{
+		{
+			name: "active key of a partner",
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetAPIKeyByHash(utils.HashToken(apiKey)).Return(activeKey(), nil)
+				m.EXPECT().GetUserById(7).Return(store.User{ID: 7, Role: constants.ROLE_PARTNER}, nil)
+				m.EXPECT().TouchAPIKey("k1", gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name: "unknown key",
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetAPIKeyByHash(utils.HashToken(apiKey)).Return(nil, nil)
+			},
+			expectedErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "revoked key",
+			setupMocks: func(m *mocks.MockStorageService) {
+				key := activeKey()
+				key.RevokedAt = sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+				m.EXPECT().GetAPIKeyByHash(utils.HashToken(apiKey)).Return(key, nil)
+			},
+			expectedErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "key of an account that is no longer a partner",
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetAPIKeyByHash(utils.HashToken(apiKey)).Return(activeKey(), nil)
+				m.EXPECT().GetUserById(7).Return(store.User{ID: 7, Role: constants.ROLE_GUEST}, nil)
+			},
+			expectedErr: ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			tt.setupMocks(mockStore)
+
+			key, err := service.AuthenticateAPIKey(apiKey)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, key)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "k1", key.ID)
+		})
+	}
+}
+
+func TestAPIKeyHasScope(t *testing.T) {
+	key := store.NewAPIKey("k1", 7, "booking engine", "hsk_test", "hash", []constants.APIKeyScope{constants.SCOPE_SEARCH, constants.SCOPE_BOOK})
+
+	assert.True(t, key.HasScope(constants.SCOPE_SEARCH))
+	assert.True(t, key.HasScope(constants.SCOPE_BOOK))
+	assert.False(t, key.HasScope(constants.SCOPE_READ_BOOKINGS))
+}
@@ -1,12 +1,17 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"hotel-system/src/constants"
 	"hotel-system/src/pb"
 	"hotel-system/src/store"
 	"hotel-system/src/utils"
 	"hotel-system/src/validators"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 )
 
 func (s *Service) Login(w http.ResponseWriter, r *http.Request) {
@@ -21,25 +26,67 @@ func (s *Service) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now()
+	ip := clientIP(r)
+	wait, err := s.loginRetryAfter(loginRequest.Username, ip, now)
+	if err != nil {
+		log.Println("Error checking login throttle:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		sendTooManyLoginAttempts(w, wait)
+		return
+	}
+
 	user, err := s.storageService.GetUserByUsername(loginRequest.Username)
 	if err != nil {
-		http.Error(w, "Invalid username", http.StatusUnauthorized)
+		log.Println("Error getting user:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	// Unknown usernames and wrong passwords get the same answer, in the same time
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = user.Password
+	}
+	if !utils.CheckPasswordHash(loginRequest.Password, passwordHash) || user == nil {
+		s.recordLoginFailure(loginRequest.Username, ip, now)
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
 		return
 	}
 
-	if !utils.CheckPasswordHash(loginRequest.Password, user.Password) {
-		http.Error(w, "Invalid password", http.StatusUnauthorized)
+	totp, err := s.storageService.GetUserTOTP(user.ID)
+	if err != nil {
+		log.Println("Error getting user TOTP:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if totp != nil && totp.EnabledAt.Valid {
+		// Failed logins are only forgotten once the second factor checks out too
+		preAuthToken, err := utils.GeneratePreAuthToken(user.ID)
+		if err != nil {
+			log.Println("Error generating pre-auth token:", err)
+			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+		sendJsonResponse(w, &pb.LoginOrRegisterResponse{
+			Message:      "Enter the code from your authenticator app",
+			MfaRequired:  true,
+			PreAuthToken: preAuthToken,
+		})
 		return
 	}
-	token, err := utils.GenerateJWT(user.ID)
+	if _, err = s.storageService.ClearLoginThrottle(usernameLoginKey(loginRequest.Username)); err != nil {
+		log.Println("Error clearing login throttle:", err)
+	}
+
+	response, err := s.startSession(user.ID, user.Role, false, "Logged in successfully")
 	if err != nil {
+		log.Println("Error starting session:", err)
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
 	}
-	response := &pb.LoginOrRegisterResponse{
-		Token:   token,
-		Message: "Registered successfully",
-	}
 	sendJsonResponse(w, response)
 }
 
@@ -63,31 +110,182 @@ func (s *Service) Register(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Username already taken", http.StatusConflict)
 		return
 	}
+	email := strings.ToLower(registerRequest.Email)
+	existingUser, err = s.storageService.GetUserByEmail(email)
+	if err != nil {
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if existingUser != nil {
+		http.Error(w, "Email already registered", http.StatusConflict)
+		return
+	}
 
 	hashedPassword, err := utils.HashPassword(registerRequest.Password)
 	if err != nil {
 		http.Error(w, "Error hashing password", http.StatusInternalServerError)
 		return
 	}
+	// Everyone signs up as a guest; staff and admins are promoted by an admin
 	newUser := &store.User{
 		Username: registerRequest.Username,
 		Password: hashedPassword,
+		Role:     constants.ROLE_GUEST,
+		Email:    email,
 	}
 	userId, err := s.storageService.AddUser(newUser)
 	if err != nil {
 		http.Error(w, "Could not register user", http.StatusInternalServerError)
 		return
 	}
+	// The account works before the email is verified; a failed send can be retried from /resendVerificationEmail
+	if err = s.sendVerificationEmail(userId, email); err != nil {
+		log.Println("Error sending verification email:", err)
+	}
 
-	//	Generate token with the user id
-	token, err := utils.GenerateJWT(userId)
+	//	Generate tokens with the user id
+	response, err := s.startSession(userId, newUser.Role, false, "Registered successfully")
 	if err != nil {
+		log.Println("Error starting session:", err)
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		return
 	}
-	response := &pb.LoginOrRegisterResponse{
-		Token:   token,
-		Message: "Registered successfully",
+	sendJsonResponse(w, response)
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+func (s *Service) Refresh(w http.ResponseWriter, r *http.Request) {
+	var refreshTokenRequest pb.RefreshTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&refreshTokenRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateRefreshTokenRequest(&refreshTokenRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.rotateRefreshToken(refreshTokenRequest.RefreshToken, time.Now())
+	if err != nil {
+		log.Println("Error refreshing token:", err)
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+	if response == nil {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
 	}
 	sendJsonResponse(w, response)
 }
+
+// Logout ends the session of a refresh token. Access tokens already issued for it stay valid until
+// they expire, which AccessTokenTTL keeps short.
+func (s *Service) Logout(w http.ResponseWriter, r *http.Request) {
+	var logoutRequest pb.LogoutRequest
+	err := json.NewDecoder(r.Body).Decode(&logoutRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateLogoutRequest(&logoutRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.endSession(logoutRequest.RefreshToken); err != nil {
+		log.Println("Error logging out:", err)
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Logged out successfully")
+}
+
+// startSession logs a user in on a new session, i.e. a new refresh token family. mfa records whether
+// the user proved a second factor, which the session keeps across refreshes.
+func (s *Service) startSession(userId int, role constants.UserRole, mfa bool, message string) (*pb.LoginOrRegisterResponse, error) {
+	response, refreshToken, err := newTokenPair(userId, role, utils.NewUuid(), mfa)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storageService.CreateRefreshToken(refreshToken); err != nil {
+		return nil, err
+	}
+	response.Message = message
+	return response, nil
+}
+
+// rotateRefreshToken replaces a refresh token with a new token pair of the same session. It returns nil
+// if the token cannot be used. A token that was already exchanged has leaked, so presenting it again
+// revokes the whole session.
+func (s *Service) rotateRefreshToken(refreshToken string, now time.Time) (response *pb.LoginOrRegisterResponse, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	token, err := s.storageService.GetRefreshTokenByHashTx(tx, utils.HashToken(refreshToken))
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if token.RevokedAt.Valid {
+		log.Printf("Refresh token reused for user %d, revoking session %s", token.UserID, token.FamilyID)
+		return nil, s.storageService.RevokeRefreshTokenFamilyTx(tx, token.FamilyID)
+	}
+	if !token.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	if err = s.storageService.RevokeRefreshTokenTx(tx, token.ID); err != nil {
+		return nil, err
+	}
+	// The role is read again so that role changes reach the session on its next refresh
+	user, err := s.storageService.GetUserById(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	response, newToken, err := newTokenPair(token.UserID, user.Role, token.FamilyID, token.MFA)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.storageService.CreateRefreshTokenTx(tx, newToken); err != nil {
+		return nil, err
+	}
+	response.Message = "Token refreshed successfully"
+	return response, nil
+}
+
+// endSession revokes every refresh token of the session a refresh token belongs to
+func (s *Service) endSession(refreshToken string) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	token, err := s.storageService.GetRefreshTokenByHashTx(tx, utils.HashToken(refreshToken))
+	if err != nil || token == nil {
+		return err
+	}
+	return s.storageService.RevokeRefreshTokenFamilyTx(tx, token.FamilyID)
+}
+
+// newTokenPair creates an access token and a refresh token for a session; the refresh token still has
+// to be stored
+func newTokenPair(userId int, role constants.UserRole, sessionId string, mfa bool) (*pb.LoginOrRegisterResponse, *store.RefreshToken, error) {
+	accessToken, err := utils.GenerateJWT(userId, role, sessionId, mfa)
+	if err != nil {
+		return nil, nil, err
+	}
+	refreshToken, refreshTokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	response := &pb.LoginOrRegisterResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(constants.AccessTokenTTL.Seconds()),
+	}
+	return response, store.NewRefreshToken(utils.NewUuid(), userId, sessionId, refreshTokenHash, mfa, constants.RefreshTokenTTL), nil
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+var (
+	errBookingNotModifiable = errors.New("only confirmed bookings can be modified, and only before check-in")
+	errModificationPending  = errors.New("an earlier modification of this booking is waiting for payment")
+	errNothingToModify      = errors.New("the modification does not change the booking")
+	errHotelNotBookable     = errors.New("hotel is not taking bookings")
+	errRoomsUnavailable     = errors.New("not enough rooms available for the new stay")
+)
+
+// stay is a run of nights, from checkIn up to but not including checkOut, with the rooms held on each
+type stay struct {
+	checkIn  time.Time
+	checkOut time.Time
+	rooms    int
+}
+
+func (st stay) nights() int {
+	return int(st.checkOut.Sub(st.checkIn).Hours() / 24)
+}
+
+func (st stay) roomsOn(night time.Time) int {
+	if night.Before(st.checkIn) || !night.Before(st.checkOut) {
+		return 0
+	}
+	return st.rooms
+}
+
+// equal reports whether both stays hold the same rooms on the same nights. Dates scanned from the
+// database and dates parsed from a request can differ in location, so they are compared with Equal.
+func (st stay) equal(other stay) bool {
+	return st.checkIn.Equal(other.checkIn) && st.checkOut.Equal(other.checkOut) && st.rooms == other.rooms
+}
+
+// inventoryDelta returns the runs of nights on which the stay to needs more rooms than the stay from,
+// each with how many more. Consecutive nights needing the same number of extra rooms make one run.
+func inventoryDelta(from, to stay) []stay {
+	start, end := from.checkIn, from.checkOut
+	if to.checkIn.Before(start) {
+		start = to.checkIn
+	}
+	if to.checkOut.After(end) {
+		end = to.checkOut
+	}
+	var runs []stay
+	for night := start; night.Before(end); night = night.AddDate(0, 0, 1) {
+		extra := to.roomsOn(night) - from.roomsOn(night)
+		if extra <= 0 {
+			continue
+		}
+		if n := len(runs); n > 0 && runs[n-1].checkOut.Equal(night) && runs[n-1].rooms == extra {
+			runs[n-1].checkOut = night.AddDate(0, 0, 1)
+			continue
+		}
+		runs = append(runs, stay{checkIn: night, checkOut: night.AddDate(0, 0, 1), rooms: extra})
+	}
+	return runs
+}
+
+// modifiedStay applies the request to the booking's stay; fields left empty keep their current value.
+// The request has been validated.
+func modifiedStay(booking *store.Booking, req *hotelsystem.ModifyBookingRequest) stay {
+	checkIn := booking.CheckInDate
+	if req.CheckInDate != "" {
+		checkIn, _ = time.Parse(constants.DateFormat, req.CheckInDate)
+	}
+	nights := booking.NumberOfDays
+	if req.NumDays > 0 {
+		nights = int(req.NumDays)
+	}
+	rooms := booking.NumberOfRooms
+	if req.NumRooms > 0 {
+		rooms = int(req.NumRooms)
+	}
+	return stay{checkIn: checkIn, checkOut: checkIn.AddDate(0, 0, nights), rooms: rooms}
+}
+
+func bookingStay(booking *store.Booking) stay {
+	return stay{checkIn: booking.CheckInDate, checkOut: booking.CheckOutDate, rooms: booking.NumberOfRooms}
+}
+
+func modificationStays(m *store.BookingModification) (from stay, to stay) {
+	from = stay{checkIn: m.OldCheckInDate, checkOut: m.OldCheckOutDate, rooms: m.OldNumberOfRooms}
+	to = stay{checkIn: m.NewCheckInDate, checkOut: m.NewCheckOutDate, rooms: m.NewNumberOfRooms}
+	return from, to
+}
+
+func roundToCents(amount float32) float32 {
+	return float32(math.Round(float64(amount)*100) / 100)
+}
+
+// ModifyBooking moves a confirmed booking of the caller to other dates or another number of rooms,
+// keeping its booking reference. The booking is repriced at the room type's current rate. A stay that
+// costs more holds its extra rooms and is applied once the difference is paid through a new checkout;
+// one that costs the same or less is applied right away, refunding the excess under the hotel's
+// cancellation policy.
+func (s *Service) ModifyBooking(w http.ResponseWriter, r *http.Request) {
+	var modifyBookingRequest hotelsystem.ModifyBookingRequest
+	err := json.NewDecoder(r.Body).Decode(&modifyBookingRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateModifyBookingRequest(&modifyBookingRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := s.getVisibleBooking(r, modifyBookingRequest.BookingID, modifyBookingRequest.BookingReference)
+	if errors.Is(err, errBookingNotFound) {
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error getting booking:", err)
+		http.Error(w, "Failed to modify booking", http.StatusInternalServerError)
+		return
+	}
+	// Staff can see the bookings of their hotels, but only the guest who made a booking may change it
+	userId := r.Context().Value("user_id").(int)
+	if booking.UserID != userId {
+		http.Error(w, "Only the guest who made the booking can modify it", http.StatusForbidden)
+		return
+	}
+
+	resp, refunds, err := s.modifyBooking(int64(booking.BookingID), userId, &modifyBookingRequest, time.Now())
+	switch {
+	case errors.Is(err, errBookingNotFound), errors.Is(err, errHotelNotFound), errors.Is(err, errRoomTypeNotFound):
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errNothingToModify):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, errBookingNotModifiable), errors.Is(err, errModificationPending),
+		errors.Is(err, errHotelNotBookable), errors.Is(err, errRoomsUnavailable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Println("Error modifying booking:", err)
+		http.Error(w, "Failed to modify booking", http.StatusInternalServerError)
+		return
+	}
+	// The excess may be spread over several payments; the status reported is that of the first refund
+	for i, refund := range refunds {
+		attempted := s.attemptRefund(refund)
+		if i == 0 {
+			resp.RefundStatus = string(attempted.Status)
+		}
+	}
+	sendJsonResponse(w, resp)
+}
+
+// modifyBooking changes the stay of a booking of the user in one transaction. The hotel is locked the
+// same way BookHotel locks it, so the rooms checked for the new stay cannot be sold meanwhile. Refunds
+// of the excess are recorded as pending, for the caller to send once the transaction is committed.
+func (s *Service) modifyBooking(bookingId int64, userId int, req *hotelsystem.ModifyBookingRequest, now time.Time) (resp *hotelsystem.ModifyBookingResponse, refunds []*store.Refund, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	booking, err := s.storageService.GetBookingByIdTx(tx, bookingId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errBookingNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if booking.UserID != userId {
+		return nil, nil, errBookingNotFound
+	}
+	checkInTime := booking.CheckInDate.Add(constants.CheckInHour * time.Hour)
+	if booking.Status != store.BOOKING_CONFIRMED || !now.Before(checkInTime) {
+		return nil, nil, errBookingNotModifiable
+	}
+	pending, err := s.storageService.GetPendingBookingModificationTx(tx, bookingId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending != nil {
+		return nil, nil, errModificationPending
+	}
+	from, to := bookingStay(&booking), modifiedStay(&booking, req)
+	if to.equal(from) {
+		return nil, nil, errNothingToModify
+	}
+
+	hotel, err := s.getHotelForUpdateTx(tx, int64(booking.HotelID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if hotel.Status != constants.HOTEL_ACTIVE {
+		return nil, nil, errHotelNotBookable
+	}
+	roomType, err := s.storageService.GetRoomTypeByIdTx(tx, int64(booking.HotelID), int64(booking.RoomTypeID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if roomType == nil {
+		return nil, nil, errRoomTypeNotFound
+	}
+	if err = s.reserveExtraRoomsTx(tx, roomType, from, to); err != nil {
+		return nil, nil, err
+	}
+
+	modification := &store.BookingModification{
+		BookingID:        booking.BookingID,
+		OldCheckInDate:   from.checkIn,
+		OldCheckOutDate:  from.checkOut,
+		OldNumberOfRooms: from.rooms,
+		OldTotalCost:     booking.TotalCost,
+		NewCheckInDate:   to.checkIn,
+		NewCheckOutDate:  to.checkOut,
+		NewNumberOfRooms: to.rooms,
+		NewTotalCost:     roundToCents(roomType.CostPerNight * float32(to.rooms) * float32(to.nights())),
+	}
+	resp = &hotelsystem.ModifyBookingResponse{
+		BookingID:        bookingId,
+		BookingReference: booking.Reference,
+		CheckInDate:      to.checkIn.Format(constants.DateFormat),
+		CheckOutDate:     to.checkOut.Format(constants.DateFormat),
+		NumRooms:         int32(to.rooms),
+		NumDays:          int32(to.nights()),
+		OldTotalCost:     modification.OldTotalCost,
+		NewTotalCost:     modification.NewTotalCost,
+	}
+
+	difference := roundToCents(modification.NewTotalCost - modification.OldTotalCost)
+	if difference > 0 {
+		// The booking keeps its old stay, and the extra rooms stay held, until the difference is paid
+		paymentId := utils.NewUuid()
+		modification.Status = store.MODIFICATION_PENDING
+		modification.PaymentID = sql.NullString{String: paymentId, Valid: true}
+		if err = s.storageService.CreateBookingModificationTx(tx, modification); err != nil {
+			return nil, nil, err
+		}
+		checkoutRequest := serializers.CheckoutRequestSerializer(bookingId, paymentId, *hotel, roomType, difference)
+		checkoutSession, err := s.paymentGateway.CreateCheckout(checkoutRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = s.storageService.CreatePaymentTx(tx, serializers.CreatePaymentSerializer(paymentId, bookingId, checkoutSession.ID, difference))
+		if err != nil {
+			return nil, nil, err
+		}
+		resp.Status = string(store.MODIFICATION_PENDING)
+		resp.AmountDue = difference
+		resp.CheckoutURL = checkoutSession.URL
+		resp.Message = "Pay the difference to confirm the new stay"
+		return resp, nil, nil
+	}
+
+	if err = s.applyModificationTx(tx, &booking, from, to, modification.NewTotalCost); err != nil {
+		return nil, nil, err
+	}
+	modification.Status = store.MODIFICATION_APPLIED
+	modification.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+	if err = s.storageService.CreateBookingModificationTx(tx, modification); err != nil {
+		return nil, nil, err
+	}
+	resp.Status = string(store.MODIFICATION_APPLIED)
+	resp.Message = "Booking modified successfully"
+
+	if excess := -difference; excess > 0 {
+		// The nights or rooms given up are treated as cancelled
+		policy, err := s.getCancellationPolicy(int64(booking.HotelID))
+		if err != nil {
+			return nil, nil, err
+		}
+		refundAmount, penalty := calculateRefund(policy, excess, booking.CheckInDate, now)
+		resp.PenaltyAmount = penalty
+		refunds, err = s.refundAcrossPaymentsTx(tx, bookingId, refundAmount, "booking modified by guest")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, refund := range refunds {
+			resp.RefundAmount += refund.Amount
+		}
+	}
+	return resp, refunds, nil
+}
+
+// reserveExtraRoomsTx reserves the rooms the stay wanted needs beyond the stay held, failing with
+// errRoomsUnavailable if any night does not have them free. The hotel must be locked.
+func (s *Service) reserveExtraRoomsTx(tx *sql.Tx, roomType *store.RoomType, held, wanted stay) error {
+	for _, run := range inventoryDelta(held, wanted) {
+		maxRoomsSold, err := s.storageService.GetMaxRoomsSoldTx(tx, int64(roomType.ID), run.checkIn, run.checkOut)
+		if err != nil {
+			return err
+		}
+		if roomType.TotalRooms-maxRoomsSold < run.rooms {
+			return errRoomsUnavailable
+		}
+		err = s.storageService.ReserveHotelInventoryTx(tx, int64(roomType.HotelID), int64(roomType.ID), run.checkIn, run.checkOut, run.rooms)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseExtraRoomsTx returns the rooms the stay held has beyond the stay kept to the inventory calendar.
+// The hotel must be locked.
+func (s *Service) releaseExtraRoomsTx(tx *sql.Tx, roomTypeId int64, held, kept stay) error {
+	for _, run := range inventoryDelta(kept, held) {
+		if err := s.storageService.ReleaseHotelInventoryTx(tx, roomTypeId, run.checkIn, run.checkOut, run.rooms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyModificationTx moves a booking to its new stay, once the rooms of both stays are held, and
+// gives back the rooms of the old stay the new one does not need
+func (s *Service) applyModificationTx(tx *sql.Tx, booking *store.Booking, from, to stay, totalCost float32) error {
+	if err := s.releaseExtraRoomsTx(tx, int64(booking.RoomTypeID), from, to); err != nil {
+		return err
+	}
+	return s.storageService.UpdateBookingStayTx(tx, int64(booking.BookingID), to.checkIn, to.checkOut, to.rooms, totalCost)
+}
+
+// failModificationTx gives up a pending modification, giving back the extra rooms it held, and fails
+// its top-up payment. The booking must be locked.
+func (s *Service) failModificationTx(tx *sql.Tx, booking *store.Booking, m *store.BookingModification) error {
+	if _, err := s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID)); err != nil {
+		return err
+	}
+	from, to := modificationStays(m)
+	if err := s.releaseExtraRoomsTx(tx, int64(booking.RoomTypeID), to, from); err != nil {
+		return err
+	}
+	if err := s.storageService.ResolveBookingModificationTx(tx, m.ID, store.MODIFICATION_FAILED); err != nil {
+		return err
+	}
+	return s.storageService.UpdatePaymentStatusTx(tx, m.PaymentID.String, constants.PAYMENT_FAILED)
+}
+
+// refundAcrossPaymentsTx refunds an amount of a booking from its successful payments, newest first, as
+// far as they have not been refunded already
+func (s *Service) refundAcrossPaymentsTx(tx *sql.Tx, bookingId int64, amount float32, reason string) ([]*store.Refund, error) {
+	bookingPayments, err := s.storageService.GetPaymentsByBookingIdTx(tx, bookingId)
+	if err != nil {
+		return nil, err
+	}
+	var refunds []*store.Refund
+	for i := len(bookingPayments) - 1; i >= 0 && amount > 0; i-- {
+		payment := bookingPayments[i]
+		if payment.Status != constants.PAYMENT_SUCCESS {
+			continue
+		}
+		refunded, err := s.storageService.SumRefundsByPaymentIdTx(tx, payment.ID, refundableStatuses)
+		if err != nil {
+			return nil, err
+		}
+		share := min(amount, roundToCents(payment.Amount-refunded))
+		if share <= 0 {
+			continue
+		}
+		refund, err := s.createRefundTx(tx, payment, share, reason)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+		amount = roundToCents(amount - share)
+	}
+	return refunds, nil
+}
+
+// applyModificationCheckoutTx settles the modification a top-up checkout was paid for: a completed
+// checkout applies a pending modification and a failed one gives it up. A top-up completed after its
+// modification was given up is refunded in full; the refund scheduler sends the refund. The booking and
+// payment are locked.
+func (s *Service) applyModificationCheckoutTx(tx *sql.Tx, eventType payments.EventType, booking *store.Booking, m *store.BookingModification, payment *store.Payment) error {
+	completed := eventType == payments.EventCheckoutCompleted
+	switch {
+	case m.Status == store.MODIFICATION_PENDING && completed:
+		if _, err := s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID)); err != nil {
+			return err
+		}
+		from, to := modificationStays(m)
+		if err := s.applyModificationTx(tx, booking, from, to, m.NewTotalCost); err != nil {
+			return err
+		}
+		if err := s.storageService.ResolveBookingModificationTx(tx, m.ID, store.MODIFICATION_APPLIED); err != nil {
+			return err
+		}
+		return s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_SUCCESS)
+	case m.Status == store.MODIFICATION_PENDING:
+		return s.failModificationTx(tx, booking, m)
+	case completed && payment.Status != constants.PAYMENT_SUCCESS && payment.Status != constants.PAYMENT_REFUNDED:
+		log.Printf("Top-up %s of booking %d paid after its modification was given up, refunding it", payment.ID, booking.BookingID)
+		if err := s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_SUCCESS); err != nil {
+			return err
+		}
+		_, err := s.createRefundTx(tx, payment, 0, "paid after the booking modification lapsed")
+		return err
+	case !completed && payment.Status == constants.PAYMENT_PENDING:
+		return s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_FAILED)
+	}
+	log.Println("Booking modification already settled, no further action required")
+	return nil
+}
+
+// ExpireBookingModifications gives up the modifications whose difference has not been paid within
+// BookingModificationHold, giving back the extra rooms they held. Each top-up is reconciled with the
+// gateway first, so a paid one whose webhook got lost is applied instead, and its checkout is closed
+// before the modification is given up. It is run by the payment scheduler.
+func (s *Service) ExpireBookingModifications() error {
+	modifications, err := s.storageService.GetPendingBookingModificationsCreatedBefore(time.Now().Add(-constants.BookingModificationHold))
+	if err != nil {
+		return err
+	}
+	for _, m := range modifications {
+		payment, err := s.storageService.GetPaymentById(m.PaymentID.String)
+		if err != nil {
+			log.Printf("Failed to get top-up %s of booking %d: %v", m.PaymentID.String, m.BookingID, err)
+			continue
+		}
+		if payment != nil && payment.Status == constants.PAYMENT_PENDING {
+			settled, err := s.reconcilePayment(payment)
+			if err != nil {
+				log.Printf("Failed to reconcile top-up %s before expiring its modification, retrying on the next run: %v", payment.ID, err)
+				continue
+			}
+			if settled {
+				continue
+			}
+			// Close the checkout first, so the guest cannot pay for a modification that is given up. This
+			// fails if they have just paid, and the next run reconciles the payment instead.
+			if err = s.paymentGateway.ExpireCheckout(payment.CheckoutSessionId); err != nil {
+				log.Printf("Failed to expire the checkout of top-up %s, retrying on the next run: %v", payment.ID, err)
+				continue
+			}
+		}
+		if err = s.expireBookingModification(m); err != nil {
+			log.Printf("Failed to expire modification %d of booking %d: %v", m.ID, m.BookingID, err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) expireBookingModification(m *store.BookingModification) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	// The booking is locked first, as everywhere else its modifications are settled
+	booking, err := s.storageService.GetBookingByIdTx(tx, int64(m.BookingID))
+	if err != nil {
+		return err
+	}
+	m, err = s.storageService.GetBookingModificationByPaymentIdTx(tx, m.PaymentID.String)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.Status != store.MODIFICATION_PENDING {
+		// Settled by its webhook in the meantime
+		return nil
+	}
+	return s.failModificationTx(tx, &booking, m)
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"database/sql"
+	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventoryDelta(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		from     stay
+		to       stay
+		expected []stay
+	}{
+		{
+			name:     "same stay",
+			from:     stay{checkIn: day(10), checkOut: day(13), rooms: 2},
+			to:       stay{checkIn: day(10), checkOut: day(13), rooms: 2},
+			expected: nil,
+		},
+		{
+			name:     "more rooms",
+			from:     stay{checkIn: day(10), checkOut: day(13), rooms: 1},
+			to:       stay{checkIn: day(10), checkOut: day(13), rooms: 3},
+			expected: []stay{{checkIn: day(10), checkOut: day(13), rooms: 2}},
+		},
+		{
+			name:     "fewer rooms",
+			from:     stay{checkIn: day(10), checkOut: day(13), rooms: 3},
+			to:       stay{checkIn: day(10), checkOut: day(13), rooms: 1},
+			expected: nil,
+		},
+		{
+			name:     "shifted a day later",
+			from:     stay{checkIn: day(10), checkOut: day(13), rooms: 2},
+			to:       stay{checkIn: day(11), checkOut: day(14), rooms: 2},
+			expected: []stay{{checkIn: day(13), checkOut: day(14), rooms: 2}},
+		},
+		{
+			name:     "extended on both ends",
+			from:     stay{checkIn: day(10), checkOut: day(13), rooms: 1},
+			to:       stay{checkIn: day(9), checkOut: day(15), rooms: 1},
+			expected: []stay{{checkIn: day(9), checkOut: day(10), rooms: 1}, {checkIn: day(13), checkOut: day(15), rooms: 1}},
+		},
+		{
+			name: "shifted later with more rooms",
+			from: stay{checkIn: day(10), checkOut: day(13), rooms: 1},
+			to:   stay{checkIn: day(12), checkOut: day(15), rooms: 2},
+			expected: []stay{
+				{checkIn: day(12), checkOut: day(13), rooms: 1},
+				{checkIn: day(13), checkOut: day(15), rooms: 2},
+			},
+		},
+		{
+			name:     "moved to other dates",
+			from:     stay{checkIn: day(10), checkOut: day(12), rooms: 1},
+			to:       stay{checkIn: day(20), checkOut: day(22), rooms: 1},
+			expected: []stay{{checkIn: day(20), checkOut: day(22), rooms: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inventoryDelta(tt.from, tt.to))
+		})
+	}
+}
+
+func modificationTestDay(d int) time.Time {
+	return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+}
+
+// modificationTestBooking is a confirmed two-night stay in one room, booked at 100 a night
+func modificationTestBooking() store.Booking {
+	return store.Booking{
+		BookingID:     1,
+		Reference:     "ABC234",
+		HotelID:       3,
+		RoomTypeID:    5,
+		UserID:        7,
+		NumberOfRooms: 1,
+		NumberOfDays:  2,
+		CheckInDate:   modificationTestDay(10),
+		CheckOutDate:  modificationTestDay(12),
+		Status:        store.BOOKING_CONFIRMED,
+		TotalCost:     200,
+	}
+}
+
+func TestModifyBooking(t *testing.T) {
+	roomType := &store.RoomType{ID: 5, HotelID: 3, Name: "Standard", TotalRooms: 10, CostPerNight: 100}
+	// The free cancellation window of this policy has closed by the time most cases modify the booking
+	policy := store.NewCancellationPolicy(3, 48, 20, false)
+	beforeCheckIn := modificationTestDay(9).Add(12 * time.Hour)
+
+	// lockBooking expects the booking, which has no pending modification, and its hotel to be locked
+	lockBooking := func(m *mocks.MockStorageService) {
+		m.EXPECT().GetBookingByIdTx(gomock.Any(), int64(1)).Return(modificationTestBooking(), nil)
+		m.EXPECT().GetPendingBookingModificationTx(gomock.Any(), int64(1)).Return(nil, nil)
+		m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Name: "Sea View", Status: constants.HOTEL_ACTIVE}, nil)
+		m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(roomType, nil)
+	}
+	expectModification := func(m *mocks.MockStorageService, status store.ModificationStatus, newTotalCost float32) {
+		m.EXPECT().CreateBookingModificationTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, bm *store.BookingModification) error {
+			assert.Equal(t, status, bm.Status)
+			assert.Equal(t, float32(200), bm.OldTotalCost)
+			assert.Equal(t, newTotalCost, bm.NewTotalCost)
+			assert.Equal(t, status == store.MODIFICATION_PENDING, bm.PaymentID.Valid)
+			return nil
+		})
+	}
+
+	tests := []struct {
+		name           string
+		req            *hotelsystem.ModifyBookingRequest
+		now            time.Time
+		setupMocks     func(m *mocks.MockStorageService)
+		expectedErr    error
+		expectedStatus store.ModificationStatus
+		expectedDue    float32
+		expectedRefund []float32
+		expectedFee    float32
+	}{
+		{
+			name: "an extra night holds its room and waits for the difference",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumDays: 3},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				lockBooking(m)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), modificationTestDay(12), modificationTestDay(13)).Return(4, nil)
+				m.EXPECT().ReserveHotelInventoryTx(gomock.Any(), int64(3), int64(5), modificationTestDay(12), modificationTestDay(13), 1).Return(nil)
+				expectModification(m, store.MODIFICATION_PENDING, 300)
+				m.EXPECT().CreatePaymentTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, p *store.Payment) error {
+					assert.Equal(t, float32(100), p.Amount)
+					assert.Equal(t, constants.PAYMENT_PENDING, p.Status)
+					return nil
+				})
+			},
+			expectedStatus: store.MODIFICATION_PENDING,
+			expectedDue:    100,
+		},
+		{
+			name: "more rooms than are free",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumRooms: 3},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				lockBooking(m)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), modificationTestDay(10), modificationTestDay(12)).Return(9, nil)
+			},
+			expectedErr: errRoomsUnavailable,
+		},
+		{
+			name: "other dates at the same price are applied without a refund",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, CheckInDate: "2025-03-20"},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				lockBooking(m)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), modificationTestDay(20), modificationTestDay(22)).Return(0, nil)
+				m.EXPECT().ReserveHotelInventoryTx(gomock.Any(), int64(3), int64(5), modificationTestDay(20), modificationTestDay(22), 1).Return(nil)
+				m.EXPECT().ReleaseHotelInventoryTx(gomock.Any(), int64(5), modificationTestDay(10), modificationTestDay(12), 1).Return(nil)
+				m.EXPECT().UpdateBookingStayTx(gomock.Any(), int64(1), modificationTestDay(20), modificationTestDay(22), 1, float32(200)).Return(nil)
+				expectModification(m, store.MODIFICATION_APPLIED, 200)
+			},
+			expectedStatus: store.MODIFICATION_APPLIED,
+		},
+		{
+			name: "a night given up is refunded from the newest payments first, less the penalty",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumDays: 1},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				lockBooking(m)
+				m.EXPECT().ReleaseHotelInventoryTx(gomock.Any(), int64(5), modificationTestDay(11), modificationTestDay(12), 1).Return(nil)
+				m.EXPECT().UpdateBookingStayTx(gomock.Any(), int64(1), modificationTestDay(10), modificationTestDay(11), 1, float32(100)).Return(nil)
+				expectModification(m, store.MODIFICATION_APPLIED, 100)
+				m.EXPECT().GetCancellationPolicy(int64(3)).Return(policy, nil)
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return([]*store.Payment{
+					{ID: "original", BookingID: 1, Amount: 150, Status: constants.PAYMENT_SUCCESS},
+					{ID: "failed", BookingID: 1, Amount: 80, Status: constants.PAYMENT_FAILED},
+					{ID: "top-up", BookingID: 1, Amount: 50, Status: constants.PAYMENT_SUCCESS},
+				}, nil)
+				m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "top-up", refundableStatuses).Return(float32(10), nil).Times(2)
+				m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "original", refundableStatuses).Return(float32(0), nil).Times(2)
+				m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+			expectedStatus: store.MODIFICATION_APPLIED,
+			expectedRefund: []float32{40, 40},
+			expectedFee:    20,
+		},
+		{
+			name: "a night given up inside the free cancellation window is refunded in full",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumDays: 1},
+			now:  modificationTestDay(1),
+			setupMocks: func(m *mocks.MockStorageService) {
+				lockBooking(m)
+				m.EXPECT().ReleaseHotelInventoryTx(gomock.Any(), int64(5), modificationTestDay(11), modificationTestDay(12), 1).Return(nil)
+				m.EXPECT().UpdateBookingStayTx(gomock.Any(), int64(1), modificationTestDay(10), modificationTestDay(11), 1, float32(100)).Return(nil)
+				expectModification(m, store.MODIFICATION_APPLIED, 100)
+				m.EXPECT().GetCancellationPolicy(int64(3)).Return(policy, nil)
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return([]*store.Payment{
+					{ID: "original", BookingID: 1, Amount: 200, Status: constants.PAYMENT_SUCCESS},
+				}, nil)
+				m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "original", refundableStatuses).Return(float32(0), nil).Times(2)
+				m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedStatus: store.MODIFICATION_APPLIED,
+			expectedRefund: []float32{100},
+		},
+		{
+			name: "the current check-in date repeated is nothing to modify",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, CheckInDate: "2025-03-10"},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				// Dates come back from the database in a location of their own
+				booking := modificationTestBooking()
+				booking.CheckInDate = booking.CheckInDate.In(time.FixedZone("", 0))
+				booking.CheckOutDate = booking.CheckOutDate.In(time.FixedZone("", 0))
+				m.EXPECT().GetBookingByIdTx(gomock.Any(), int64(1)).Return(booking, nil)
+				m.EXPECT().GetPendingBookingModificationTx(gomock.Any(), int64(1)).Return(nil, nil)
+			},
+			expectedErr: errNothingToModify,
+		},
+		{
+			name: "an earlier modification is waiting for payment",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumDays: 3},
+			now:  beforeCheckIn,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingByIdTx(gomock.Any(), int64(1)).Return(modificationTestBooking(), nil)
+				m.EXPECT().GetPendingBookingModificationTx(gomock.Any(), int64(1)).Return(&store.BookingModification{ID: 9}, nil)
+			},
+			expectedErr: errModificationPending,
+		},
+		{
+			name: "the booking cannot be modified from its check-in time on",
+			req:  &hotelsystem.ModifyBookingRequest{BookingID: 1, NumDays: 3},
+			now:  modificationTestDay(10).Add(constants.CheckInHour * time.Hour),
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingByIdTx(gomock.Any(), int64(1)).Return(modificationTestBooking(), nil)
+			},
+			expectedErr: errBookingNotModifiable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{
+				storageService: mockStore,
+				paymentGateway: payments.NewFakeGateway("http://localhost/pay"),
+			}
+			mockStore.EXPECT().BeginTransaction(gomock.Any()).Return(nil, nil)
+			tt.setupMocks(mockStore)
+
+			resp, refunds, err := service.modifyBooking(1, 7, tt.req, tt.now)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, string(tt.expectedStatus), resp.Status)
+			assert.Equal(t, tt.expectedDue, resp.AmountDue)
+			assert.Equal(t, tt.expectedDue > 0, resp.CheckoutURL != "")
+			assert.Equal(t, tt.expectedFee, resp.PenaltyAmount)
+			var refunded []float32
+			for _, refund := range refunds {
+				refunded = append(refunded, refund.Amount)
+			}
+			assert.Equal(t, tt.expectedRefund, refunded)
+		})
+	}
+}
+
+func TestApplyModificationCheckoutTx(t *testing.T) {
+	// A top-up for one more night, from the 12th to the 13th
+	modification := func(status store.ModificationStatus) *store.BookingModification {
+		return &store.BookingModification{
+			ID:               9,
+			BookingID:        1,
+			OldCheckInDate:   modificationTestDay(10),
+			OldCheckOutDate:  modificationTestDay(12),
+			OldNumberOfRooms: 1,
+			OldTotalCost:     200,
+			NewCheckInDate:   modificationTestDay(10),
+			NewCheckOutDate:  modificationTestDay(13),
+			NewNumberOfRooms: 1,
+			NewTotalCost:     300,
+			Status:           status,
+			PaymentID:        sql.NullString{String: "top-up", Valid: true},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		eventType    payments.EventType
+		modification *store.BookingModification
+		paymentState constants.PaymentStatus
+		setupMocks   func(m *mocks.MockStorageService)
+	}{
+		{
+			name:         "a paid top-up applies the modification",
+			eventType:    payments.EventCheckoutCompleted,
+			modification: modification(store.MODIFICATION_PENDING),
+			paymentState: constants.PAYMENT_PENDING,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3}, nil)
+				m.EXPECT().UpdateBookingStayTx(gomock.Any(), int64(1), modificationTestDay(10), modificationTestDay(13), 1, float32(300)).Return(nil)
+				m.EXPECT().ResolveBookingModificationTx(gomock.Any(), 9, store.MODIFICATION_APPLIED).Return(nil)
+				m.EXPECT().UpdatePaymentStatusTx(gomock.Any(), "top-up", constants.PAYMENT_SUCCESS).Return(nil)
+			},
+		},
+		{
+			name:         "a declined top-up gives the extra night back",
+			eventType:    payments.EventCheckoutFailed,
+			modification: modification(store.MODIFICATION_PENDING),
+			paymentState: constants.PAYMENT_PENDING,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3}, nil)
+				m.EXPECT().ReleaseHotelInventoryTx(gomock.Any(), int64(5), modificationTestDay(12), modificationTestDay(13), 1).Return(nil)
+				m.EXPECT().ResolveBookingModificationTx(gomock.Any(), 9, store.MODIFICATION_FAILED).Return(nil)
+				m.EXPECT().UpdatePaymentStatusTx(gomock.Any(), "top-up", constants.PAYMENT_FAILED).Return(nil)
+			},
+		},
+		{
+			name:         "a top-up paid after its modification lapsed is refunded in full",
+			eventType:    payments.EventCheckoutCompleted,
+			modification: modification(store.MODIFICATION_FAILED),
+			paymentState: constants.PAYMENT_FAILED,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().UpdatePaymentStatusTx(gomock.Any(), "top-up", constants.PAYMENT_SUCCESS).Return(nil)
+				m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "top-up", refundableStatuses).Return(float32(0), nil)
+				m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, refund *store.Refund) error {
+					assert.Equal(t, "top-up", refund.PaymentID)
+					assert.Equal(t, float32(100), refund.Amount)
+					return nil
+				})
+			},
+		},
+		{
+			name:         "a declined top-up of a lapsed modification fails the payment",
+			eventType:    payments.EventCheckoutFailed,
+			modification: modification(store.MODIFICATION_FAILED),
+			paymentState: constants.PAYMENT_PENDING,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().UpdatePaymentStatusTx(gomock.Any(), "top-up", constants.PAYMENT_FAILED).Return(nil)
+			},
+		},
+		{
+			name:         "a repeated event for an applied modification changes nothing",
+			eventType:    payments.EventCheckoutCompleted,
+			modification: modification(store.MODIFICATION_APPLIED),
+			paymentState: constants.PAYMENT_SUCCESS,
+			setupMocks:   func(m *mocks.MockStorageService) {},
+		},
+		{
+			name:         "a refunded late top-up is not refunded again",
+			eventType:    payments.EventCheckoutCompleted,
+			modification: modification(store.MODIFICATION_FAILED),
+			paymentState: constants.PAYMENT_REFUNDED,
+			setupMocks:   func(m *mocks.MockStorageService) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			tt.setupMocks(mockStore)
+
+			booking := modificationTestBooking()
+			payment := &store.Payment{ID: "top-up", BookingID: 1, Amount: 100, Status: tt.paymentState}
+			err := service.applyModificationCheckoutTx(nil, tt.eventType, &booking, tt.modification, payment)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -3,20 +3,22 @@ package services
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"hotel-system/src/constants"
+	"hotel-system/src/payments"
 	"hotel-system/src/serializers"
+	"hotel-system/src/store"
 	hotelsystem "hotel-system/src/types/hotelsystem"
 	"hotel-system/src/utils"
 	"hotel-system/src/validators"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/stripe/stripe-go/v82"
-	"github.com/stripe/stripe-go/v82/checkout/session"
 )
 
 func (s *Service) BookHotel(w http.ResponseWriter, r *http.Request) {
@@ -47,62 +49,62 @@ func (s *Service) BookHotel(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Hotel not found", http.StatusNotFound)
 		return
 	}
+	if hotelForUpdate.Status != constants.HOTEL_ACTIVE {
+		http.Error(w, "Hotel is not taking bookings", http.StatusConflict)
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	booking := serializers.BookingSerializer(&bookHotelRequest, userId)
+
+	roomType, err := s.storageService.GetRoomTypeByIdTx(tx, bookHotelRequest.HotelID, bookHotelRequest.RoomTypeID)
+	if err != nil {
+		log.Println("Error getting room type:", err)
+		http.Error(w, "Failed to fetch room type", http.StatusInternalServerError)
+		return
+	}
+	if roomType == nil {
+		http.Error(w, "Room type not found", http.StatusNotFound)
+		return
+	}
+
+	// The hotel row lock above serialises bookings per hotel, so the calendar read below is stable
+	maxRoomsSold, err := s.storageService.GetMaxRoomsSoldTx(tx, bookHotelRequest.RoomTypeID, booking.CheckInDate, booking.CheckOutDate)
+	if err != nil {
+		log.Println("Error getting hotel inventory:", err)
+		http.Error(w, "Failed to check room availability", http.StatusInternalServerError)
+		return
+	}
 
-	if hotelForUpdate.AvailableRooms < int(bookHotelRequest.NumRooms) {
+	if roomType.TotalRooms-maxRoomsSold < int(bookHotelRequest.NumRooms) {
 		sendJsonResponse(w, serializers.BookHotelResponseSerializer("", 0, nil, 0, "Not enough rooms available"))
 		return
 	}
 
 	//time.Sleep(5 * time.Second) // Simulate some processing delay
 
-	err = s.storageService.DecrementHotelRoomsTx(tx, bookHotelRequest.HotelID, int(bookHotelRequest.NumRooms))
+	err = s.storageService.ReserveHotelInventoryTx(tx, bookHotelRequest.HotelID, bookHotelRequest.RoomTypeID, booking.CheckInDate, booking.CheckOutDate, booking.NumberOfRooms)
 	if err != nil {
-		log.Println("Error updating hotel room count:", err)
+		log.Println("Error reserving hotel inventory:", err)
 		http.Error(w, "Failed to update hotel room count", http.StatusInternalServerError)
 		return
 	}
 
-	userId := r.Context().Value("user_id").(int)
-	booking := serializers.BookingSerializer(&bookHotelRequest, userId)
-	bookingId, err := s.storageService.CreateBookingTx(tx, booking)
+	totalCost := roomType.CostPerNight * float32(bookHotelRequest.NumRooms) * float32(bookHotelRequest.NumDays)
+	booking.TotalCost = totalCost
+	bookingId, err := s.createBookingTx(tx, booking)
 	if err != nil {
+		log.Println("Error creating booking:", err)
 		http.Error(w, "Failed to create booking", http.StatusInternalServerError)
 		return
 	}
 
-	totalCost := hotelForUpdate.CostPerNight * float32(bookHotelRequest.NumRooms) * float32(bookHotelRequest.NumDays)
-	//params := serializers.CreateStripeCheckoutSessionParams(bookingId, hotelForUpdate, totalCost)
-	paymentStatusUrl := "http://localhost:6000/status"
-
 	paymentId := uuid.New().String()
-	params := &stripe.CheckoutSessionParams{
-		LineItems: []*stripe.CheckoutSessionLineItemParams{
-			{
-				// Provide the exact Price ID (for example, price_1234) of the product you want to sell
-				PriceData: &stripe.CheckoutSessionLineItemPriceDataParams{
-					Currency:          stripe.String(stripe.CurrencyUSD),
-					UnitAmountDecimal: stripe.Float64(float64(totalCost * 100)),
-					ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
-						Name: stripe.String("Hotel Booking for " + hotelForUpdate.Name),
-					},
-				},
-				Quantity: stripe.Int64(1),
-			},
-		},
-		Metadata: map[string]string{
-			constants.BookingIdField: fmt.Sprintf("%d", bookingId),
-			"payment_id":             paymentId,
-		},
-		Mode:          stripe.String(string(stripe.CheckoutSessionModePayment)),
-		CustomerEmail: stripe.String("k@gmail.com"),
-		SuccessURL:    stripe.String(paymentStatusUrl),
-		CancelURL:     stripe.String(paymentStatusUrl),
-	}
-
-	checkoutSession, err := session.New(params)
-	if err != nil {
-		log.Printf("session.New: %v", err)
-		http.Error(w, "Failed to create stripe checkout session", http.StatusInternalServerError)
+	checkoutRequest := serializers.CheckoutRequestSerializer(bookingId, paymentId, hotelForUpdate, roomType, totalCost)
+	checkoutSession, err := s.paymentGateway.CreateCheckout(checkoutRequest)
+	if err != nil {
+		log.Printf("CreateCheckout: %v", err)
+		http.Error(w, "Failed to create checkout session", http.StatusInternalServerError)
 		return
 	}
 
@@ -128,6 +130,23 @@ func (s *Service) BookHotel(w http.ResponseWriter, r *http.Request) {
 	sendJsonResponse(w, &resp)
 }
 
+// createBookingTx creates the booking under a new booking reference, drawing another one while the
+// drawn reference is already taken
+func (s *Service) createBookingTx(tx *sql.Tx, booking *store.Booking) (int64, error) {
+	for attempt := 0; attempt < constants.BookingReferenceAttempts; attempt++ {
+		reference, err := utils.NewBookingReference()
+		if err != nil {
+			return 0, err
+		}
+		booking.Reference = reference
+		bookingId, err := s.storageService.CreateBookingTx(tx, booking)
+		if !errors.Is(err, store.ErrBookingReferenceTaken) {
+			return bookingId, err
+		}
+	}
+	return 0, store.ErrBookingReferenceTaken
+}
+
 var CheckoutUrl string // for testing
 
 // Redirects the client to the checkout URL set during booking creation.
@@ -142,6 +161,34 @@ func (s *Service) ClientCheckoutRedirect(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, CheckoutUrl, http.StatusSeeOther)
 }
 
+// FakeCheckout stands in for the hosted checkout page when the fake payment gateway is configured.
+// It settles the checkout as paid, or declined with outcome=failed, and delivers the webhook event
+// the gateway sends for it to the webhook inbox.
+func (s *Service) FakeCheckout(w http.ResponseWriter, r *http.Request) {
+	fakeGateway, ok := s.paymentGateway.(*payments.FakeGateway)
+	if !ok {
+		http.Error(w, "Fake payment gateway not configured", http.StatusNotFound)
+		return
+	}
+	event, err := fakeGateway.Pay(r.URL.Query().Get("session_id"), r.URL.Query().Get("outcome") != "failed")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The fake gateway's webhook body is the event itself
+	rawBody, err := json.Marshal(event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = s.receiveWebhookEvent(event, rawBody); err != nil {
+		log.Println("Error storing webhook event:", err)
+		http.Error(w, "Failed to deliver payment event", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Checkout "+string(event.Checkout.Status))
+}
+
 func (s *Service) GetBookingDetailsById(w http.ResponseWriter, r *http.Request) {
 	var getBookingRequest hotelsystem.GetBookingDetailsRequest
 	err := json.NewDecoder(r.Body).Decode(&getBookingRequest)
@@ -155,34 +202,38 @@ func (s *Service) GetBookingDetailsById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	booking, err := s.storageService.GetBookingById(getBookingRequest.BookingID)
-	if err != nil {
+	booking, err := s.getVisibleBooking(r, getBookingRequest.BookingID, getBookingRequest.BookingReference)
+	if errors.Is(err, errBookingNotFound) {
 		http.Error(w, "Booking not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		log.Println("Error getting booking:", err)
+		http.Error(w, "Failed to get booking", http.StatusInternalServerError)
+		return
+	}
 
-	payment, err := s.storageService.GetPaymentByBookingId(getBookingRequest.BookingID)
+	payment, err := s.storageService.GetPaymentByBookingId(int64(booking.BookingID))
 	if err != nil {
 		log.Println("Error getting booking details:", err)
 	}
-	var (
-		status    constants.PaymentStatus
-		totalCost float32
-	)
+	var status constants.PaymentStatus
 	if payment != nil {
 		status = payment.Status
-		totalCost = payment.Amount
 	}
 	sendJsonResponse(w, hotelsystem.GetBookingDetailsResponse{
-		BookingID:     int64(booking.BookingID),
-		NumRooms:      int32(booking.NumberOfRooms),
-		NumDays:       int32(booking.NumberOfDays),
-		CheckInDate:   booking.CheckInDate.Format(constants.DateFormat),
-		CheckOutDate:  booking.CheckOutDate.Format(constants.DateFormat),
-		TotalCost:     totalCost,
-		Status:        string(booking.Status),
-		PaymentStatus: string(status),
-		BookingTime:   booking.BookingTime.String(),
+		BookingID:             int64(booking.BookingID),
+		NumRooms:              int32(booking.NumberOfRooms),
+		NumDays:               int32(booking.NumberOfDays),
+		CheckInDate:           booking.CheckInDate.Format(constants.DateFormat),
+		CheckOutDate:          booking.CheckOutDate.Format(constants.DateFormat),
+		TotalCost:             booking.TotalCost,
+		Status:                string(booking.Status),
+		PaymentStatus:         string(status),
+		BookingTime:           booking.BookingTime.String(),
+		RoomTypeID:            int64(booking.RoomTypeID),
+		LatePaymentResolution: string(booking.LatePaymentResolution),
+		BookingReference:      booking.Reference,
 	})
 }
 
@@ -247,3 +298,71 @@ func (s *Service) checkIdempotencyForBookingAndReturnResponse(
 	return &bookHotelResponse, nil
 
 }
+
+// GetMyBookings lists the bookings of the caller, upcoming, past or cancelled, with their hotel and
+// payment
+func (s *Service) GetMyBookings(w http.ResponseWriter, r *http.Request) {
+	var getMyBookingsRequest hotelsystem.GetMyBookingsRequest
+	err := json.NewDecoder(r.Body).Decode(&getMyBookingsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if getMyBookingsRequest.Limit == 0 {
+		getMyBookingsRequest.Limit = constants.DefaultPageSize
+	}
+	if err = validators.ValidateGetMyBookingsRequest(&getMyBookingsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	today := time.Now().Truncate(24 * time.Hour)
+	bookings, err := s.storageService.GetUserBookings(userId, &getMyBookingsRequest, today)
+	if err != nil {
+		log.Println("Error getting user bookings:", err)
+		http.Error(w, "Failed to get bookings", http.StatusInternalServerError)
+		return
+	}
+	totalRecords, err := s.storageService.CountUserBookings(userId, &getMyBookingsRequest, today)
+	if err != nil {
+		log.Println("Error counting user bookings:", err)
+		http.Error(w, "Failed to get bookings", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.MyBookingsResponseSerializer(bookings, totalRecords))
+}
+
+// GetHotelBookings lists a hotel's bookings for its staff and for admins
+func (s *Service) GetHotelBookings(w http.ResponseWriter, r *http.Request) {
+	var getHotelBookingsRequest hotelsystem.GetHotelBookingsRequest
+	err := json.NewDecoder(r.Body).Decode(&getHotelBookingsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetHotelBookingsRequest(&getHotelBookingsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	canManage, err := s.canManageHotel(r, getHotelBookingsRequest.HotelID)
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Failed to get bookings", http.StatusInternalServerError)
+		return
+	}
+	if !canManage {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	bookings, err := s.storageService.GetBookingsByHotelId(getHotelBookingsRequest.HotelID, store.BookingStatus(getHotelBookingsRequest.Status),
+		getHotelBookingsRequest.Limit, getHotelBookingsRequest.Offset)
+	if err != nil {
+		log.Println("Error getting hotel bookings:", err)
+		http.Error(w, "Failed to get bookings", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.HotelBookingsResponseSerializer(bookings))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+var (
+	errBookingNotFound       = errors.New("booking not found")
+	errBookingNotCancellable = errors.New("only pending and confirmed bookings can be cancelled, and only before check-in")
+)
+
+func (s *Service) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	var cancelBookingRequest hotelsystem.CancelBookingRequest
+	err := json.NewDecoder(r.Body).Decode(&cancelBookingRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateCancelBookingRequest(&cancelBookingRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	resp, refunds, err := s.cancelBooking(cancelBookingRequest.BookingID, userId, time.Now())
+	switch {
+	case errors.Is(err, errBookingNotFound):
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errBookingNotCancellable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Println("Error cancelling booking:", err)
+		http.Error(w, "Failed to cancel booking", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Booking %d cancelled by user %d: %s", cancelBookingRequest.BookingID, userId, cancelBookingRequest.Reason)
+	for i, refund := range refunds {
+		attempted := s.attemptRefund(refund)
+		if i == 0 {
+			resp.RefundStatus = string(attempted.Status)
+		}
+	}
+	sendJsonResponse(w, resp)
+}
+
+// cancelBooking cancels a booking of the user before its check-in time and returns its rooms to the
+// inventory calendar in one transaction. The refundable amount of each payment follows the hotel's
+// cancellation policy and is recorded as a pending refund, which the caller sends to the gateway once
+// the transaction is committed.
+// The response reports the refund of the payment the booking was made with first, followed by those of
+// any top-ups paid when it was modified.
+func (s *Service) cancelBooking(bookingId int64, userId int, now time.Time) (resp *hotelsystem.CancelBookingResponse, refunds []*store.Refund, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	booking, err := s.storageService.GetBookingByIdTx(tx, bookingId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, errBookingNotFound
+		}
+		return nil, nil, err
+	}
+	// Other guests' bookings are reported as missing so their ids cannot be probed
+	if booking.UserID != userId {
+		return nil, nil, errBookingNotFound
+	}
+	checkInTime := booking.CheckInDate.Add(constants.CheckInHour * time.Hour)
+	if booking.Status != store.BOOKING_PENDING && booking.Status != store.BOOKING_CONFIRMED || !now.Before(checkInTime) {
+		return nil, nil, errBookingNotCancellable
+	}
+
+	// A modification waiting for its payment is given up first, giving back the extra rooms it held
+	modification, err := s.storageService.GetPendingBookingModificationTx(tx, bookingId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if modification != nil {
+		if err = s.failModificationTx(tx, &booking, modification); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Lock the hotel the same way BookHotel does before touching its inventory
+	if _, err = s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID)); err != nil {
+		return nil, nil, err
+	}
+	err = s.storageService.ReleaseHotelInventoryTx(tx, int64(booking.RoomTypeID), booking.CheckInDate, booking.CheckOutDate, booking.NumberOfRooms)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = s.storageService.UpdateBookingStatusTx(tx, bookingId, store.BOOKING_CANCELLED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp = &hotelsystem.CancelBookingResponse{
+		BookingID: bookingId,
+		Status:    string(store.BOOKING_CANCELLED),
+		Message:   "Booking cancelled successfully",
+	}
+
+	bookingPayments, err := s.storageService.GetPaymentsByBookingIdTx(tx, bookingId)
+	if err != nil {
+		return nil, nil, err
+	}
+	var policy *store.CancellationPolicy
+	for _, payment := range bookingPayments {
+		switch payment.Status {
+		case constants.PAYMENT_SUCCESS:
+			if policy == nil {
+				if policy, err = s.getCancellationPolicy(int64(booking.HotelID)); err != nil {
+					return nil, nil, err
+				}
+			}
+			// The policy applies to what the guest still has paid, after any refund made before the
+			// cancellation, such as the difference of a modification to a cheaper stay
+			refunded, err := s.storageService.SumRefundsByPaymentIdTx(tx, payment.ID, refundableStatuses)
+			if err != nil {
+				return nil, nil, err
+			}
+			amountPaid := payment.Amount - refunded
+			if amountPaid <= 0 {
+				continue
+			}
+			refundAmount, penaltyAmount := calculateRefund(policy, amountPaid, booking.CheckInDate, now)
+			resp.AmountPaid += amountPaid
+			resp.PenaltyAmount += penaltyAmount
+			if refundAmount == 0 {
+				continue
+			}
+			refund, err := s.createRefundTx(tx, payment, refundAmount, "booking cancelled by guest")
+			if err != nil {
+				return nil, nil, err
+			}
+			resp.RefundAmount += refund.Amount
+			refunds = append(refunds, refund)
+		case constants.PAYMENT_PENDING:
+			// The guest has not paid yet; fail the payment so the checkout can no longer confirm the booking
+			err = s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_FAILED)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(refunds) > 0 {
+		resp.RefundID = refunds[0].ID
+		resp.RefundStatus = string(refunds[0].Status)
+	}
+	return resp, refunds, nil
+}
+
+// calculateRefund splits the amount paid for a booking into the part refunded to the guest and the
+// penalty kept by the hotel, according to the policy and how long before check-in the guest cancels.
+func calculateRefund(policy *store.CancellationPolicy, amountPaid float32, checkInDate time.Time, now time.Time) (refund float32, penalty float32) {
+	if policy.NonRefundable {
+		return 0, amountPaid
+	}
+	checkInTime := checkInDate.Add(constants.CheckInHour * time.Hour)
+	freeUntil := checkInTime.Add(-time.Duration(policy.FreeCancellationHours) * time.Hour)
+	if now.Before(freeUntil) {
+		return amountPaid, 0
+	}
+	penalty = float32(math.Round(float64(amountPaid*policy.PenaltyPercent)) / 100)
+	return amountPaid - penalty, penalty
+}
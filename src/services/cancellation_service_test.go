@@ -0,0 +1,59 @@
+package services
+
+import (
+	"hotel-system/src/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateRefund(t *testing.T) {
+	checkInDate := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	checkInTime := checkInDate.Add(12 * time.Hour)
+
+	tests := []struct {
+		name            string
+		policy          *store.CancellationPolicy
+		now             time.Time
+		expectedRefund  float32
+		expectedPenalty float32
+	}{
+		{
+			name:            "cancelled before the free cancellation window closes",
+			policy:          store.NewCancellationPolicy(1, 24, 50, false),
+			now:             checkInTime.Add(-25 * time.Hour),
+			expectedRefund:  1000,
+			expectedPenalty: 0,
+		},
+		{
+			name:            "cancelled inside the penalty window",
+			policy:          store.NewCancellationPolicy(1, 24, 25, false),
+			now:             checkInTime.Add(-23 * time.Hour),
+			expectedRefund:  750,
+			expectedPenalty: 250,
+		},
+		{
+			name:            "full penalty after the window",
+			policy:          store.NewCancellationPolicy(1, 48, 100, false),
+			now:             checkInTime.Add(-time.Hour),
+			expectedRefund:  0,
+			expectedPenalty: 1000,
+		},
+		{
+			name:            "non refundable rate",
+			policy:          store.NewCancellationPolicy(1, 24, 0, true),
+			now:             checkInTime.Add(-30 * 24 * time.Hour),
+			expectedRefund:  0,
+			expectedPenalty: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refund, penalty := calculateRefund(tt.policy, 1000, checkInDate, tt.now)
+			assert.Equal(t, tt.expectedRefund, refund)
+			assert.Equal(t, tt.expectedPenalty, penalty)
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/imaging"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+var (
+	errTooManyHotelImages = fmt.Errorf("a hotel can have at most %d images", constants.MaxHotelImages)
+	errHotelImageNotFound = errors.New("hotel image not found")
+	errImageOrderMismatch = errors.New("image_ids must list every image of the hotel exactly once")
+)
+
+// multipartOverhead leaves room in the request body for the form fields and part headers around the image
+const multipartOverhead = 64 << 10
+
+// UploadHotelImage adds an image to the end of a hotel's gallery. The request is multipart/form-data
+// with the fields hotel_id and image. The original is stored as uploaded, next to a JPEG thumbnail.
+func (s *Service) UploadHotelImage(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, constants.MaxImageUploadBytes+multipartOverhead)
+	if err := r.ParseMultipartForm(constants.MaxImageUploadBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("Image must be at most %d bytes", constants.MaxImageUploadBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	hotelId, err := strconv.ParseInt(r.FormValue("hotel_id"), 10, 64)
+	if err != nil || hotelId <= 0 {
+		http.Error(w, "hotel_id must be > 0", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "image file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if header.Size > constants.MaxImageUploadBytes {
+		http.Error(w, fmt.Sprintf("Image must be at most %d bytes", constants.MaxImageUploadBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, hotelId) {
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// The content type is taken from the bytes, never from what the client claims
+	img, contentType, err := imaging.Decode(data, constants.MaxImagePixels)
+	if errors.Is(err, imaging.ErrUnsupportedImage) {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hotelImage, err := s.storeHotelImage(hotelId, data, img, contentType)
+	if errors.Is(err, errHotelNotFound) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errTooManyHotelImages) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error storing hotel image:", err)
+		http.Error(w, "Could not upload image", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, &hotelsystem.UploadHotelImageResponse{
+		Status:  "S",
+		Message: "Image uploaded successfully",
+		Image:   serializers.HotelImageResponseSerializer(hotelImage),
+	})
+}
+
+// storeHotelImage writes an uploaded image and its thumbnail to the blob store and adds them to the
+// gallery. The blobs are removed again if the image cannot be added.
+func (s *Service) storeHotelImage(hotelId int64, data []byte, img image.Image, contentType string) (*store.HotelImage, error) {
+	thumbnail, err := imaging.EncodeJPEG(imaging.Thumbnail(img, constants.ThumbnailMaxSize), constants.ThumbnailJPEGQuality)
+	if err != nil {
+		return nil, err
+	}
+
+	id := utils.NewUuid()
+	bounds := img.Bounds()
+	hotelImage := &store.HotelImage{
+		ID:           id,
+		HotelID:      int(hotelId),
+		BlobKey:      fmt.Sprintf("hotels/%d/%s%s", hotelId, id, imaging.ContentTypes[contentType]),
+		ThumbnailKey: fmt.Sprintf("hotels/%d/%s_thumb.jpg", hotelId, id),
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		Width:        bounds.Dx(),
+		Height:       bounds.Dy(),
+		CreatedAt:    time.Now(),
+	}
+	hotelImage.URL = s.blobStore.URL(hotelImage.BlobKey)
+	hotelImage.ThumbnailURL = s.blobStore.URL(hotelImage.ThumbnailKey)
+
+	if err = s.blobStore.Put(hotelImage.BlobKey, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+	if err = s.blobStore.Put(hotelImage.ThumbnailKey, bytes.NewReader(thumbnail), "image/jpeg"); err != nil {
+		s.deleteImageBlobs(hotelImage)
+		return nil, err
+	}
+	if err = s.addHotelImage(hotelImage); err != nil {
+		s.deleteImageBlobs(hotelImage)
+		return nil, err
+	}
+	return hotelImage, nil
+}
+
+func (s *Service) addHotelImage(image *store.HotelImage) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	// Locking the hotel keeps concurrent uploads from going over the limit
+	hotelId := int64(image.HotelID)
+	if _, err = s.getHotelForUpdateTx(tx, hotelId); err != nil {
+		return err
+	}
+	images, err := s.storageService.GetHotelImagesTx(tx, hotelId)
+	if err != nil {
+		return err
+	}
+	if len(images) >= constants.MaxHotelImages {
+		return errTooManyHotelImages
+	}
+	if err = s.storageService.CreateHotelImageTx(tx, image); err != nil {
+		return err
+	}
+	return s.storageService.SyncHotelImageUrlsTx(tx, hotelId)
+}
+
+// deleteImageBlobs removes the blobs of an image; failures only leave orphaned files, so they are logged
+func (s *Service) deleteImageBlobs(image *store.HotelImage) {
+	for _, key := range []string{image.BlobKey, image.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.blobStore.Delete(key); err != nil {
+			log.Printf("Error deleting blob %s of hotel image %s: %v", key, image.ID, err)
+		}
+	}
+}
+
+// GetHotelImages returns a hotel's gallery in order, with the thumbnail and size of each image
+func (s *Service) GetHotelImages(w http.ResponseWriter, r *http.Request) {
+	var getHotelImagesRequest hotelsystem.GetHotelImagesRequest
+	err := json.NewDecoder(r.Body).Decode(&getHotelImagesRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetHotelImagesRequest(&getHotelImagesRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hotel, err := s.storageService.GetHotelById(getHotelImagesRequest.HotelID)
+	if err != nil {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	visible, err := s.canViewHotel(r, &hotel)
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Could not get hotel images", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+
+	images, err := s.storageService.GetHotelImages(getHotelImagesRequest.HotelID)
+	if err != nil {
+		log.Println("Error getting hotel images:", err)
+		http.Error(w, "Could not get hotel images", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.HotelImagesResponseSerializer(images))
+}
+
+// ReorderHotelImages puts a hotel's gallery in a new order. The first image is the one shown with the
+// hotel in listings.
+func (s *Service) ReorderHotelImages(w http.ResponseWriter, r *http.Request) {
+	var reorderRequest hotelsystem.ReorderHotelImagesRequest
+	err := json.NewDecoder(r.Body).Decode(&reorderRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateReorderHotelImagesRequest(&reorderRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, reorderRequest.HotelID) {
+		return
+	}
+
+	err = s.reorderHotelImages(reorderRequest.HotelID, reorderRequest.ImageIDs)
+	if errors.Is(err, errHotelNotFound) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errImageOrderMismatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println("Error reordering hotel images:", err)
+		http.Error(w, "Could not reorder images", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Images reordered successfully")
+}
+
+func (s *Service) reorderHotelImages(hotelId int64, imageIds []string) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	if _, err = s.getHotelForUpdateTx(tx, hotelId); err != nil {
+		return err
+	}
+	images, err := s.storageService.GetHotelImagesTx(tx, hotelId)
+	if err != nil {
+		return err
+	}
+	if !isImageOrder(images, imageIds) {
+		return errImageOrderMismatch
+	}
+	if err = s.storageService.ReorderHotelImagesTx(tx, hotelId, imageIds); err != nil {
+		return err
+	}
+	return s.storageService.SyncHotelImageUrlsTx(tx, hotelId)
+}
+
+// isImageOrder reports whether imageIds names each of the images exactly once
+func isImageOrder(images []*store.HotelImage, imageIds []string) bool {
+	if len(images) != len(imageIds) {
+		return false
+	}
+	for _, image := range images {
+		if !slices.Contains(imageIds, image.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteHotelImage removes an image from a hotel's gallery, along with its stored files
+func (s *Service) DeleteHotelImage(w http.ResponseWriter, r *http.Request) {
+	var deleteRequest hotelsystem.DeleteHotelImageRequest
+	err := json.NewDecoder(r.Body).Decode(&deleteRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateDeleteHotelImageRequest(&deleteRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, deleteRequest.HotelID) {
+		return
+	}
+
+	image, err := s.deleteHotelImage(deleteRequest.HotelID, deleteRequest.ImageID)
+	if errors.Is(err, errHotelNotFound) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errHotelImageNotFound) {
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error deleting hotel image:", err)
+		http.Error(w, "Could not delete image", http.StatusInternalServerError)
+		return
+	}
+	// Only once the image is out of the gallery, so that it never points at a missing file
+	s.deleteImageBlobs(image)
+	sendSuccessResponse(w, "Image deleted successfully")
+}
+
+func (s *Service) deleteHotelImage(hotelId int64, imageId string) (image *store.HotelImage, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	if _, err = s.getHotelForUpdateTx(tx, hotelId); err != nil {
+		return nil, err
+	}
+	image, err = s.storageService.DeleteHotelImageTx(tx, hotelId, imageId)
+	if err != nil {
+		return nil, err
+	}
+	if image == nil {
+		return nil, errHotelImageNotFound
+	}
+	if err = s.storageService.SyncHotelImageUrlsTx(tx, hotelId); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"hotel-system/src/store"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsImageOrder(t *testing.T) {
+	images := []*store.HotelImage{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	tests := []struct {
+		name     string
+		imageIds []string
+		want     bool
+	}{
+		{"same order", []string{"a", "b", "c"}, true},
+		{"new order", []string{"c", "a", "b"}, true},
+		{"missing image", []string{"a", "b"}, false},
+		{"unknown image", []string{"a", "b", "d"}, false},
+		{"extra image", []string{"a", "b", "c", "d"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isImageOrder(images, tt.imageIds))
+		})
+	}
+}
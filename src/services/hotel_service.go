@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	errHotelNotFound            = errors.New("hotel not found")
+	errRoomTypeNotFound         = errors.New("room type not found")
+	errHotelHasUpcomingBookings = errors.New("hotel has upcoming bookings; deactivate it instead, or cancel them first")
+)
+
+// roomsSoldError rejects shrinking a room type below the rooms already sold on an upcoming night
+type roomsSoldError struct {
+	roomTypeId int
+	roomsSold  int
+}
+
+func (e *roomsSoldError) Error() string {
+	return fmt.Sprintf("room type %d has %d rooms sold on an upcoming night; total_rooms cannot go below that", e.roomTypeId, e.roomsSold)
+}
+
+// UpdateHotel changes the details of a hotel and its room types. Only the fields that are set in the
+// request are changed.
+func (s *Service) UpdateHotel(w http.ResponseWriter, r *http.Request) {
+	var updateHotelRequest hotelsystem.UpdateHotelRequest
+	err := json.NewDecoder(r.Body).Decode(&updateHotelRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateUpdateHotelRequest(&updateHotelRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, updateHotelRequest.HotelID) {
+		return
+	}
+
+	err = s.updateHotel(&updateHotelRequest, time.Now())
+	var soldErr *roomsSoldError
+	switch {
+	case errors.Is(err, errHotelNotFound):
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errRoomTypeNotFound):
+		http.Error(w, "Room type not found", http.StatusNotFound)
+		return
+	case errors.As(err, &soldErr):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Println("Error updating hotel:", err)
+		http.Error(w, "Could not update hotel", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Hotel updated successfully")
+}
+
+// DeactivateHotel takes a hotel off sale. It disappears from the listing and search and takes no new
+// bookings; existing bookings are kept.
+func (s *Service) DeactivateHotel(w http.ResponseWriter, r *http.Request) {
+	s.changeHotelStatus(w, r, constants.HOTEL_INACTIVE, "Hotel deactivated successfully")
+}
+
+// ReactivateHotel puts a deactivated hotel back on sale
+func (s *Service) ReactivateHotel(w http.ResponseWriter, r *http.Request) {
+	s.changeHotelStatus(w, r, constants.HOTEL_ACTIVE, "Hotel reactivated successfully")
+}
+
+// DeleteHotel soft deletes a hotel. Its row, room types and past bookings are kept for the booking
+// history, but the hotel can no longer be found or managed. Hotels with upcoming bookings cannot be
+// deleted.
+func (s *Service) DeleteHotel(w http.ResponseWriter, r *http.Request) {
+	s.changeHotelStatus(w, r, constants.HOTEL_DELETED, "Hotel deleted successfully")
+}
+
+func (s *Service) changeHotelStatus(w http.ResponseWriter, r *http.Request, status constants.HotelStatus, message string) {
+	var hotelStatusRequest hotelsystem.HotelStatusRequest
+	err := json.NewDecoder(r.Body).Decode(&hotelStatusRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateHotelStatusRequest(&hotelStatusRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, hotelStatusRequest.HotelID) {
+		return
+	}
+
+	err = s.setHotelStatus(hotelStatusRequest.HotelID, status, time.Now())
+	if errors.Is(err, errHotelNotFound) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errHotelHasUpcomingBookings) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error changing hotel status:", err)
+		http.Error(w, "Could not change hotel status", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, message)
+}
+
+// authorizeHotelManagement answers 404 for hotels that do not exist or were deleted and 403 to
+// callers who may not manage the hotel, and reports whether the request may go on
+func (s *Service) authorizeHotelManagement(w http.ResponseWriter, r *http.Request, hotelId int64) bool {
+	hotel, err := s.storageService.GetHotelById(hotelId)
+	if err != nil || hotel.Status == constants.HOTEL_DELETED {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return false
+	}
+	canManage, err := s.canManageHotel(r, hotelId)
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return false
+	}
+	if !canManage {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// getHotelForUpdateTx locks a hotel that has not been deleted, against concurrent bookings and edits
+func (s *Service) getHotelForUpdateTx(tx *sql.Tx, hotelId int64) (*store.Hotel, error) {
+	hotel, err := s.storageService.GetHotelForUpdate(tx, hotelId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errHotelNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hotel.Status == constants.HOTEL_DELETED {
+		return nil, errHotelNotFound
+	}
+	return &hotel, nil
+}
+
+func (s *Service) updateHotel(req *hotelsystem.UpdateHotelRequest, now time.Time) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	// The hotel lock keeps bookings from selling rooms while a room type shrinks
+	hotel, err := s.getHotelForUpdateTx(tx, req.HotelID)
+	if err != nil {
+		return err
+	}
+	applyHotelUpdate(hotel, req)
+	if err = s.storageService.UpdateHotelDetailsTx(tx, hotel); err != nil {
+		return err
+	}
+
+	today := now.Truncate(24 * time.Hour)
+	for _, rtUpdate := range req.RoomTypes {
+		roomType, err := s.storageService.GetRoomTypeByIdTx(tx, req.HotelID, rtUpdate.RoomTypeID)
+		if err != nil {
+			return err
+		}
+		if roomType == nil {
+			return errRoomTypeNotFound
+		}
+		if rtUpdate.TotalRooms != nil && int(*rtUpdate.TotalRooms) < roomType.TotalRooms {
+			roomsSold, err := s.storageService.GetPeakRoomsSoldSinceTx(tx, rtUpdate.RoomTypeID, today)
+			if err != nil {
+				return err
+			}
+			if int(*rtUpdate.TotalRooms) < roomsSold {
+				return &roomsSoldError{roomTypeId: roomType.ID, roomsSold: roomsSold}
+			}
+		}
+		applyRoomTypeUpdate(roomType, rtUpdate)
+		if err = s.storageService.UpdateRoomTypeTx(tx, roomType); err != nil {
+			return err
+		}
+	}
+	if len(req.RoomTypes) > 0 {
+		return s.storageService.RefreshHotelRoomSummaryTx(tx, req.HotelID)
+	}
+	return nil
+}
+
+func applyHotelUpdate(hotel *store.Hotel, req *hotelsystem.UpdateHotelRequest) {
+	if req.Name != nil {
+		hotel.Name = *req.Name
+	}
+	if req.Description != nil {
+		hotel.Description = *req.Description
+	}
+	if req.Street != nil {
+		hotel.Street = *req.Street
+	}
+	if req.Landmark != nil {
+		hotel.Landmark = *req.Landmark
+	}
+	if req.Locality != nil {
+		hotel.Locality = *req.Locality
+	}
+	if req.City != nil {
+		hotel.City = *req.City
+	}
+	if req.State != nil {
+		hotel.State = *req.State
+	}
+	if req.Pincode != nil {
+		// Checked to be a number by the validator
+		hotel.Pincode, _ = strconv.Atoi(*req.Pincode)
+	}
+	if req.Latitude != nil && req.Longitude != nil {
+		hotel.Latitude = sql.NullFloat64{Float64: *req.Latitude, Valid: true}
+		hotel.Longitude = sql.NullFloat64{Float64: *req.Longitude, Valid: true}
+	}
+}
+
+func applyRoomTypeUpdate(roomType *store.RoomType, req *hotelsystem.UpdateRoomTypeRequest) {
+	if req.Name != nil {
+		roomType.Name = *req.Name
+	}
+	if req.Description != nil {
+		roomType.Description = *req.Description
+	}
+	if req.TotalRooms != nil {
+		roomType.TotalRooms = int(*req.TotalRooms)
+	}
+	if req.MaxOccupancy != nil {
+		roomType.MaxOccupancy = int(*req.MaxOccupancy)
+	}
+	if req.CostPerNight != nil {
+		roomType.CostPerNight = *req.CostPerNight
+	}
+}
+
+func (s *Service) setHotelStatus(hotelId int64, status constants.HotelStatus, now time.Time) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	// Locked so that no booking slips in between the check below and the status change
+	if _, err = s.getHotelForUpdateTx(tx, hotelId); err != nil {
+		return err
+	}
+	if status == constants.HOTEL_DELETED {
+		upcoming, err := s.storageService.CountUpcomingBookingsTx(tx, hotelId, now.Truncate(24*time.Hour))
+		if err != nil {
+			return err
+		}
+		if upcoming > 0 {
+			return errHotelHasUpcomingBookings
+		}
+	}
+	return s.storageService.UpdateHotelStatusTx(tx, hotelId, status)
+}
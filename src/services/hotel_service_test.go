@@ -0,0 +1,33 @@
+package services
+
+import (
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyHotelUpdate(t *testing.T) {
+	name, city, pincode := "Sea View", "Goa", "403001"
+	hotel := &store.Hotel{ID: 1, Name: "Sea Veiw", Description: "By the beach", City: "Panaji", Pincode: 400001}
+
+	applyHotelUpdate(hotel, &hotelsystem.UpdateHotelRequest{HotelID: 1, Name: &name, City: &city, Pincode: &pincode})
+
+	assert.Equal(t, "Sea View", hotel.Name)
+	assert.Equal(t, "Goa", hotel.City)
+	assert.Equal(t, 403001, hotel.Pincode)
+	assert.Equal(t, "By the beach", hotel.Description, "fields left out of the request are kept")
+}
+
+func TestApplyRoomTypeUpdate(t *testing.T) {
+	totalRooms, cost := int64(8), float32(99.5)
+	roomType := &store.RoomType{ID: 3, Name: "Deluxe", TotalRooms: 10, MaxOccupancy: 2, CostPerNight: 120}
+
+	applyRoomTypeUpdate(roomType, &hotelsystem.UpdateRoomTypeRequest{RoomTypeID: 3, TotalRooms: &totalRooms, CostPerNight: &cost})
+
+	assert.Equal(t, 8, roomType.TotalRooms)
+	assert.Equal(t, float32(99.5), roomType.CostPerNight)
+	assert.Equal(t, "Deluxe", roomType.Name)
+	assert.Equal(t, 2, roomType.MaxOccupancy)
+}
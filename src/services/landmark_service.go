@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+)
+
+var errLandmarkNotFound = errors.New("landmark not found")
+
+// AddLandmark adds a named place that guests can search for hotels around
+func (s *Service) AddLandmark(w http.ResponseWriter, r *http.Request) {
+	var addLandmarkRequest hotelsystem.AddLandmarkRequest
+	err := json.NewDecoder(r.Body).Decode(&addLandmarkRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateAddLandmarkRequest(&addLandmarkRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	landmarkId, err := s.storageService.CreateLandmark(&store.Landmark{
+		Name:      addLandmarkRequest.Name,
+		City:      addLandmarkRequest.City,
+		Latitude:  addLandmarkRequest.Latitude,
+		Longitude: addLandmarkRequest.Longitude,
+	})
+	if err != nil {
+		log.Println("Error adding landmark:", err)
+		http.Error(w, "Could not add landmark", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, &hotelsystem.AddLandmarkResponse{
+		Status:     "S",
+		Message:    "Landmark added successfully",
+		LandmarkID: landmarkId,
+	})
+}
+
+func (s *Service) GetLandmarks(w http.ResponseWriter, r *http.Request) {
+	var getLandmarksRequest hotelsystem.GetLandmarksRequest
+	err := json.NewDecoder(r.Body).Decode(&getLandmarksRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	landmarks, err := s.storageService.GetLandmarks(getLandmarksRequest.City)
+	if err != nil {
+		log.Println("Error getting landmarks:", err)
+		http.Error(w, "Could not get landmarks", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.LandmarksResponseSerializer(landmarks))
+}
+
+// resolveSearchCentre turns the landmark a search is near into its point, and fills in the default
+// radius of a search near a point
+func (s *Service) resolveSearchCentre(req *hotelsystem.GetHotelsListRequest) error {
+	if req.NearLandmark != "" {
+		landmark, err := s.storageService.GetLandmarkByName(req.NearLandmark, req.City)
+		if err != nil {
+			return err
+		}
+		if landmark == nil {
+			return errLandmarkNotFound
+		}
+		req.NearLatitude, req.NearLongitude = &landmark.Latitude, &landmark.Longitude
+	}
+	if req.NearLatitude != nil && req.RadiusKm == 0 {
+		req.RadiusKm = constants.DefaultSearchRadiusKm
+	}
+	return nil
+}
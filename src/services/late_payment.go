@@ -0,0 +1,112 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"log"
+	"time"
+)
+
+// latePayment is the outcome of recovering a payment that succeeded after its booking had lapsed
+type latePayment struct {
+	booking    *store.Booking
+	resolution constants.LatePaymentResolution
+	refund     *store.Refund // set when the payment is refunded
+}
+
+// recoverLatePaymentTx settles a payment that succeeded after its booking expired, failed or was
+// cancelled. The booking is reinstated if its rooms can be reserved again for the original dates;
+// otherwise the whole payment is refunded. Bookings the guest cancelled are always refunded.
+func (s *Service) recoverLatePaymentTx(tx *sql.Tx, booking *store.Booking, payment *store.Payment) (*latePayment, error) {
+	err := s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_SUCCESS)
+	if err != nil {
+		return nil, err
+	}
+
+	reinstated := false
+	if booking.Status != store.BOOKING_CANCELLED {
+		reinstated, err = s.reserveAgainTx(tx, booking, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	outcome := &latePayment{booking: booking, resolution: constants.LATE_PAYMENT_REINSTATED}
+	if reinstated {
+		err = s.storageService.UpdateBookingStatusTx(tx, int64(booking.BookingID), store.BOOKING_CONFIRMED)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		outcome.resolution = constants.LATE_PAYMENT_REFUNDED
+		outcome.refund, err = s.createRefundTx(tx, payment, 0, fmt.Sprintf("payment received after booking %s", booking.Status))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.storageService.SetBookingLatePaymentResolutionTx(tx, int64(booking.BookingID), outcome.resolution)
+	if err != nil {
+		return nil, err
+	}
+	err = s.storageService.SetPaymentLatePaymentResolutionTx(tx, payment.ID, outcome.resolution)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Payment %s arrived after booking %d was %s: %s", payment.ID, booking.BookingID, booking.Status, outcome.resolution)
+	return outcome, nil
+}
+
+// reserveAgainTx reserves the rooms of a lapsed booking for its original dates, provided the stay has
+// not started yet, the hotel is still on sale and the rooms are still free
+func (s *Service) reserveAgainTx(tx *sql.Tx, booking *store.Booking, now time.Time) (bool, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, booking.CheckInDate.Location())
+	if booking.CheckInDate.Before(today) {
+		return false, nil
+	}
+
+	// Lock the hotel the same way BookHotel does before touching its inventory
+	hotel, err := s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID))
+	if err != nil {
+		return false, err
+	}
+	if hotel.Status != constants.HOTEL_ACTIVE {
+		return false, nil
+	}
+	roomType, err := s.storageService.GetRoomTypeByIdTx(tx, int64(booking.HotelID), int64(booking.RoomTypeID))
+	if err != nil {
+		return false, err
+	}
+	if roomType == nil {
+		return false, nil
+	}
+	maxRoomsSold, err := s.storageService.GetMaxRoomsSoldTx(tx, int64(booking.RoomTypeID), booking.CheckInDate, booking.CheckOutDate)
+	if err != nil {
+		return false, err
+	}
+	if roomType.TotalRooms-maxRoomsSold < booking.NumberOfRooms {
+		return false, nil
+	}
+	err = s.storageService.ReserveHotelInventoryTx(tx, int64(booking.HotelID), int64(booking.RoomTypeID), booking.CheckInDate, booking.CheckOutDate, booking.NumberOfRooms)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// followUpLatePayment sends the refund of a late payment to the gateway and tells the guest what
+// happened to their booking. It runs once the recovery is committed.
+func (s *Service) followUpLatePayment(lp *latePayment) {
+	subject := "Your booking is confirmed"
+	body := fmt.Sprintf("Your payment for booking %d arrived after the booking had lapsed, but the rooms were still available and your booking is now confirmed.", lp.booking.BookingID)
+	if lp.resolution == constants.LATE_PAYMENT_REFUNDED {
+		s.attemptRefund(lp.refund)
+		subject = "Your payment is being refunded"
+		body = fmt.Sprintf("Your payment for booking %d arrived after the booking had lapsed and the rooms are no longer available. The full amount is being refunded.", lp.booking.BookingID)
+	}
+	if err := s.notifier.Notify(lp.booking.UserID, subject, body); err != nil {
+		log.Printf("Failed to notify user %d about booking %d: %v", lp.booking.UserID, lp.booking.BookingID, err)
+	}
+}
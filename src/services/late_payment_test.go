@@ -0,0 +1,201 @@
+package services
+
+import (
+	"database/sql"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserveAgainTx(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	booking := func(checkIn time.Time) *store.Booking {
+		return &store.Booking{
+			BookingID:     1,
+			HotelID:       3,
+			RoomTypeID:    5,
+			NumberOfRooms: 2,
+			CheckInDate:   checkIn,
+			CheckOutDate:  checkIn.AddDate(0, 0, 2),
+			Status:        store.BOOKING_EXPIRED,
+		}
+	}
+	roomType := &store.RoomType{ID: 5, HotelID: 3, TotalRooms: 10}
+
+	tests := []struct {
+		name       string
+		checkIn    time.Time
+		setupMocks func(m *mocks.MockStorageService, b *store.Booking)
+		expected   bool
+	}{
+		{
+			name:    "rooms still free",
+			checkIn: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_ACTIVE}, nil)
+				m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(roomType, nil)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), b.CheckInDate, b.CheckOutDate).Return(8, nil)
+				m.EXPECT().ReserveHotelInventoryTx(gomock.Any(), int64(3), int64(5), b.CheckInDate, b.CheckOutDate, 2).Return(nil)
+			},
+			expected: true,
+		},
+		{
+			name:    "stay starting today",
+			checkIn: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_ACTIVE}, nil)
+				m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(roomType, nil)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), b.CheckInDate, b.CheckOutDate).Return(0, nil)
+				m.EXPECT().ReserveHotelInventoryTx(gomock.Any(), int64(3), int64(5), b.CheckInDate, b.CheckOutDate, 2).Return(nil)
+			},
+			expected: true,
+		},
+		{
+			name:    "rooms sold meanwhile",
+			checkIn: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_ACTIVE}, nil)
+				m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(roomType, nil)
+				m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), b.CheckInDate, b.CheckOutDate).Return(9, nil)
+			},
+			expected: false,
+		},
+		{
+			name:    "room type removed",
+			checkIn: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_ACTIVE}, nil)
+				m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(nil, nil)
+			},
+			expected: false,
+		},
+		{
+			name:    "hotel taken off sale",
+			checkIn: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_INACTIVE}, nil)
+			},
+			expected: false,
+		},
+		{
+			name:    "hotel deleted",
+			checkIn: time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {
+				m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_DELETED}, nil)
+			},
+			expected: false,
+		},
+		{
+			name:       "stay already started",
+			checkIn:    time.Date(2025, 3, 9, 0, 0, 0, 0, time.UTC),
+			setupMocks: func(m *mocks.MockStorageService, b *store.Booking) {},
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			b := booking(tt.checkIn)
+			tt.setupMocks(mockStore, b)
+
+			reserved, err := service.reserveAgainTx(nil, b, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, reserved)
+		})
+	}
+}
+
+func TestRecoverLatePaymentTx(t *testing.T) {
+	// recoverLatePaymentTx reserves against the current date, so the stay is kept in the future
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 5)
+	checkOut := checkIn.AddDate(0, 0, 2)
+	roomType := &store.RoomType{ID: 5, HotelID: 3, TotalRooms: 10}
+
+	expectReserveAttempt := func(m *mocks.MockStorageService, roomsSold int) {
+		m.EXPECT().GetHotelForUpdate(gomock.Any(), int64(3)).Return(store.Hotel{ID: 3, Status: constants.HOTEL_ACTIVE}, nil)
+		m.EXPECT().GetRoomTypeByIdTx(gomock.Any(), int64(3), int64(5)).Return(roomType, nil)
+		m.EXPECT().GetMaxRoomsSoldTx(gomock.Any(), int64(5), checkIn, checkOut).Return(roomsSold, nil)
+	}
+	expectRefund := func(m *mocks.MockStorageService) {
+		m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "p1", refundableStatuses).Return(float32(0), nil)
+		m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, refund *store.Refund) error {
+			assert.Equal(t, float32(400), refund.Amount)
+			return nil
+		})
+	}
+
+	tests := []struct {
+		name               string
+		bookingStatus      store.BookingStatus
+		setupMocks         func(m *mocks.MockStorageService)
+		expectedResolution constants.LatePaymentResolution
+	}{
+		{
+			name:          "expired booking whose rooms are free is reinstated",
+			bookingStatus: store.BOOKING_EXPIRED,
+			setupMocks: func(m *mocks.MockStorageService) {
+				expectReserveAttempt(m, 3)
+				m.EXPECT().ReserveHotelInventoryTx(gomock.Any(), int64(3), int64(5), checkIn, checkOut, 2).Return(nil)
+				m.EXPECT().UpdateBookingStatusTx(gomock.Any(), int64(1), store.BOOKING_CONFIRMED).Return(nil)
+			},
+			expectedResolution: constants.LATE_PAYMENT_REINSTATED,
+		},
+		{
+			name:          "failed booking whose rooms were sold is refunded",
+			bookingStatus: store.BOOKING_FAILED,
+			setupMocks: func(m *mocks.MockStorageService) {
+				expectReserveAttempt(m, 9)
+				expectRefund(m)
+			},
+			expectedResolution: constants.LATE_PAYMENT_REFUNDED,
+		},
+		{
+			name:          "cancelled booking is refunded without reserving",
+			bookingStatus: store.BOOKING_CANCELLED,
+			setupMocks: func(m *mocks.MockStorageService) {
+				expectRefund(m)
+			},
+			expectedResolution: constants.LATE_PAYMENT_REFUNDED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			mockStore.EXPECT().UpdatePaymentStatusTx(gomock.Any(), "p1", constants.PAYMENT_SUCCESS).Return(nil)
+			tt.setupMocks(mockStore)
+			mockStore.EXPECT().SetBookingLatePaymentResolutionTx(gomock.Any(), int64(1), tt.expectedResolution).Return(nil)
+			mockStore.EXPECT().SetPaymentLatePaymentResolutionTx(gomock.Any(), "p1", tt.expectedResolution).Return(nil)
+
+			booking := &store.Booking{
+				BookingID:     1,
+				HotelID:       3,
+				RoomTypeID:    5,
+				UserID:        7,
+				NumberOfRooms: 2,
+				CheckInDate:   checkIn,
+				CheckOutDate:  checkOut,
+				Status:        tt.bookingStatus,
+			}
+			payment := &store.Payment{ID: "p1", BookingID: 1, Amount: 400, Status: constants.PAYMENT_PENDING}
+			outcome, err := service.recoverLatePaymentTx(nil, booking, payment)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResolution, outcome.resolution)
+			assert.Equal(t, tt.expectedResolution == constants.LATE_PAYMENT_REFUNDED, outcome.refund != nil)
+		})
+	}
+}
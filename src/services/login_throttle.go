@@ -0,0 +1,179 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/pb"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// loginLimit is how many failed logins a key is allowed before it is slowed down and locked out
+type loginLimit struct {
+	freeAttempts int
+	lockoutAfter int
+}
+
+var (
+	usernameLoginLimit = loginLimit{freeAttempts: constants.LoginFreeAttempts, lockoutAfter: constants.LoginLockoutThreshold}
+	ipLoginLimit       = loginLimit{freeAttempts: constants.LoginIPFreeAttempts, lockoutAfter: constants.LoginIPLockoutThreshold}
+)
+
+// backoff is how long a key must wait before its next login attempt after the given number of
+// failures, and whether that wait is a lockout
+func (l loginLimit) backoff(failures int) (time.Duration, bool) {
+	if failures >= l.lockoutAfter {
+		return constants.LoginLockoutDuration, true
+	}
+	if failures <= l.freeAttempts {
+		return 0, false
+	}
+	delay := constants.LoginBaseDelay
+	for i := l.freeAttempts + 1; i < failures && delay < constants.LoginMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, constants.LoginMaxDelay), false
+}
+
+// dummyPasswordHash is checked against when the username does not exist, so that a login for an
+// unknown user takes as long as one with a wrong password
+var dummyPasswordHash, _ = utils.HashPassword("not-a-real-password")
+
+func usernameLoginKey(username string) string {
+	return "user:" + username
+}
+
+func ipLoginKey(ip string) string {
+	return "ip:" + ip
+}
+
+// clientIP is the address the request came from. Forwarding headers are not trusted, as they are set
+// by the client unless a proxy in front of the server overwrites them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loginRetryAfter returns how long the caller must wait before trying to log in as the username from
+// the IP, or 0 if they may try now
+func (s *Service) loginRetryAfter(username string, ip string, now time.Time) (time.Duration, error) {
+	throttles, err := s.storageService.GetLoginThrottles([]string{usernameLoginKey(username), ipLoginKey(ip)})
+	if err != nil {
+		return 0, err
+	}
+	var wait time.Duration
+	for _, throttle := range throttles {
+		if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(now) {
+			wait = max(wait, throttle.LockedUntil.Time.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// recordLoginFailure counts a failed login against the username and the IP and makes them wait
+// before their next attempt. Lockouts are recorded in the audit log.
+func (s *Service) recordLoginFailure(username string, ip string, now time.Time) {
+	keys := []struct {
+		key      string
+		limit    loginLimit
+		username string
+	}{
+		{usernameLoginKey(username), usernameLoginLimit, username},
+		{ipLoginKey(ip), ipLoginLimit, ""},
+	}
+	for _, k := range keys {
+		failures, err := s.storageService.RecordLoginFailure(k.key, now, now.Add(-constants.LoginFailureWindow))
+		if err != nil {
+			log.Printf("Error recording login failure for %s: %v", k.key, err)
+			continue
+		}
+		delay, lockout := k.limit.backoff(failures)
+		if delay == 0 {
+			continue
+		}
+		if err = s.storageService.LockLoginKey(k.key, now.Add(delay)); err != nil {
+			log.Printf("Error locking %s: %v", k.key, err)
+			continue
+		}
+		if lockout {
+			log.Printf("Login locked for %s after %d failed attempts", k.key, failures)
+			detail := fmt.Sprintf("%s locked for %s after %d failed login attempts", k.key, delay, failures)
+			s.recordAuthAuditEvent(store.NewAuthAuditEvent(utils.NewUuid(), constants.AUDIT_LOGIN_LOCKOUT, k.username, ip, detail))
+		}
+	}
+}
+
+func (s *Service) recordAuthAuditEvent(event *store.AuthAuditEvent) {
+	if err := s.storageService.CreateAuthAuditEvent(event); err != nil {
+		log.Printf("Error recording %s audit event: %v", event.Type, err)
+	}
+}
+
+// UnlockAccount lets an admin lift the lockout of a username and forget its failed logins
+func (s *Service) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var unlockAccountRequest pb.UnlockAccountRequest
+	err := json.NewDecoder(r.Body).Decode(&unlockAccountRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateUnlockAccountRequest(&unlockAccountRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cleared, err := s.storageService.ClearLoginThrottle(usernameLoginKey(unlockAccountRequest.Username))
+	if err != nil {
+		log.Println("Error unlocking account:", err)
+		http.Error(w, "Failed to unlock account", http.StatusInternalServerError)
+		return
+	}
+	if !cleared {
+		http.Error(w, "No failed logins recorded for the username", http.StatusNotFound)
+		return
+	}
+
+	event := store.NewAuthAuditEvent(utils.NewUuid(), constants.AUDIT_ACCOUNT_UNLOCK, unlockAccountRequest.Username, clientIP(r), "unlocked by an admin")
+	event.ActorUserID = sql.NullInt64{Int64: int64(r.Context().Value("user_id").(int)), Valid: true}
+	s.recordAuthAuditEvent(event)
+	sendSuccessResponse(w, "Account unlocked successfully")
+}
+
+func (s *Service) GetAuthAuditEvents(w http.ResponseWriter, r *http.Request) {
+	var getAuthAuditEventsRequest pb.GetAuthAuditEventsRequest
+	err := json.NewDecoder(r.Body).Decode(&getAuthAuditEventsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetAuthAuditEventsRequest(&getAuthAuditEventsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.storageService.GetAuthAuditEvents(getAuthAuditEventsRequest.Username, getAuthAuditEventsRequest.Limit, getAuthAuditEventsRequest.Offset)
+	if err != nil {
+		log.Println("Error getting audit events:", err)
+		http.Error(w, "Failed to get audit events", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.AuthAuditEventsResponseSerializer(events))
+}
+
+// sendTooManyLoginAttempts answers a login attempt made while the username or IP has to wait
+func sendTooManyLoginAttempts(w http.ResponseWriter, wait time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())+1))
+	http.Error(w, "Too many failed login attempts, try again later", http.StatusTooManyRequests)
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"hotel-system/src/constants"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginLimitBackoff(t *testing.T) {
+	limit := loginLimit{freeAttempts: 3, lockoutAfter: 10}
+	tests := []struct {
+		name        string
+		failures    int
+		wantDelay   time.Duration
+		wantLockout bool
+	}{
+		{"free attempt", 1, 0, false},
+		{"last free attempt", 3, 0, false},
+		{"first delayed attempt", 4, constants.LoginBaseDelay, false},
+		{"delay doubles", 6, 4 * constants.LoginBaseDelay, false},
+		{"delay is capped", 9, constants.LoginMaxDelay, false},
+		{"lockout", 10, constants.LoginLockoutDuration, true},
+		{"still locked out", 25, constants.LoginLockoutDuration, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, lockout := limit.backoff(tt.failures)
+			assert.Equal(t, tt.wantDelay, delay)
+			assert.Equal(t, tt.wantLockout, lockout)
+		})
+	}
+}
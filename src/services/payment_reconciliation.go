@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/store"
+	"log"
+	"time"
+)
+
+// ReconcilePendingPayments asks the gateway for the state of every payment that has been pending for
+// longer than a webhook normally takes, and applies checkouts that were settled without us hearing
+// about it. It is run by the payment scheduler.
+func (s *Service) ReconcilePendingPayments() error {
+	pendingPayments, err := s.storageService.GetPendingPaymentsCreatedBefore(time.Now().Add(-constants.PaymentReconcileAfter))
+	if err != nil {
+		return err
+	}
+	for _, payment := range pendingPayments {
+		if _, err = s.reconcilePayment(payment); err != nil {
+			log.Printf("Failed to reconcile payment %s: %v", payment.ID, err)
+		}
+	}
+	return nil
+}
+
+// ReconcileBooking reconciles the payment of a pending booking and reports whether the gateway had
+// settled it. The booking scheduler calls it before expiring a booking, so a paid booking whose
+// webhook got lost is confirmed instead.
+func (s *Service) ReconcileBooking(bookingId int64) (bool, error) {
+	payment, err := s.storageService.GetPaymentByBookingId(bookingId)
+	if err != nil {
+		return false, err
+	}
+	if payment == nil || payment.Status != constants.PAYMENT_PENDING {
+		return false, nil
+	}
+	return s.reconcilePayment(payment)
+}
+
+// reconcilePayment applies the gateway's state of a pending payment's checkout through the same
+// transitions as its webhook would. It returns false if the guest has not finished the checkout yet.
+func (s *Service) reconcilePayment(payment *store.Payment) (bool, error) {
+	checkout, err := s.paymentGateway.GetCheckout(payment.CheckoutSessionId)
+	if err != nil {
+		return false, fmt.Errorf("get checkout %s: %w", payment.CheckoutSessionId, err)
+	}
+
+	event := &payments.WebhookEvent{Type: payments.EventCheckoutFailed, Checkout: checkout}
+	switch checkout.Status {
+	case payments.CHECKOUT_OPEN:
+		return false, nil
+	case payments.CHECKOUT_PAID:
+		event.Type = payments.EventCheckoutCompleted
+	}
+	// Our own ids are what the transitions key on, whatever the gateway echoed back
+	checkout.Metadata = map[string]string{
+		constants.BookingIdField: fmt.Sprintf("%d", payment.BookingID),
+		"payment_id":             payment.ID,
+	}
+
+	log.Printf("Reconciling payment %s of booking %d: pending here, %s at the gateway", payment.ID, payment.BookingID, checkout.Status)
+	if err = s.processCheckoutEvent(event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
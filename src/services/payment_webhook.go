@@ -2,23 +2,20 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"hotel-system/src/constants"
 	"hotel-system/src/errorcodes"
+	"hotel-system/src/payments"
 	"hotel-system/src/store"
 	"hotel-system/src/utils"
 	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
 	"strconv"
-
-	"github.com/stripe/stripe-go/v82"
-	"github.com/stripe/stripe-go/v82/webhook"
 )
 
-// if anything inside here fails, then refund
+// PaymentWebhookHandler verifies a gateway notification and stores it in the webhook inbox. The event
+// is acknowledged right away and applied asynchronously by the inbox worker (see webhook_inbox.go).
 func (s *Service) PaymentWebhookHandler(w http.ResponseWriter, req *http.Request) {
 	req.Body = http.MaxBytesReader(w, req.Body, constants.MaxBodyBytes)
 
@@ -29,198 +26,209 @@ func (s *Service) PaymentWebhookHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	// Pass the request body and Stripe-Signature header to ConstructEvent, along with the webhook signing key
-	// Use the secret provided by Stripe CLI for local testing or your webhook endpoint's secret.
-	webhookSigningSecret := os.Getenv("STRIPE_WEBHOOK_SECRET")
-	if webhookSigningSecret == "" {
-		log.Println("STRIPE_WEBHOOK_SECRET not set in environment")
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	event, err := webhook.ConstructEvent(body, req.Header.Get("Stripe-Signature"), webhookSigningSecret)
-
+	event, err := s.paymentGateway.VerifyWebhook(body, req.Header)
 	if err != nil {
 		fmt.Printf("Error verifying webhook signature: %v\n", err)
 		w.WriteHeader(http.StatusBadRequest) // Return a 400 error on a bad signature
 		return
 	}
+	fmt.Println("Webhook received for event type:", event.Type)
 
-	var checkoutSession stripe.CheckoutSession
-	err = json.Unmarshal(event.Data.Raw, &checkoutSession)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing webhook JSON: %v\n", err)
-		w.WriteHeader(http.StatusBadRequest)
+	// Acknowledge as soon as the event is stored; a failure here makes the gateway deliver it again
+	if err = s.receiveWebhookEvent(event, body); err != nil {
+		log.Println("Error storing webhook event:", err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	w.WriteHeader(http.StatusOK)
+}
 
-	fmt.Println("Webhook received for event type:", event.Type)
+// applyWebhookEvent applies a gateway event to the bookings, payments and refunds it concerns. It is
+// called by the webhook inbox worker and must be safe to repeat.
+func (s *Service) applyWebhookEvent(event *payments.WebhookEvent) error {
+	switch event.Type {
+	case payments.EventCheckoutCompleted, payments.EventCheckoutFailed:
+		return s.processCheckoutEvent(event)
+	case payments.EventRefundUpdated:
+		return s.processRefundEvent(event)
+	default:
+		return nil
+	}
+}
+
+// processCheckoutEvent confirms or fails the booking a checkout was paid for. Payments that arrive
+// after their booking lapsed are followed up once the recovery is committed.
+func (s *Service) processCheckoutEvent(event *payments.WebhookEvent) error {
+	lp, err := s.applyCheckoutEvent(event)
+	if err != nil {
+		return err
+	}
+	if lp != nil {
+		s.followUpLatePayment(lp)
+	}
+	return nil
+}
+
+func (s *Service) applyCheckoutEvent(event *payments.WebhookEvent) (lp *latePayment, err error) {
+	checkoutSession := event.Checkout
+	if checkoutSession == nil {
+		return nil, fmt.Errorf("%w: checkout missing", errInvalidWebhookEvent)
+	}
 	fmt.Println("Checkout session metadata: ", checkoutSession.ID)
 
 	metadataMap := checkoutSession.Metadata
 	if metadataMap == nil || metadataMap[constants.BookingIdField] == "" {
-		log.Println("Metadata is missing or booking_id is not present")
-		http.Error(w, errorcodes.ErrMetadataNotFound, http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("%w: %s", errInvalidWebhookEvent, errorcodes.ErrMetadataNotFound)
 	}
 
 	bookingId, err := strconv.Atoi(metadataMap[constants.BookingIdField])
 	if err != nil {
-		log.Println("Error converting booking_id to int:", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("%w: booking_id: %v", errInvalidWebhookEvent, err)
 	}
 
 	paymentId, ok := metadataMap["payment_id"]
 	if !ok {
-		log.Println("payment_id not found in metadata")
-		http.Error(w, errorcodes.ErrMetadataNotFound, http.StatusBadRequest)
-		return
+		return nil, fmt.Errorf("%w: payment_id: %s", errInvalidWebhookEvent, errorcodes.ErrMetadataNotFound)
 	}
 
-	ctx := context.Background()
-	tx, err := s.storageService.BeginTransaction(ctx)
+	tx, err := s.storageService.BeginTransaction(context.Background())
 	if err != nil {
-		log.Println("Failed to start transaction")
-		return
+		return nil, err
 	}
 	defer utils.RollbackOrCommitTransaction(tx, &err)
 
+	// A missing booking is retried: the booking transaction may not have committed yet
 	booking, err := s.storageService.GetBookingByIdTx(tx, int64(bookingId))
 	if err != nil {
-		log.Println("Error getting booking entry:", err)
-		http.Error(w, errorcodes.ErrBookingNotFoundByID, http.StatusNotFound)
-		return
+		return nil, fmt.Errorf("%s: %w", errorcodes.ErrBookingNotFoundByID, err)
 	}
 
-	if booking.Status == store.BOOKING_FAILED || booking.Status == store.BOOKING_EXPIRED {
-		log.Println("Booking already expired or failed")
-		w.WriteHeader(http.StatusOK)
-		return
+	payment, err := s.storageService.GetPaymentByIdTx(tx, paymentId)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errorcodes.ErrPaymentNotFoundByOID, err)
 	}
 
-	if booking.Status == store.BOOKING_CONFIRMED {
-		log.Println("Booking already confirmed, no further action required")
-		w.WriteHeader(http.StatusOK)
-		return
+	// A top-up paid for a booking modification settles the modification, not the booking
+	modification, err := s.storageService.GetBookingModificationByPaymentIdTx(tx, paymentId)
+	if err != nil {
+		return nil, err
+	}
+	if modification != nil {
+		return nil, s.applyModificationCheckoutTx(tx, event.Type, &booking, modification, payment)
 	}
 
-	payment, err := s.storageService.GetPaymentByIdTx(tx, paymentId)
-	if err != nil {
-		log.Println("Error getting payment entry:", err)
-		http.Error(w, errorcodes.ErrPaymentNotFoundByOID, http.StatusNotFound)
-		return
+	if booking.Status == store.BOOKING_FAILED || booking.Status == store.BOOKING_EXPIRED || booking.Status == store.BOOKING_CANCELLED {
+		if event.Type == payments.EventCheckoutCompleted && payment.Status != constants.PAYMENT_SUCCESS && payment.Status != constants.PAYMENT_REFUNDED {
+			return s.recoverLatePaymentTx(tx, &booking, payment)
+		}
+		log.Println("Booking already expired, failed or cancelled")
+		// The booking let go of its rooms already; only the payment is left to settle
+		if event.Type == payments.EventCheckoutFailed && payment.Status == constants.PAYMENT_PENDING {
+			err = s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_FAILED)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", errorcodes.ErrPaymentStatusUpdate, err)
+			}
+		}
+		return nil, nil
+	}
+
+	if booking.Status == store.BOOKING_CONFIRMED {
+		log.Println("Booking already confirmed, no further action required")
+		return nil, nil
 	}
 
 	if payment.Status == constants.PAYMENT_SUCCESS || payment.Status == constants.PAYMENT_FAILED {
 		log.Println("Payment already processed, no further action required")
-		w.WriteHeader(http.StatusOK)
-		return
+		return nil, nil
 	}
 
-	//paymentStatus, _ := constants.StripePaymentStatusToPaymentStatusMap[string(event.Type)]
-	paymentStatus := event.Type
-
-	switch paymentStatus {
-	case stripe.EventTypeCheckoutSessionCompleted, stripe.EventTypeCheckoutSessionAsyncPaymentSucceeded:
-		// Update booking status to confirmed
-		booking.Status = store.BOOKING_CONFIRMED
-		err = s.storageService.UpdateBookingStatusTx(tx, int64(bookingId), booking.Status)
+	switch event.Type {
+	case payments.EventCheckoutCompleted:
+		err = s.storageService.UpdateBookingStatusTx(tx, int64(bookingId), store.BOOKING_CONFIRMED)
 		if err != nil {
-			log.Println("Error updating booking status:", err)
-			http.Error(w, "Failed to update booking", http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrBookingUpdateFailed, err)
 		}
 
 		err = s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_SUCCESS)
 		if err != nil {
-			log.Println("Error updating payment status:", err)
-			http.Error(w, "Failed to update payment status", http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrPaymentStatusUpdate, err)
 		}
-	case stripe.EventTypeCheckoutSessionAsyncPaymentFailed:
-		var hotel store.Hotel
-		hotel, err = s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID))
+	case payments.EventCheckoutFailed:
+		_, err = s.storageService.GetHotelForUpdate(tx, int64(booking.HotelID))
 		if err != nil {
-			log.Println("Error getting hotel entry:", err)
-			http.Error(w, "Hotel not found", http.StatusNotFound)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrHotelNotFoundByID, err)
 		}
 
-		err = s.storageService.IncrementHotelRoomsTx(tx, int64(hotel.ID), booking.NumberOfRooms)
+		err = s.storageService.ReleaseHotelInventoryTx(tx, int64(booking.RoomTypeID), booking.CheckInDate, booking.CheckOutDate, booking.NumberOfRooms)
 		if err != nil {
-			log.Println("Error updating hotel rooms:", err)
-			http.Error(w, errorcodes.ErrUpdateHotelRooms, http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrUpdateHotelRooms, err)
 		}
 
 		err = s.storageService.UpdateBookingStatusTx(tx, int64(bookingId), store.BOOKING_FAILED)
 		if err != nil {
-			log.Println("Error updating booking status:", err)
-			http.Error(w, errorcodes.ErrBookingUpdateFailed, http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrBookingUpdateFailed, err)
 		}
 
 		err = s.storageService.UpdatePaymentStatusTx(tx, payment.ID, constants.PAYMENT_FAILED)
 		if err != nil {
-			log.Println("Error updating payment status:", err)
-			http.Error(w, errorcodes.ErrPaymentStatusUpdate, http.StatusInternalServerError)
-			return
+			return nil, fmt.Errorf("%s: %w", errorcodes.ErrPaymentStatusUpdate, err)
 		}
 	}
+	return nil, nil
 }
 
 /*
 
 Above webhook workflow
 
-- Receive HTTP POST webhook and JSON-decode payload; if decode fails, return 400.
-- Verify request signature; if invalid, return 400/401.
+- Receive HTTP POST webhook and read the payload; if reading fails, return 400.
+- Verify request signature through the payment gateway; if invalid, return 400.
+- Store the event in the webhook inbox keyed by its gateway event id and return 200; redeliveries are dropped.
+  If storing fails, return 500 so the gateway delivers it again.
 
-mark payment as  processing
+The inbox worker then applies the event (processCheckoutEvent):
 
--
-- Extract booking_id from payload metadata; if missing/invalid, return 400.
+- Extract booking_id from payload metadata; if missing/invalid, dead-letter the event.
 - Start a database transaction for locking and atomic updates.
-- Fetch booking by booking_id; if not found, return 404.
-- If booking already CONFIRMED or FAILED/EXPIRED, return 200 (idempotent).
-- Look up payment by checkout session ID from payload; if missing/not found, return 404.
-- if payment already failed or success then return 200 (idempotent)
-- If payment status is SUCCESS:
+- Fetch booking by booking_id; if not found, retry later.
+- Look up payment by payment_id from metadata; if not found, retry later.
+- If the payment is the top-up of a booking modification (applyModificationCheckoutTx):
+    - A pending modification is applied if the checkout completed, and given up if it failed.
+    - A top-up completed after its modification was given up is refunded in full.
+- If booking already FAILED/EXPIRED/CANCELLED:
+    - If the checkout completed, the guest paid for a booking that let go of its rooms (recoverLatePaymentTx):
+      reserve the rooms again and confirm the booking, or refund the payment in full; record the outcome
+      on booking and payment, then notify the guest.
+    - If the checkout failed, only fail a still pending payment.
+- If booking already CONFIRMED, do nothing (idempotent).
+- if payment already failed or success then do nothing (idempotent)
+- If the checkout completed:
     - Set booking to CONFIRMED and payment to SUCCESS.
 
-- If payment status is FAILED:
-    - Lock hotel, return reserved rooms to inventory, set booking to FAILED, set payment to FAILED.
-
-- Commit on success; on any failure, rollback and return appropriate 4xx/5xx.
-- For any other payment status, make no state changes.
-
-
+- If the checkout failed:
+    - Lock hotel, return reserved nights to the inventory calendar, set booking to FAILED, set payment to FAILED.
 
+- Commit on success; on any failure, rollback and retry with backoff until the event is dead-lettered.
+- For any other event type, make no state changes.
 
+Refund events go to processRefundEvent, which updates the refunds table through the refund processor.
+*/
 
+// processRefundEvent applies a refund status change reported by the gateway to the refund it belongs to.
+// Refunds issued outside this system (e.g. from the Stripe dashboard) carry no refund id and are ignored.
+func (s *Service) processRefundEvent(event *payments.WebhookEvent) error {
+	refundEvent := event.Refund
+	if refundEvent == nil {
+		return fmt.Errorf("%w: refund missing", errInvalidWebhookEvent)
+	}
+	if refundEvent.RefundID == "" {
+		log.Println("Refund webhook without refund_id metadata, ignoring:", refundEvent.GatewayRefundID)
+		return nil
+	}
 
-Refunds query
-
-CREATE TABLE refunds (
-    id SERIAL PRIMARY KEY,
-
-    payment_id VARCHAR NOT NULL,        -- Razorpay payment ID
-    booking_id INTEGER NOT NULL,        -- Your internal booking ID
-
-    refund_id VARCHAR,                  -- Razorpay refund ID (after successful API call)
-    refund_status VARCHAR NOT NULL,     -- INITIATED / PROCESSED / FAILED
-    refund_reason TEXT,                 -- Optional reason (e.g., overbooking, system error)
-
-    amount INTEGER NOT NULL,            -- Amount to refund (in paise)
-
-    attempts INTEGER DEFAULT 0,         -- Number of refund attempts
-    last_attempt_at TIMESTAMP,          -- Last refund attempt time
-
-    created_at TIMESTAMP DEFAULT NOW(),
-    updated_at TIMESTAMP DEFAULT NOW(),
-
-    CONSTRAINT fk_refund_payment FOREIGN KEY (payment_id) REFERENCES payments(payment_id),
-    CONSTRAINT fk_refund_booking FOREIGN KEY (booking_id) REFERENCES bookings(booking_id)
-);
-
-*/
+	err := s.refundProcessor.UpdateFromGateway(refundEvent.RefundID, refundEvent.GatewayRefundID, refundEvent.Status, refundEvent.FailureReason)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errorcodes.ErrRefundUpdateFailed, err)
+	}
+	return nil
+}
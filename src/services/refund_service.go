@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+)
+
+var (
+	errPaymentNotRefundable = errors.New("booking has no successful payment to refund")
+	errRefundExceedsPayment = errors.New("refund amount exceeds the amount left to refund")
+)
+
+// refundableStatuses are the refund statuses that count against the amount left to refund on a payment
+var refundableStatuses = []constants.RefundStatus{constants.REFUND_PENDING, constants.REFUND_PROCESSING, constants.REFUND_SUCCESS}
+
+func (s *Service) RequestRefund(w http.ResponseWriter, r *http.Request) {
+	var requestRefundRequest hotelsystem.RequestRefundRequest
+	err := json.NewDecoder(r.Body).Decode(&requestRefundRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateRequestRefundRequest(&requestRefundRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Guests get their refunds by cancelling; other refunds are granted by the hotel's staff
+	booking, err := s.storageService.GetBookingById(requestRefundRequest.BookingID)
+	if err != nil {
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	canManage, err := s.canManageHotel(r, int64(booking.HotelID))
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Failed to request refund", http.StatusInternalServerError)
+		return
+	}
+	if !canManage {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	refunds, err := s.requestRefund(requestRefundRequest.BookingID, requestRefundRequest.Amount, requestRefundRequest.Reason)
+	switch {
+	case errors.Is(err, errBookingNotFound):
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	case errors.Is(err, errPaymentNotRefundable), errors.Is(err, errRefundExceedsPayment):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Println("Error requesting refund:", err)
+		http.Error(w, "Failed to request refund", http.StatusInternalServerError)
+		return
+	}
+	for i, refund := range refunds {
+		refunds[i] = s.attemptRefund(refund)
+	}
+	sendJsonResponse(w, serializers.RefundsResponseSerializer(refunds))
+}
+
+func (s *Service) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	var getRefundsRequest hotelsystem.GetRefundsRequest
+	err := json.NewDecoder(r.Body).Decode(&getRefundsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetRefundsRequest(&getRefundsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_, err = s.getVisibleBooking(r, getRefundsRequest.BookingID, "")
+	if errors.Is(err, errBookingNotFound) {
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error getting booking:", err)
+		http.Error(w, "Failed to get refunds", http.StatusInternalServerError)
+		return
+	}
+
+	refunds, err := s.storageService.GetRefundsByBookingId(getRefundsRequest.BookingID)
+	if err != nil {
+		log.Println("Error getting refunds:", err)
+		http.Error(w, "Failed to get refunds", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.RefundsResponseSerializer(refunds))
+}
+
+// requestRefund records pending refunds of an amount of a booking across its successful payments,
+// newest first, so that modification top-ups can be refunded as well as the original payment. An amount
+// of 0 refunds everything not refunded yet.
+func (s *Service) requestRefund(bookingId int64, amount float32, reason string) (refunds []*store.Refund, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	bookingPayments, err := s.storageService.GetPaymentsByBookingIdTx(tx, bookingId)
+	if err != nil {
+		return nil, err
+	}
+	if len(bookingPayments) == 0 {
+		return nil, errBookingNotFound
+	}
+	paid := false
+	var remaining float32
+	for _, payment := range bookingPayments {
+		if payment.Status != constants.PAYMENT_SUCCESS {
+			continue
+		}
+		paid = true
+		refunded, err := s.storageService.SumRefundsByPaymentIdTx(tx, payment.ID, refundableStatuses)
+		if err != nil {
+			return nil, err
+		}
+		remaining += payment.Amount - refunded
+	}
+	if !paid {
+		return nil, errPaymentNotRefundable
+	}
+	remaining = roundToCents(remaining)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return nil, errRefundExceedsPayment
+	}
+	return s.refundAcrossPaymentsTx(tx, bookingId, amount, reason)
+}
+
+// createRefundTx creates a pending refund of the payment, limited to the part of it not refunded or
+// being refunded already. An amount of 0 refunds all of that part.
+func (s *Service) createRefundTx(tx *sql.Tx, payment *store.Payment, amount float32, reason string) (*store.Refund, error) {
+	refunded, err := s.storageService.SumRefundsByPaymentIdTx(tx, payment.ID, refundableStatuses)
+	if err != nil {
+		return nil, err
+	}
+	remaining := payment.Amount - refunded
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount <= 0 || amount > remaining {
+		return nil, errRefundExceedsPayment
+	}
+
+	refund := store.NewRefund(utils.NewUuid(), payment.ID, payment.BookingID, amount, reason)
+	if err = s.storageService.CreateRefundTx(tx, refund); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// attemptRefund sends a committed refund to the gateway right away and returns its latest state.
+// A failed attempt is only logged; the refund scheduler retries it.
+func (s *Service) attemptRefund(refund *store.Refund) *store.Refund {
+	if err := s.refundProcessor.Attempt(refund); err != nil {
+		log.Printf("Refund %s attempt failed: %v", refund.ID, err)
+	}
+	latest, err := s.storageService.GetRefundById(refund.ID)
+	if err != nil || latest == nil {
+		return refund
+	}
+	return latest
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestRefund(t *testing.T) {
+	// A booking paid 200 up front and 50 more for a modification, of which 10 has been refunded already
+	paidBooking := []*store.Payment{
+		{ID: "original", BookingID: 1, Amount: 200, Status: constants.PAYMENT_SUCCESS},
+		{ID: "top-up", BookingID: 1, Amount: 50, Status: constants.PAYMENT_SUCCESS},
+	}
+	expectRefunded := func(m *mocks.MockStorageService, times int) {
+		m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "original", refundableStatuses).Return(float32(0), nil).Times(times)
+		m.EXPECT().SumRefundsByPaymentIdTx(gomock.Any(), "top-up", refundableStatuses).Return(float32(10), nil).Times(times)
+	}
+
+	tests := []struct {
+		name            string
+		amount          float32
+		setupMocks      func(m *mocks.MockStorageService)
+		expectedErr     error
+		expectedRefunds map[string]float32
+	}{
+		{
+			name:   "a refund larger than the original payment takes the top-up first",
+			amount: 220,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return(paidBooking, nil).Times(2)
+				expectRefunded(m, 3)
+				m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+			expectedRefunds: map[string]float32{"top-up": 40, "original": 180},
+		},
+		{
+			name:   "no amount refunds everything left",
+			amount: 0,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return(paidBooking, nil).Times(2)
+				expectRefunded(m, 3)
+				m.EXPECT().CreateRefundTx(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+			expectedRefunds: map[string]float32{"top-up": 40, "original": 200},
+		},
+		{
+			name:   "more than is left to refund",
+			amount: 241,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return(paidBooking, nil)
+				expectRefunded(m, 1)
+			},
+			expectedErr: errRefundExceedsPayment,
+		},
+		{
+			name:   "nothing paid yet",
+			amount: 100,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return([]*store.Payment{
+					{ID: "original", BookingID: 1, Amount: 200, Status: constants.PAYMENT_PENDING},
+				}, nil)
+			},
+			expectedErr: errPaymentNotRefundable,
+		},
+		{
+			name:   "no payment at all",
+			amount: 100,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetPaymentsByBookingIdTx(gomock.Any(), int64(1)).Return(nil, nil)
+			},
+			expectedErr: errBookingNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			mockStore.EXPECT().BeginTransaction(gomock.Any()).Return(nil, nil)
+			tt.setupMocks(mockStore)
+
+			refunds, err := service.requestRefund(1, tt.amount, "goodwill")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			refunded := map[string]float32{}
+			for _, refund := range refunds {
+				refunded[refund.PaymentID] = refund.Amount
+			}
+			assert.Equal(t, tt.expectedRefunds, refunded)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"strings"
+)
+
+var (
+	errReviewNotFound  = errors.New("review not found")
+	errAlreadyReviewed = errors.New("this stay has already been reviewed")
+)
+
+// AddReview lets a guest review a stay of theirs once it is completed; each booking can be reviewed once
+func (s *Service) AddReview(w http.ResponseWriter, r *http.Request) {
+	var addReviewRequest hotelsystem.AddReviewRequest
+	err := json.NewDecoder(r.Body).Decode(&addReviewRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateAddReviewRequest(&addReviewRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := s.getVisibleBooking(r, addReviewRequest.BookingID, addReviewRequest.BookingReference)
+	if errors.Is(err, errBookingNotFound) {
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error getting booking:", err)
+		http.Error(w, "Could not add review", http.StatusInternalServerError)
+		return
+	}
+	// Staff can see the bookings of their hotels, but only the guest who stayed may review
+	if booking.UserID != r.Context().Value("user_id").(int) {
+		http.Error(w, "Only the guest who made the booking can review the stay", http.StatusForbidden)
+		return
+	}
+	if booking.Status != store.BOOKING_COMPLETED {
+		http.Error(w, "Only completed stays can be reviewed", http.StatusConflict)
+		return
+	}
+
+	review := &store.Review{
+		BookingID:   booking.BookingID,
+		HotelID:     booking.HotelID,
+		UserID:      booking.UserID,
+		Overall:     int(addReviewRequest.Overall),
+		Cleanliness: int(addReviewRequest.Cleanliness),
+		Service:     int(addReviewRequest.Service),
+		Location:    int(addReviewRequest.Location),
+		Value:       int(addReviewRequest.Value),
+		Comment:     strings.TrimSpace(addReviewRequest.Comment),
+	}
+	err = s.addReview(review)
+	if errors.Is(err, errAlreadyReviewed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error adding review:", err)
+		http.Error(w, "Could not add review", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, &hotelsystem.AddReviewResponse{
+		Status:   "S",
+		Message:  "Review added successfully",
+		ReviewID: int64(review.ID),
+	})
+}
+
+// addReview stores a review and adds it to the hotel's rating
+func (s *Service) addReview(review *store.Review) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	created, err := s.storageService.CreateReviewTx(tx, review)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return errAlreadyReviewed
+	}
+	return s.storageService.UpdateHotelRatingTx(tx, int64(review.HotelID), 1, review.Overall)
+}
+
+// GetHotelReviews returns a page of a hotel's reviews, newest first, with its rating. Hidden reviews are
+// only returned to the people who manage the hotel.
+func (s *Service) GetHotelReviews(w http.ResponseWriter, r *http.Request) {
+	var getReviewsRequest hotelsystem.GetHotelReviewsRequest
+	err := json.NewDecoder(r.Body).Decode(&getReviewsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if getReviewsRequest.Limit == 0 {
+		getReviewsRequest.Limit = constants.DefaultPageSize
+	}
+	if err = validators.ValidateGetHotelReviewsRequest(&getReviewsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hotel, err := s.storageService.GetHotelById(getReviewsRequest.HotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error getting hotel:", err)
+		http.Error(w, "Could not get reviews", http.StatusInternalServerError)
+		return
+	}
+	visible, err := s.canViewHotel(r, &hotel)
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Could not get reviews", http.StatusInternalServerError)
+		return
+	}
+	if !visible {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	includeHidden, err := s.canManageHotel(r, getReviewsRequest.HotelID)
+	if err != nil {
+		log.Println("Error checking hotel staff:", err)
+		http.Error(w, "Could not get reviews", http.StatusInternalServerError)
+		return
+	}
+
+	reviews, err := s.storageService.GetHotelReviews(getReviewsRequest.HotelID, includeHidden, getReviewsRequest.Limit, getReviewsRequest.Offset)
+	if err != nil {
+		log.Println("Error getting reviews:", err)
+		http.Error(w, "Could not get reviews", http.StatusInternalServerError)
+		return
+	}
+	totalRecords, err := s.storageService.CountHotelReviews(getReviewsRequest.HotelID, includeHidden)
+	if err != nil {
+		log.Println("Error counting reviews:", err)
+		http.Error(w, "Could not get reviews", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.HotelReviewsResponseSerializer(hotel, reviews, totalRecords))
+}
+
+// ReplyToReview posts the hotel's public reply to a review, replacing any earlier one
+func (s *Service) ReplyToReview(w http.ResponseWriter, r *http.Request) {
+	var replyRequest hotelsystem.ReplyToReviewRequest
+	err := json.NewDecoder(r.Body).Decode(&replyRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateReplyToReviewRequest(&replyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review, err := s.storageService.GetReviewById(replyRequest.ReviewID)
+	if err != nil {
+		log.Println("Error getting review:", err)
+		http.Error(w, "Could not reply to review", http.StatusInternalServerError)
+		return
+	}
+	if review == nil {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, int64(review.HotelID)) {
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	replied, err := s.storageService.SetReviewReply(replyRequest.ReviewID, strings.TrimSpace(replyRequest.Reply), userId)
+	if err != nil {
+		log.Println("Error replying to review:", err)
+		http.Error(w, "Could not reply to review", http.StatusInternalServerError)
+		return
+	}
+	if !replied {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	sendSuccessResponse(w, "Reply posted successfully")
+}
+
+// HideReview hides an abusive review from guests and takes it out of the hotel's rating, or with
+// hidden set to false, restores it
+func (s *Service) HideReview(w http.ResponseWriter, r *http.Request) {
+	var hideReviewRequest hotelsystem.HideReviewRequest
+	err := json.NewDecoder(r.Body).Decode(&hideReviewRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateHideReviewRequest(&hideReviewRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = s.setReviewHidden(hideReviewRequest.ReviewID, hideReviewRequest.Hidden)
+	if errors.Is(err, errReviewNotFound) {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("Error hiding review:", err)
+		http.Error(w, "Could not update review", http.StatusInternalServerError)
+		return
+	}
+	if hideReviewRequest.Hidden {
+		sendSuccessResponse(w, "Review hidden successfully")
+		return
+	}
+	sendSuccessResponse(w, "Review shown successfully")
+}
+
+// setReviewHidden hides or shows a review and moves it out of or back into the hotel's rating. A review
+// that is already hidden or shown is left as it is.
+func (s *Service) setReviewHidden(reviewId int64, hidden bool) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	review, err := s.storageService.SetReviewHiddenTx(tx, reviewId, hidden)
+	if err != nil {
+		return err
+	}
+	if review == nil {
+		existing, err := s.storageService.GetReviewById(reviewId)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return errReviewNotFound
+		}
+		return nil
+	}
+	sign := 1
+	if hidden {
+		sign = -1
+	}
+	return s.storageService.UpdateHotelRatingTx(tx, int64(review.HotelID), sign, sign*review.Overall)
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"hotel-system/src/constants"
+	"hotel-system/src/store"
+	"hotel-system/src/store/mocks"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddReview(t *testing.T) {
+	// Booking 1 at hotel 3 was made by guest 7
+	booking := func(status store.BookingStatus) *store.Booking {
+		return &store.Booking{BookingID: 1, HotelID: 3, UserID: 7, Status: status}
+	}
+
+	tests := []struct {
+		name           string
+		userId         int
+		role           constants.UserRole
+		setupMocks     func(m *mocks.MockStorageService)
+		expectedStatus int
+	}{
+		{
+			name:   "the guest reviews a completed stay",
+			userId: 7,
+			role:   constants.ROLE_GUEST,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingById(int64(1)).Return(booking(store.BOOKING_COMPLETED), nil)
+				m.EXPECT().BeginTransaction(gomock.Any()).Return(nil, nil)
+				m.EXPECT().CreateReviewTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ *sql.Tx, review *store.Review) (bool, error) {
+					assert.Equal(t, 3, review.HotelID)
+					assert.Equal(t, 7, review.UserID)
+					assert.Equal(t, "Lovely stay", review.Comment)
+					return true, nil
+				})
+				m.EXPECT().UpdateHotelRatingTx(gomock.Any(), int64(3), 1, 4).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "the stay has not been completed",
+			userId: 7,
+			role:   constants.ROLE_GUEST,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingById(int64(1)).Return(booking(store.BOOKING_CONFIRMED), nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "the stay has been reviewed already",
+			userId: 7,
+			role:   constants.ROLE_GUEST,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingById(int64(1)).Return(booking(store.BOOKING_COMPLETED), nil)
+				m.EXPECT().BeginTransaction(gomock.Any()).Return(nil, nil)
+				m.EXPECT().CreateReviewTx(gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "staff of the hotel cannot review a guest's stay",
+			userId: 9,
+			role:   constants.ROLE_HOTEL_STAFF,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingById(int64(1)).Return(booking(store.BOOKING_COMPLETED), nil)
+				m.EXPECT().IsHotelStaff(int64(3), 9).Return(true, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "another guest's booking is not found",
+			userId: 8,
+			role:   constants.ROLE_GUEST,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().GetBookingById(int64(1)).Return(booking(store.BOOKING_COMPLETED), nil)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			tt.setupMocks(mockStore)
+
+			body, _ := json.Marshal(hotelsystem.AddReviewRequest{
+				BookingID:   1,
+				Overall:     4,
+				Cleanliness: 5,
+				Service:     4,
+				Location:    3,
+				Value:       4,
+				Comment:     "  Lovely stay ",
+			})
+			req := httptest.NewRequest(http.MethodPost, "/addReview", bytes.NewReader(body))
+			ctx := context.WithValue(req.Context(), "user_id", tt.userId)
+			ctx = context.WithValue(ctx, "role", tt.role)
+			rec := httptest.NewRecorder()
+			service.AddReview(rec, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestSetReviewHidden(t *testing.T) {
+	review := &store.Review{ID: 5, HotelID: 3, Overall: 2}
+
+	tests := []struct {
+		name        string
+		hidden      bool
+		setupMocks  func(m *mocks.MockStorageService)
+		expectedErr error
+	}{
+		{
+			name:   "hiding a review takes it out of the rating",
+			hidden: true,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().SetReviewHiddenTx(gomock.Any(), int64(5), true).Return(review, nil)
+				m.EXPECT().UpdateHotelRatingTx(gomock.Any(), int64(3), -1, -2).Return(nil)
+			},
+		},
+		{
+			name:   "showing a review puts it back into the rating",
+			hidden: false,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().SetReviewHiddenTx(gomock.Any(), int64(5), false).Return(review, nil)
+				m.EXPECT().UpdateHotelRatingTx(gomock.Any(), int64(3), 1, 2).Return(nil)
+			},
+		},
+		{
+			name:   "hiding a hidden review leaves the rating alone",
+			hidden: true,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().SetReviewHiddenTx(gomock.Any(), int64(5), true).Return(nil, nil)
+				m.EXPECT().GetReviewById(int64(5)).Return(&store.Review{ID: 5, HotelID: 3, Overall: 2, HiddenAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil)
+			},
+		},
+		{
+			name:   "missing review",
+			hidden: true,
+			setupMocks: func(m *mocks.MockStorageService) {
+				m.EXPECT().SetReviewHiddenTx(gomock.Any(), int64(5), true).Return(nil, nil)
+				m.EXPECT().GetReviewById(int64(5)).Return(nil, nil)
+			},
+			expectedErr: errReviewNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStore := mocks.NewMockStorageService(ctrl)
+			service := &Service{storageService: mockStore}
+			mockStore.EXPECT().BeginTransaction(gomock.Any()).Return(nil, nil)
+			tt.setupMocks(mockStore)
+
+			err := service.setReviewHidden(5, tt.hidden)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -1,29 +1,60 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"hotel-system/src/blobstore"
+	"hotel-system/src/constants"
+	"hotel-system/src/notifications"
 	payments2 "hotel-system/src/payments"
+	"hotel-system/src/refunds"
 	scheduler "hotel-system/src/schedulers"
 	"hotel-system/src/serializers"
 	"hotel-system/src/store"
 	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
 	"hotel-system/src/validators"
 	"log"
 	"net/http"
+	"time"
 )
 
 type Service struct {
-	storageService store.StorageService
-	paymentsClient payments2.Client
+	storageService  store.StorageService
+	paymentGateway  payments2.Gateway
+	refundProcessor *refunds.Processor
+	outbox          *notifications.Outbox
+	notifier        notifications.Notifier
+	blobStore       blobstore.Store
+	appBaseURL      string // links in emails point here
 }
 
-func NewService(db *sql.DB) *Service {
+func NewService(db *sql.DB, paymentGateway payments2.Gateway, emailSender notifications.Sender, blobStore blobstore.Store, appBaseURL string) *Service {
 	storageService := store.NewStore(db)
-	paymentsClient := payments2.NewPaymentsClient()
-	bs := scheduler.NewBookingScheduler(storageService)
+	refundProcessor := refunds.NewProcessor(storageService, paymentGateway)
+	outbox := notifications.NewOutbox(storageService, emailSender)
+	s := &Service{
+		storageService:  storageService,
+		paymentGateway:  paymentGateway,
+		refundProcessor: refundProcessor,
+		outbox:          outbox,
+		notifier:        outbox,
+		blobStore:       blobStore,
+		appBaseURL:      appBaseURL,
+	}
+	bs := scheduler.NewBookingScheduler(storageService, s)
 	bs.Start()
-	return &Service{storageService: storageService, paymentsClient: paymentsClient}
+	ps := scheduler.NewPaymentScheduler(s)
+	ps.Start()
+	rs := scheduler.NewRefundScheduler(refundProcessor)
+	rs.Start()
+	ws := scheduler.NewWebhookScheduler(s)
+	ws.Start()
+	obs := scheduler.NewOutboxScheduler(outbox)
+	obs.Start()
+	return s
 }
 
 func (s *Service) AddHotel(w http.ResponseWriter, r *http.Request) {
@@ -33,12 +64,175 @@ func (s *Service) AddHotel(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = s.storageService.AddHotel(addHotelRequest)
+	if err = validators.ValidateAddHotelRequest(&addHotelRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	amenityCodes := addHotelRequest.GetAmenities()
+	for _, rt := range addHotelRequest.GetRoomTypes() {
+		amenityCodes = append(amenityCodes, rt.GetAmenities()...)
+	}
+	amenityIds, err := s.amenityIdsByCode(amenityCodes)
+	var unknownErr *unknownAmenityError
+	if errors.As(err, &unknownErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
+		log.Println("Error looking up amenities:", err)
 		http.Error(w, "Could not add hotel", http.StatusInternalServerError)
 		return
 	}
-	sendSuccessResponse(w, "Hotel added successfully")
+
+	// The hotel's own room count and price summarise its room types: all rooms, lowest price
+	roomTypes := serializers.RoomTypesSerializer(&addHotelRequest)
+	addHotelRequest.TotalRooms = 0
+	addHotelRequest.CostPerNight = int64(roomTypes[0].CostPerNight)
+	for _, rt := range roomTypes {
+		addHotelRequest.TotalRooms += int64(rt.TotalRooms)
+		if int64(rt.CostPerNight) < addHotelRequest.CostPerNight {
+			addHotelRequest.CostPerNight = int64(rt.CostPerNight)
+		}
+	}
+
+	// Hotel staff who add a hotel are put on its staff so they can go on managing it
+	var staffUserId int
+	if requestRole(r) == constants.ROLE_HOTEL_STAFF {
+		staffUserId = r.Context().Value("user_id").(int)
+	}
+	hotelId, err := s.addHotelWithRoomTypes(addHotelRequest, roomTypes, amenityIds, staffUserId)
+	if err != nil {
+		log.Println("Error adding hotel:", err)
+		http.Error(w, "Could not add hotel", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, hotelsystem.AddHotelResponse{
+		Status:    "S",
+		Message:   "Hotel added successfully",
+		HotelID:   hotelId,
+		RoomTypes: serializers.RoomTypesResponseSerializer(roomTypes, nil, nil),
+	})
+}
+
+// addHotelWithRoomTypes creates the hotel and its room types in one transaction,
+// filling in the ids of the given room types. amenityIds holds the ids of the amenity codes of the
+// request. A non-zero staffUserId is assigned to the hotel.
+func (s *Service) addHotelWithRoomTypes(addHotelRequest hotelsystem.AddHotelRequest, roomTypes []*store.RoomType, amenityIds map[string]int64, staffUserId int) (hotelId int64, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	hotelId, err = s.storageService.AddHotelTx(tx, addHotelRequest)
+	if err != nil {
+		return 0, err
+	}
+	for _, rt := range roomTypes {
+		rt.HotelID = int(hotelId)
+		var roomTypeId int64
+		roomTypeId, err = s.storageService.CreateRoomTypeTx(tx, rt)
+		if err != nil {
+			return 0, err
+		}
+		rt.ID = int(roomTypeId)
+	}
+	if len(addHotelRequest.Amenities) > 0 {
+		if err = s.storageService.SetHotelAmenitiesTx(tx, hotelId, amenityIdsOf(addHotelRequest.Amenities, amenityIds)); err != nil {
+			return 0, err
+		}
+	}
+	// Room types given in the request are created in its order
+	for i, rt := range addHotelRequest.GetRoomTypes() {
+		if len(rt.Amenities) == 0 {
+			continue
+		}
+		if err = s.storageService.SetRoomTypeAmenitiesTx(tx, int64(roomTypes[i].ID), amenityIdsOf(rt.Amenities, amenityIds)); err != nil {
+			return 0, err
+		}
+	}
+	if addHotelRequest.CancellationPolicy != nil {
+		policy := serializers.CancellationPolicySerializer(hotelId, addHotelRequest.CancellationPolicy)
+		err = s.storageService.UpsertCancellationPolicyTx(tx, policy)
+		if err != nil {
+			return 0, err
+		}
+	}
+	// Images given by URL start the gallery, ahead of any uploaded later
+	for _, url := range addHotelRequest.GetImages() {
+		if err = s.storageService.CreateHotelImageTx(tx, store.NewLinkedHotelImage(utils.NewUuid(), int(hotelId), url)); err != nil {
+			return 0, err
+		}
+	}
+	if staffUserId != 0 {
+		if err = s.storageService.AddHotelStaffTx(tx, hotelId, staffUserId); err != nil {
+			return 0, err
+		}
+	}
+	return hotelId, nil
+}
+
+func (s *Service) SetCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	var setPolicyRequest hotelsystem.SetCancellationPolicyRequest
+	err := json.NewDecoder(r.Body).Decode(&setPolicyRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateSetCancellationPolicyRequest(&setPolicyRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorizeHotelManagement(w, r, setPolicyRequest.HotelID) {
+		return
+	}
+	policy := serializers.CancellationPolicySerializer(setPolicyRequest.HotelID, setPolicyRequest.CancellationPolicy)
+	if err = s.storageService.UpsertCancellationPolicy(policy); err != nil {
+		log.Println("Error saving cancellation policy:", err)
+		http.Error(w, "Could not save cancellation policy", http.StatusInternalServerError)
+		return
+	}
+	sendSuccessResponse(w, "Cancellation policy updated successfully")
+}
+
+// getCancellationPolicy returns the hotel's cancellation policy, falling back to the default policy
+func (s *Service) getCancellationPolicy(hotelId int64) (*store.CancellationPolicy, error) {
+	policy, err := s.storageService.GetCancellationPolicy(hotelId)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = store.NewCancellationPolicy(int(hotelId), constants.DefaultFreeCancellationHours, constants.DefaultCancellationPenaltyPercent, false)
+	}
+	return policy, nil
+}
+
+// prepareHotelSearch resolves the landmark a listing or search is near and checks that its amenity
+// filters are in the catalog, answering 404 or 400 if not, and reports whether the request may go on
+func (s *Service) prepareHotelSearch(w http.ResponseWriter, req *hotelsystem.GetHotelsListRequest) bool {
+	err := s.resolveSearchCentre(req)
+	if errors.Is(err, errLandmarkNotFound) {
+		http.Error(w, "Landmark not found", http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Println("Error looking up landmark:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return false
+	}
+	_, err = s.amenityIdsByCode(req.Amenities)
+	var unknownErr *unknownAmenityError
+	if errors.As(err, &unknownErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err != nil {
+		log.Println("Error looking up amenities:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return false
+	}
+	return true
 }
 
 func (s *Service) GetHotelsList(w http.ResponseWriter, r *http.Request) {
@@ -49,21 +243,94 @@ func (s *Service) GetHotelsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if getHotelsListReq.Limit == 0 {
-		getHotelsListReq.Limit = 10
+		getHotelsListReq.Limit = constants.DefaultPageSize
 	}
 	if err = validators.ValidateGetHotelsListRequest(&getHotelsListReq); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if !s.prepareHotelSearch(w, &getHotelsListReq) {
+		return
+	}
 	hotels, err := s.storageService.GetHotels(&getHotelsListReq)
 	if err != nil {
+		log.Println("Error listing hotels:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return
+	}
+	totalRecords, err := s.storageService.CountHotels(&getHotelsListReq)
+	if err != nil {
+		log.Println("Error counting hotels:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return
+	}
+	hotelIds := make([]int64, len(hotels))
+	for i, hotel := range hotels {
+		hotelIds[i] = int64(hotel.ID)
+	}
+	amenities, err := s.storageService.GetHotelAmenities(hotelIds)
+	if err != nil {
+		log.Println("Error getting hotel amenities:", err)
 		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
 		return
 	}
-	hotelsListResponse := serializers.HotelsResponseSerializer(hotels)
+	hotelsListResponse := serializers.HotelsResponseSerializer(hotels, amenities, totalRecords)
 	sendJsonResponse(w, hotelsListResponse)
 }
 
+// SearchHotels returns the hotels that can host the requested stay, with the total price of the stay
+func (s *Service) SearchHotels(w http.ResponseWriter, r *http.Request) {
+	var searchReq hotelsystem.GetHotelsListRequest
+	err := json.NewDecoder(r.Body).Decode(&searchReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if searchReq.Limit == 0 {
+		searchReq.Limit = constants.DefaultPageSize
+	}
+	if searchReq.NumRooms == 0 {
+		searchReq.NumRooms = 1
+	}
+	if searchReq.NumGuests == 0 {
+		searchReq.NumGuests = searchReq.NumRooms
+	}
+	if err = validators.ValidateSearchHotelsRequest(&searchReq); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.prepareHotelSearch(w, &searchReq) {
+		return
+	}
+	hotels, err := s.storageService.GetAvailableHotels(&searchReq)
+	if err != nil {
+		log.Println("Error searching available hotels:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return
+	}
+	totalRecords, err := s.storageService.CountAvailableHotels(&searchReq)
+	if err != nil {
+		log.Println("Error counting available hotels:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return
+	}
+	hotelIds := make([]int64, len(hotels))
+	for i, ah := range hotels {
+		hotelIds[i] = int64(ah.Hotel.ID)
+	}
+	amenities, err := s.storageService.GetHotelAmenities(hotelIds)
+	if err != nil {
+		log.Println("Error getting hotel amenities:", err)
+		http.Error(w, "Could not fetch hotels", http.StatusInternalServerError)
+		return
+	}
+
+	checkIn, _ := time.Parse(constants.DateFormat, searchReq.CheckInDate)
+	checkOut, _ := time.Parse(constants.DateFormat, searchReq.CheckOutDate)
+	numNights := int(checkOut.Sub(checkIn).Hours() / 24)
+	sendJsonResponse(w, serializers.AvailableHotelsResponseSerializer(hotels, amenities, totalRecords, numNights, int(searchReq.NumRooms)))
+}
+
 func (s *Service) GetHotelById(w http.ResponseWriter, r *http.Request) {
 
 	var getHotelByIdReq hotelsystem.GetHotelByIdRequest
@@ -73,11 +340,59 @@ func (s *Service) GetHotelById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	hotel, err := s.storageService.GetHotelById(getHotelByIdReq.HotelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+	visible, err := s.canViewHotel(r, &hotel)
 	if err != nil {
+		log.Println("Error checking hotel staff:", err)
 		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
 		return
 	}
-	hotelResponse := serializers.HotelByIdResponseSerializer(hotel)
+	if !visible {
+		http.Error(w, "Hotel not found", http.StatusNotFound)
+		return
+	}
+
+	roomTypes, err := s.storageService.GetRoomTypesByHotelId(getHotelByIdReq.HotelID)
+	if err != nil {
+		log.Println("Error getting room types:", err)
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+
+	// Availability comes from tonight's entry in the inventory calendar
+	today := time.Now().Truncate(24 * time.Hour)
+	maxRoomsSold, err := s.storageService.GetMaxRoomsSoldByRoomType(getHotelByIdReq.HotelID, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		log.Println("Error getting hotel inventory:", err)
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+	policy, err := s.getCancellationPolicy(getHotelByIdReq.HotelID)
+	if err != nil {
+		log.Println("Error getting cancellation policy:", err)
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+	amenities, err := s.storageService.GetHotelAmenities([]int64{getHotelByIdReq.HotelID})
+	if err != nil {
+		log.Println("Error getting hotel amenities:", err)
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+	roomTypeAmenities, err := s.storageService.GetRoomTypeAmenities(getHotelByIdReq.HotelID)
+	if err != nil {
+		log.Println("Error getting room type amenities:", err)
+		http.Error(w, "Could not fetch hotel", http.StatusInternalServerError)
+		return
+	}
+	hotelResponse := serializers.HotelByIdResponseSerializer(hotel, roomTypes, maxRoomsSold, policy, amenities[hotel.ID], roomTypeAmenities)
 	sendJsonResponse(w, hotelResponse)
 }
 
@@ -89,28 +404,39 @@ func (s *Service) GetPaymentStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Change to payment by booking id or session id
-	payment, err := s.storageService.GetPaymentByBookingId(paymentStatusRequest.BookingID)
+	if err = validators.ValidatePaymentStatusRequest(&paymentStatusRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := s.getVisibleBooking(r, paymentStatusRequest.BookingID, paymentStatusRequest.BookingReference)
+	if errors.Is(err, errBookingNotFound) {
+		http.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		log.Println("Error getting payment by booking id:", err)
+		log.Println("Error getting booking:", err)
 		http.Error(w, "Could not fetch payment details", http.StatusInternalServerError)
 		return
 	}
 
-	var bookingStatus string
-	booking, err := s.storageService.GetBookingById(paymentStatusRequest.BookingID)
+	payment, err := s.storageService.GetPaymentByBookingId(int64(booking.BookingID))
 	if err != nil {
-		log.Println("Error getting booking details:", err)
+		log.Println("Error getting payment by booking id:", err)
+		http.Error(w, "Could not fetch payment details", http.StatusInternalServerError)
+		return
 	}
-	if booking != nil {
-		bookingStatus = string(booking.Status)
+	if payment == nil {
+		http.Error(w, "Payment not found", http.StatusNotFound)
+		return
 	}
 
 	status := hotelsystem.PaymentStatusResponse{
-		OrderID:       paymentStatusRequest.OrderID,
-		PaymentStatus: string(payment.Status),
-		BookingStatus: bookingStatus,
-		Message:       "Payment status retrieved successfully",
+		OrderID:               paymentStatusRequest.OrderID,
+		PaymentStatus:         string(payment.Status),
+		BookingStatus:         string(booking.Status),
+		Message:               "Payment status retrieved successfully",
+		LatePaymentResolution: string(payment.LatePaymentResolution),
 	}
 	sendJsonResponse(w, status)
 }
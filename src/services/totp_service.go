@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/pb"
+	"hotel-system/src/store"
+	"hotel-system/src/utils"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"time"
+)
+
+var errTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// EnrollTOTP starts two-factor setup for the caller with a new secret, which their authenticator app
+// takes from the provisioning URI. Two-factor login is only on once the setup is confirmed with a code.
+func (s *Service) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value("user_id").(int)
+	user, err := s.storageService.GetUserById(userId)
+	if err != nil {
+		log.Println("Error getting user:", err)
+		http.Error(w, "Failed to start two-factor setup", http.StatusInternalServerError)
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		log.Println("Error generating TOTP secret:", err)
+		http.Error(w, "Failed to start two-factor setup", http.StatusInternalServerError)
+		return
+	}
+	stored, err := s.storageService.UpsertPendingUserTOTP(userId, secret)
+	if err != nil {
+		log.Println("Error storing TOTP secret:", err)
+		http.Error(w, "Failed to start two-factor setup", http.StatusInternalServerError)
+		return
+	}
+	if !stored {
+		http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+	sendJsonResponse(w, &pb.EnrollTotpResponse{
+		Secret:          secret,
+		ProvisioningUri: utils.TOTPProvisioningURI(user.Username, secret),
+	})
+}
+
+// ConfirmTOTP turns two-factor login on once the caller enters a code from their newly set up
+// authenticator, and hands out the recovery codes. They are shown only this once.
+func (s *Service) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var confirmTotpRequest pb.ConfirmTotpRequest
+	err := json.NewDecoder(r.Body).Decode(&confirmTotpRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateConfirmTotpRequest(&confirmTotpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	totp, err := s.storageService.GetUserTOTP(userId)
+	if err != nil {
+		log.Println("Error getting user TOTP:", err)
+		http.Error(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	if totp == nil {
+		http.Error(w, "Start two-factor setup at /enrollTotp first", http.StatusConflict)
+		return
+	}
+	if totp.EnabledAt.Valid {
+		http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+	step, ok := utils.VerifyTOTP(totp.Secret, confirmTotpRequest.Code, time.Now(), totp.LastUsedStep)
+	if !ok {
+		http.Error(w, "Invalid two-factor code", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.storageService.GetUserById(userId)
+	if err != nil {
+		log.Println("Error getting user:", err)
+		http.Error(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	recoveryCodes, err := s.enableTOTP(user, step)
+	if errors.Is(err, errTOTPAlreadyEnabled) {
+		http.Error(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println("Error enabling TOTP:", err)
+		http.Error(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	s.recordAuthAuditEvent(store.NewAuthAuditEvent(utils.NewUuid(), constants.AUDIT_TOTP_ENABLED, user.Username, clientIP(r), ""))
+	sendJsonResponse(w, &pb.ConfirmTotpResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Two-factor authentication enabled; it is asked for from your next login",
+	})
+}
+
+// DisableTOTP turns two-factor login off for the caller after checking a current code or a recovery
+// code. Admins cannot turn it off, as they need it to use admin endpoints.
+func (s *Service) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var disableTotpRequest pb.DisableTotpRequest
+	err := json.NewDecoder(r.Body).Decode(&disableTotpRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateDisableTotpRequest(&disableTotpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if requestRole(r) == constants.ROLE_ADMIN {
+		http.Error(w, "Two-factor authentication is required for admins", http.StatusForbidden)
+		return
+	}
+
+	userId := r.Context().Value("user_id").(int)
+	totp, err := s.storageService.GetUserTOTP(userId)
+	if err != nil {
+		log.Println("Error getting user TOTP:", err)
+		http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	if totp == nil || !totp.EnabledAt.Valid {
+		http.Error(w, "Two-factor authentication is not enabled", http.StatusConflict)
+		return
+	}
+	user, err := s.storageService.GetUserById(userId)
+	if err != nil {
+		log.Println("Error getting user:", err)
+		http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	ok, err := s.checkSecondFactor(&user, totp, disableTotpRequest.Code, disableTotpRequest.RecoveryCode, clientIP(r), time.Now())
+	if err != nil {
+		log.Println("Error checking two-factor code:", err)
+		http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "Invalid two-factor code", http.StatusBadRequest)
+		return
+	}
+
+	if err = s.disableTOTP(user); err != nil {
+		log.Println("Error disabling TOTP:", err)
+		http.Error(w, "Failed to disable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+	s.recordAuthAuditEvent(store.NewAuthAuditEvent(utils.NewUuid(), constants.AUDIT_TOTP_DISABLED, user.Username, clientIP(r), ""))
+	sendSuccessResponse(w, "Two-factor authentication disabled")
+}
+
+// LoginTOTP is the second login step for users with two-factor login on. It trades the pre-auth token
+// from /login and a TOTP or recovery code for a session. Wrong codes count as failed logins.
+func (s *Service) LoginTOTP(w http.ResponseWriter, r *http.Request) {
+	var loginTotpRequest pb.LoginTotpRequest
+	err := json.NewDecoder(r.Body).Decode(&loginTotpRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateLoginTotpRequest(&loginTotpRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claims, err := utils.ValidatePreAuthToken(loginTotpRequest.PreAuthToken)
+	if err != nil {
+		http.Error(w, "Invalid or expired pre-auth token, log in again", http.StatusUnauthorized)
+		return
+	}
+	user, err := s.storageService.GetUserById(claims.UserID)
+	if err != nil {
+		log.Println("Error getting user:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	ip := clientIP(r)
+	wait, err := s.loginRetryAfter(user.Username, ip, now)
+	if err != nil {
+		log.Println("Error checking login throttle:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if wait > 0 {
+		sendTooManyLoginAttempts(w, wait)
+		return
+	}
+
+	totp, err := s.storageService.GetUserTOTP(user.ID)
+	if err != nil {
+		log.Println("Error getting user TOTP:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if totp == nil || !totp.EnabledAt.Valid {
+		// Turned off since the password step
+		http.Error(w, "Invalid or expired pre-auth token, log in again", http.StatusUnauthorized)
+		return
+	}
+	ok, err := s.checkSecondFactor(&user, totp, loginTotpRequest.Code, loginTotpRequest.RecoveryCode, ip, now)
+	if err != nil {
+		log.Println("Error checking two-factor code:", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		s.recordLoginFailure(user.Username, ip, now)
+		http.Error(w, "Invalid two-factor code", http.StatusUnauthorized)
+		return
+	}
+	if _, err = s.storageService.ClearLoginThrottle(usernameLoginKey(user.Username)); err != nil {
+		log.Println("Error clearing login throttle:", err)
+	}
+
+	response, err := s.startSession(user.ID, user.Role, true, "Logged in successfully")
+	if err != nil {
+		log.Println("Error starting session:", err)
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, response)
+}
+
+// checkSecondFactor checks a TOTP code, or failing that a recovery code, for a user with two-factor
+// login on. Accepted codes are used up: a TOTP code cannot be replayed and a recovery code works once.
+func (s *Service) checkSecondFactor(user *store.User, totp *store.UserTOTP, code string, recoveryCode string, ip string, now time.Time) (bool, error) {
+	if code != "" {
+		step, ok := utils.VerifyTOTP(totp.Secret, code, now, totp.LastUsedStep)
+		if !ok {
+			return false, nil
+		}
+		// Guards against the same code being used by two requests at once
+		return s.storageService.UpdateUserTOTPLastUsedStep(user.ID, step)
+	}
+
+	used, err := s.storageService.UseRecoveryCode(user.ID, utils.HashToken(utils.NormalizeRecoveryCode(recoveryCode)))
+	if err != nil || !used {
+		return false, err
+	}
+	s.recordAuthAuditEvent(store.NewAuthAuditEvent(utils.NewUuid(), constants.AUDIT_RECOVERY_USED, user.Username, ip, ""))
+	return true, nil
+}
+
+// enableTOTP turns two-factor login on with the pending secret, whose first accepted code was of the
+// given step, and replaces the user's recovery codes. It returns the new recovery codes.
+func (s *Service) enableTOTP(user store.User, step int64) (recoveryCodes []string, err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	enabled, err := s.storageService.EnableUserTOTPTx(tx, user.ID, step)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+	recoveryCodes, err = utils.GenerateRecoveryCodes(constants.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	codeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		codeHashes[i] = utils.HashToken(code)
+	}
+	if err = s.storageService.DeleteRecoveryCodesTx(tx, user.ID); err != nil {
+		return nil, err
+	}
+	if err = s.storageService.CreateRecoveryCodesTx(tx, user.ID, codeHashes); err != nil {
+		return nil, err
+	}
+	if user.Email != "" {
+		body := "Two-factor authentication was just turned on for your account. If this was not you, reset your password right away."
+		if err = s.outbox.EnqueueTx(tx, user.ID, user.Email, "Two-factor authentication enabled", body); err != nil {
+			return nil, err
+		}
+	}
+	return recoveryCodes, nil
+}
+
+func (s *Service) disableTOTP(user store.User) (err error) {
+	tx, err := s.storageService.BeginTransaction(context.Background())
+	if err != nil {
+		return err
+	}
+	defer utils.RollbackOrCommitTransaction(tx, &err)
+
+	if err = s.storageService.DeleteUserTOTPTx(tx, user.ID); err != nil {
+		return err
+	}
+	if err = s.storageService.DeleteRecoveryCodesTx(tx, user.ID); err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+	body := "Two-factor authentication was just turned off for your account. If this was not you, reset your password right away."
+	return s.outbox.EnqueueTx(tx, user.ID, user.Email, "Two-factor authentication disabled", body)
+}
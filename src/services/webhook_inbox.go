@@ -0,0 +1,174 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"hotel-system/src/constants"
+	"hotel-system/src/payments"
+	"hotel-system/src/serializers"
+	"hotel-system/src/store"
+	hotelsystem "hotel-system/src/types/hotelsystem"
+	"hotel-system/src/validators"
+	"log"
+	"net/http"
+	"time"
+)
+
+// errInvalidWebhookEvent marks events that can never be applied; they are dead-lettered without retries
+var errInvalidWebhookEvent = errors.New("invalid webhook event")
+
+// receiveWebhookEvent stores a verified event in the webhook inbox, next to the body it was parsed
+// from, and starts applying it in the background. Events already in the inbox are redeliveries and
+// are not applied again.
+func (s *Service) receiveWebhookEvent(event *payments.WebhookEvent, rawBody []byte) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	inserted, err := s.storageService.CreateWebhookEvent(store.NewWebhookEvent(event.ID, string(event.Type), payload, event.GatewayType, rawBody))
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		log.Println("Webhook event already received:", event.ID)
+		return nil
+	}
+	go s.processInboxEvent(event.ID)
+	return nil
+}
+
+// ProcessDueWebhookEvents applies the inbox events waiting for an attempt. It is run by the webhook
+// scheduler and picks up events whose first attempt failed or whose worker died mid-way.
+func (s *Service) ProcessDueWebhookEvents() error {
+	events, err := s.storageService.GetDueWebhookEvents(time.Now(), constants.WebhookBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		s.processInboxEvent(event.ID)
+	}
+	return nil
+}
+
+// processInboxEvent claims an inbox event and applies it. A failed attempt is retried with exponential
+// backoff; after MaxWebhookAttempts, or straight away for invalid events, the event is dead-lettered.
+func (s *Service) processInboxEvent(eventId string) {
+	now := time.Now()
+	claimed, err := s.storageService.ClaimWebhookEvent(eventId, now, now.Add(constants.WebhookProcessingLease))
+	if err != nil {
+		log.Printf("Error claiming webhook event %s: %v", eventId, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	inboxEvent, err := s.storageService.GetWebhookEventById(eventId)
+	if err != nil || inboxEvent == nil {
+		log.Printf("Error getting webhook event %s: %v", eventId, err)
+		return
+	}
+
+	var event payments.WebhookEvent
+	if err = json.Unmarshal(inboxEvent.Payload, &event); err != nil {
+		err = errors.Join(errInvalidWebhookEvent, err)
+	} else {
+		err = s.applyWebhookEvent(&event)
+	}
+
+	inboxEvent.Attempts++
+	switch {
+	case err == nil:
+		inboxEvent.Status = constants.WEBHOOK_PROCESSED
+		inboxEvent.LastError = ""
+		inboxEvent.ProcessedAt.Time, inboxEvent.ProcessedAt.Valid = time.Now(), true
+	case errors.Is(err, errInvalidWebhookEvent) || inboxEvent.Attempts >= constants.MaxWebhookAttempts:
+		log.Printf("Webhook event %s dead-lettered after %d attempts: %v", eventId, inboxEvent.Attempts, err)
+		inboxEvent.Status = constants.WEBHOOK_DEAD
+		inboxEvent.LastError = err.Error()
+	default:
+		log.Printf("Webhook event %s attempt %d failed: %v", eventId, inboxEvent.Attempts, err)
+		inboxEvent.Status = constants.WEBHOOK_PENDING
+		inboxEvent.LastError = err.Error()
+		inboxEvent.NextAttemptAt = time.Now().Add(webhookRetryDelay(inboxEvent.Attempts))
+	}
+	if err = s.storageService.UpdateWebhookEvent(inboxEvent); err != nil {
+		log.Printf("Error updating webhook event %s: %v", eventId, err)
+	}
+}
+
+// webhookRetryDelay is the wait before the next attempt after the given number of failed attempts
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := constants.WebhookRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.WebhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, constants.WebhookRetryMaxDelay)
+}
+
+func (s *Service) GetWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	var getWebhookEventsRequest hotelsystem.GetWebhookEventsRequest
+	err := json.NewDecoder(r.Body).Decode(&getWebhookEventsRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateGetWebhookEventsRequest(&getWebhookEventsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.storageService.GetWebhookEvents(constants.WebhookEventStatus(getWebhookEventsRequest.Status), getWebhookEventsRequest.Limit, getWebhookEventsRequest.Offset)
+	if err != nil {
+		log.Println("Error getting webhook events:", err)
+		http.Error(w, "Failed to get webhook events", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.WebhookEventsResponseSerializer(events))
+}
+
+// ReplayWebhookEvent applies an inbox event again, typically a dead-lettered one after the cause of its
+// failures has been fixed. The event gets a fresh set of attempts.
+func (s *Service) ReplayWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	var replayWebhookEventRequest hotelsystem.ReplayWebhookEventRequest
+	err := json.NewDecoder(r.Body).Decode(&replayWebhookEventRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = validators.ValidateReplayWebhookEventRequest(&replayWebhookEventRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	inboxEvent, err := s.storageService.GetWebhookEventById(replayWebhookEventRequest.EventID)
+	if err != nil {
+		log.Println("Error getting webhook event:", err)
+		http.Error(w, "Failed to get webhook event", http.StatusInternalServerError)
+		return
+	}
+	if inboxEvent == nil {
+		http.Error(w, "Webhook event not found", http.StatusNotFound)
+		return
+	}
+	// The check for a worker holding the event is part of the reset, so a claim made in between is not
+	// overwritten
+	reset, err := s.storageService.ResetWebhookEvent(inboxEvent.ID, time.Now())
+	if err != nil {
+		log.Println("Error resetting webhook event:", err)
+		http.Error(w, "Failed to replay webhook event", http.StatusInternalServerError)
+		return
+	}
+	if !reset {
+		http.Error(w, "Webhook event is being processed", http.StatusConflict)
+		return
+	}
+	s.processInboxEvent(inboxEvent.ID)
+
+	replayed, err := s.storageService.GetWebhookEventById(inboxEvent.ID)
+	if err != nil || replayed == nil {
+		log.Println("Error getting replayed webhook event:", err)
+		http.Error(w, "Failed to get webhook event", http.StatusInternalServerError)
+		return
+	}
+	sendJsonResponse(w, serializers.WebhookEventResponseSerializer(replayed))
+}
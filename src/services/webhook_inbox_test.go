@@ -0,0 +1,24 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: 30 * time.Second},
+		{attempts: 2, expected: time.Minute},
+		{attempts: 4, expected: 4 * time.Minute},
+		{attempts: 8, expected: time.Hour},
+		{attempts: 50, expected: time.Hour},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, webhookRetryDelay(tt.attempts), "attempts %d", tt.attempts)
+	}
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CreateAmenity adds an amenity to the catalog and sets its id. It returns false if the code is taken.
+func (ds *dataStore) CreateAmenity(amenity *Amenity) (bool, error) {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (code, name, category, icon) VALUES ($1, $2, $3, $4) ON CONFLICT (code) DO NOTHING RETURNING id, created_at",
+		SchemaName,
+		AmenitiesTableName,
+	)
+	err := ds.db.QueryRow(query, amenity.Code, amenity.Name, amenity.Category, amenity.Icon).Scan(&amenity.ID, &amenity.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateAmenity changes the name, category and icon of the amenity with the given code. It returns
+// false if there is none.
+func (ds *dataStore) UpdateAmenity(amenity *Amenity) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET name = $1, category = $2, icon = $3 WHERE code = $4",
+		SchemaName,
+		AmenitiesTableName,
+	)
+	result, err := ds.db.Exec(query, amenity.Name, amenity.Category, amenity.Icon, amenity.Code)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+// DeleteAmenity removes an amenity from the catalog, and so from every hotel and room type. It returns
+// false if there is no amenity with the code.
+func (ds *dataStore) DeleteAmenity(code string) (bool, error) {
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE code = $1", SchemaName, AmenitiesTableName)
+	result, err := ds.db.Exec(query, code)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// GetAmenities returns the whole catalog, by category and name
+func (ds *dataStore) GetAmenities() ([]*Amenity, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s ORDER BY category, name",
+		strings.Join(AmenitiesTableColumns, ", "),
+		SchemaName,
+		AmenitiesTableName,
+	)
+	rows, err := ds.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenities(rows)
+}
+
+// GetAmenitiesByCodes returns the amenities with the given codes; unknown codes are left out
+func (ds *dataStore) GetAmenitiesByCodes(codes []string) ([]*Amenity, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE code = ANY($1) ORDER BY category, name",
+		strings.Join(AmenitiesTableColumns, ", "),
+		SchemaName,
+		AmenitiesTableName,
+	)
+	rows, err := ds.db.Query(query, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenities(rows)
+}
+
+func scanAmenities(rows *sql.Rows) ([]*Amenity, error) {
+	defer rows.Close()
+	var amenities []*Amenity
+	for rows.Next() {
+		var amenity Amenity
+		if err := rows.Scan(amenity.scanFields()...); err != nil {
+			return nil, err
+		}
+		amenities = append(amenities, &amenity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return amenities, nil
+}
+
+// SetHotelAmenitiesTx replaces the amenities of a hotel
+func (ds *dataStore) SetHotelAmenitiesTx(tx *sql.Tx, hotelId int64, amenityIds []int64) error {
+	return setAmenitiesTx(tx, HotelAmenitiesTableName, "hotel_id", hotelId, amenityIds)
+}
+
+// SetRoomTypeAmenitiesTx replaces the amenities of a room type
+func (ds *dataStore) SetRoomTypeAmenitiesTx(tx *sql.Tx, roomTypeId int64, amenityIds []int64) error {
+	return setAmenitiesTx(tx, RoomTypeAmenitiesTableName, "room_type_id", roomTypeId, amenityIds)
+}
+
+func setAmenitiesTx(tx *sql.Tx, tableName string, ownerColumn string, ownerId int64, amenityIds []int64) error {
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = $1", SchemaName, tableName, ownerColumn)
+	if _, err := tx.Exec(query, ownerId); err != nil {
+		return err
+	}
+	query = fmt.Sprintf(
+		"INSERT INTO %s.%s (%s, amenity_id) SELECT $1, UNNEST($2::integer[])",
+		SchemaName,
+		tableName,
+		ownerColumn,
+	)
+	_, err := tx.Exec(query, ownerId, pq.Array(amenityIds))
+	return err
+}
+
+// GetHotelAmenities returns the amenities of each of the given hotels, keyed by hotel id
+func (ds *dataStore) GetHotelAmenities(hotelIds []int64) (map[int][]*Amenity, error) {
+	query := fmt.Sprintf(`
+		SELECT ha.hotel_id, %s FROM %s.%s ha
+		JOIN %s.%s a ON a.id = ha.amenity_id
+		WHERE ha.hotel_id = ANY($1)
+		ORDER BY a.category, a.name`,
+		prefixColumns("a", AmenitiesTableColumns),
+		SchemaName,
+		HotelAmenitiesTableName,
+		SchemaName,
+		AmenitiesTableName,
+	)
+	rows, err := ds.db.Query(query, pq.Array(hotelIds))
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenitiesByOwner(rows)
+}
+
+// GetRoomTypeAmenities returns the amenities of the room types of a hotel, keyed by room type id
+func (ds *dataStore) GetRoomTypeAmenities(hotelId int64) (map[int][]*Amenity, error) {
+	query := fmt.Sprintf(`
+		SELECT rta.room_type_id, %[1]s FROM %[2]s.%[3]s rta
+		JOIN %[2]s.%[4]s a ON a.id = rta.amenity_id
+		JOIN %[2]s.%[5]s r ON r.id = rta.room_type_id
+		WHERE r.hotel_id = $1
+		ORDER BY a.category, a.name`,
+		prefixColumns("a", AmenitiesTableColumns),
+		SchemaName,
+		RoomTypeAmenitiesTableName,
+		AmenitiesTableName,
+		RoomTypeTableName,
+	)
+	rows, err := ds.db.Query(query, hotelId)
+	if err != nil {
+		return nil, err
+	}
+	return scanAmenitiesByOwner(rows)
+}
+
+func scanAmenitiesByOwner(rows *sql.Rows) (map[int][]*Amenity, error) {
+	defer rows.Close()
+	amenities := make(map[int][]*Amenity)
+	for rows.Next() {
+		var ownerId int
+		var amenity Amenity
+		if err := rows.Scan(append([]any{&ownerId}, amenity.scanFields()...)...); err != nil {
+			return nil, err
+		}
+		amenities[ownerId] = append(amenities[ownerId], &amenity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return amenities, nil
+}
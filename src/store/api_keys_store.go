@@ -0,0 +1,101 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (ds *dataStore) CreateAPIKey(key *APIKey) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		SchemaName,
+		APIKeysTableName,
+		strings.Join(APIKeysTableColumns, ", "),
+	)
+	_, err := ds.db.Exec(query, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes), key.CreatedAt, key.LastUsedAt, key.RevokedAt)
+	return err
+}
+
+// GetAPIKeyByHash returns the key with the given hash, revoked or not, or nil if there is none
+func (ds *dataStore) GetAPIKeyByHash(keyHash string) (*APIKey, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE key_hash = $1",
+		strings.Join(APIKeysTableColumns, ", "),
+		SchemaName,
+		APIKeysTableName,
+	)
+	var key APIKey
+	err := ds.db.QueryRow(query, keyHash).Scan(key.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeysByUserId returns every key of a partner, newest first, including revoked ones
+func (ds *dataStore) GetAPIKeysByUserId(userId int) ([]*APIKey, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE user_id = $1 ORDER BY created_at DESC",
+		strings.Join(APIKeysTableColumns, ", "),
+		SchemaName,
+		APIKeysTableName,
+	)
+	rows, err := ds.db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		var key APIKey
+		if err = rows.Scan(key.scanFields()...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key of the given partner. It returns false if the partner has no such
+// active key.
+func (ds *dataStore) RevokeAPIKey(keyId string, userId int) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL",
+		SchemaName,
+		APIKeysTableName,
+	)
+	result, err := ds.db.Exec(query, keyId, userId)
+	if err != nil {
+		return false, err
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return revoked > 0, nil
+}
+
+// TouchAPIKey records that a key was used. To spare a write on every request, last_used_at only
+// moves once it is older than constants.APIKeyUsageResolution.
+func (ds *dataStore) TouchAPIKey(keyId string, now time.Time) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)",
+		SchemaName,
+		APIKeysTableName,
+	)
+	_, err := ds.db.Exec(query, now, keyId, now.Add(-constants.APIKeyUsageResolution))
+	return err
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CreateBookingModificationTx records a modification of a booking and sets its id and creation time
+func (ds *dataStore) CreateBookingModificationTx(tx *sql.Tx, m *BookingModification) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.%s (booking_id, old_check_in_date, old_check_out_date, old_number_of_rooms, old_total_cost,
+			new_check_in_date, new_check_out_date, new_number_of_rooms, new_total_cost, status, payment_id, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at`,
+		SchemaName,
+		BookingModificationsTableName,
+	)
+	return tx.QueryRow(query, m.BookingID, m.OldCheckInDate, m.OldCheckOutDate, m.OldNumberOfRooms, m.OldTotalCost,
+		m.NewCheckInDate, m.NewCheckOutDate, m.NewNumberOfRooms, m.NewTotalCost, m.Status, m.PaymentID, m.ResolvedAt).Scan(&m.ID, &m.CreatedAt)
+}
+
+// GetPendingBookingModificationTx fetches and locks the modification of a booking that is waiting for its
+// payment, returning nil if there is none
+func (ds *dataStore) GetPendingBookingModificationTx(tx *sql.Tx, bookingId int64) (*BookingModification, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE booking_id = $1 AND status = $2 FOR UPDATE",
+		strings.Join(BookingModificationsTableColumns, ", "),
+		SchemaName,
+		BookingModificationsTableName,
+	)
+	return scanBookingModification(tx.QueryRow(query, bookingId, MODIFICATION_PENDING))
+}
+
+// GetBookingModificationByPaymentIdTx fetches and locks the modification a top-up payment was made for,
+// returning nil if the payment is not a top-up
+func (ds *dataStore) GetBookingModificationByPaymentIdTx(tx *sql.Tx, paymentId string) (*BookingModification, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE payment_id = $1 FOR UPDATE",
+		strings.Join(BookingModificationsTableColumns, ", "),
+		SchemaName,
+		BookingModificationsTableName,
+	)
+	return scanBookingModification(tx.QueryRow(query, paymentId))
+}
+
+func scanBookingModification(row *sql.Row) (*BookingModification, error) {
+	var m BookingModification
+	err := row.Scan(m.scanFields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetPendingBookingModificationsCreatedBefore returns the modifications still waiting for their payment
+// that were made before the given time, oldest first
+func (ds *dataStore) GetPendingBookingModificationsCreatedBefore(createdBefore time.Time) ([]*BookingModification, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE status = $1 AND created_at < $2 ORDER BY created_at",
+		strings.Join(BookingModificationsTableColumns, ", "),
+		SchemaName,
+		BookingModificationsTableName,
+	)
+	rows, err := ds.db.Query(query, MODIFICATION_PENDING, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var modifications []*BookingModification
+	for rows.Next() {
+		var m BookingModification
+		if err = rows.Scan(m.scanFields()...); err != nil {
+			return nil, err
+		}
+		modifications = append(modifications, &m)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return modifications, nil
+}
+
+// ResolveBookingModificationTx marks a modification as applied or failed
+func (ds *dataStore) ResolveBookingModificationTx(tx *sql.Tx, modificationId int, status ModificationStatus) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, resolved_at = NOW() WHERE id = $2",
+		SchemaName,
+		BookingModificationsTableName,
+	)
+	_, err := tx.Exec(query, status, modificationId)
+	return err
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GetCancellationPolicy returns the cancellation policy of a hotel, or nil if the hotel has not configured one
+func (ds *dataStore) GetCancellationPolicy(hotelId int64) (*CancellationPolicy, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE hotel_id = $1", strings.Join(CancellationPolicyColumns, ", "), SchemaName, CancellationPolicyTableName)
+	var cp CancellationPolicy
+	err := ds.db.QueryRow(query, hotelId).Scan(
+		&cp.HotelID,
+		&cp.FreeCancellationHours,
+		&cp.PenaltyPercent,
+		&cp.NonRefundable,
+		&cp.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &cp, nil
+}
+
+func (ds *dataStore) UpsertCancellationPolicy(policy *CancellationPolicy) error {
+	_, err := ds.db.Exec(upsertCancellationPolicyQuery(), policy.HotelID, policy.FreeCancellationHours, policy.PenaltyPercent, policy.NonRefundable, policy.UpdatedAt)
+	return err
+}
+
+func (ds *dataStore) UpsertCancellationPolicyTx(tx *sql.Tx, policy *CancellationPolicy) error {
+	_, err := tx.Exec(upsertCancellationPolicyQuery(), policy.HotelID, policy.FreeCancellationHours, policy.PenaltyPercent, policy.NonRefundable, policy.UpdatedAt)
+	return err
+}
+
+func upsertCancellationPolicyQuery() string {
+	return fmt.Sprintf(`
+		INSERT INTO %s.%s (hotel_id, free_cancellation_hours, penalty_percent, non_refundable, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (hotel_id) DO UPDATE SET
+			free_cancellation_hours = EXCLUDED.free_cancellation_hours,
+			penalty_percent = EXCLUDED.penalty_percent,
+			non_refundable = EXCLUDED.non_refundable,
+			updated_at = EXCLUDED.updated_at`,
+		SchemaName,
+		CancellationPolicyTableName,
+	)
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// CreateHotelImageTx adds an image at the end of a hotel's gallery, setting its position
+func (ds *dataStore) CreateHotelImageTx(tx *sql.Tx, image *HotelImage) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s.%[2]s (%[3]s)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(position) + 1, 0) FROM %[1]s.%[2]s WHERE hotel_id = $2), $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING position`,
+		SchemaName,
+		HotelImagesTableName,
+		strings.Join(HotelImagesTableColumns, ", "),
+	)
+	return tx.QueryRow(query, image.ID, image.HotelID, image.URL, image.ThumbnailURL, image.BlobKey, image.ThumbnailKey,
+		image.ContentType, image.SizeBytes, image.Width, image.Height, image.CreatedAt).Scan(&image.Position)
+}
+
+// GetHotelImages returns a hotel's gallery in order
+func (ds *dataStore) GetHotelImages(hotelId int64) ([]*HotelImage, error) {
+	rows, err := ds.db.Query(hotelImagesQuery(), hotelId)
+	if err != nil {
+		return nil, err
+	}
+	return scanHotelImages(rows)
+}
+
+func (ds *dataStore) GetHotelImagesTx(tx *sql.Tx, hotelId int64) ([]*HotelImage, error) {
+	rows, err := tx.Query(hotelImagesQuery(), hotelId)
+	if err != nil {
+		return nil, err
+	}
+	return scanHotelImages(rows)
+}
+
+func hotelImagesQuery() string {
+	return fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE hotel_id = $1 ORDER BY position, created_at",
+		strings.Join(HotelImagesTableColumns, ", "),
+		SchemaName,
+		HotelImagesTableName,
+	)
+}
+
+func scanHotelImages(rows *sql.Rows) ([]*HotelImage, error) {
+	defer rows.Close()
+	var images []*HotelImage
+	for rows.Next() {
+		var image HotelImage
+		if err := rows.Scan(image.scanFields()...); err != nil {
+			return nil, err
+		}
+		images = append(images, &image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// DeleteHotelImageTx removes an image from a hotel's gallery and returns it, or nil if the hotel has
+// no such image. The gaps it leaves in the positions are harmless, as only their order matters.
+func (ds *dataStore) DeleteHotelImageTx(tx *sql.Tx, hotelId int64, imageId string) (*HotelImage, error) {
+	query := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE id = $1 AND hotel_id = $2 RETURNING %s",
+		SchemaName,
+		HotelImagesTableName,
+		strings.Join(HotelImagesTableColumns, ", "),
+	)
+	var image HotelImage
+	err := tx.QueryRow(query, imageId, hotelId).Scan(image.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+// ReorderHotelImagesTx puts a hotel's images in the order of imageIds
+func (ds *dataStore) ReorderHotelImagesTx(tx *sql.Tx, hotelId int64, imageIds []string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.%s i SET position = o.position - 1
+		FROM UNNEST($1::text[]) WITH ORDINALITY AS o(id, position)
+		WHERE i.id = o.id AND i.hotel_id = $2`,
+		SchemaName,
+		HotelImagesTableName,
+	)
+	_, err := tx.Exec(query, pq.Array(imageIds), hotelId)
+	return err
+}
+
+// SyncHotelImageUrlsTx copies the gallery URLs, in order, to the hotel's image_urls, which the hotel
+// listing and search return
+func (ds *dataStore) SyncHotelImageUrlsTx(tx *sql.Tx, hotelId int64) error {
+	query := fmt.Sprintf(`
+		UPDATE %[1]s.%[2]s SET updated_at = NOW(), image_urls = COALESCE(
+			(SELECT ARRAY_AGG(url ORDER BY position, created_at) FROM %[1]s.%[3]s WHERE hotel_id = $1),
+			ARRAY[]::text[]
+		)
+		WHERE id = $1`,
+		SchemaName,
+		HotelTableName,
+		HotelImagesTableName,
+	)
+	_, err := tx.Exec(query, hotelId)
+	return err
+}
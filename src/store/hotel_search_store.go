@@ -0,0 +1,306 @@
+package store
+
+import (
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/types/hotelsystem"
+	"hotel-system/src/utils"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// prefixColumns qualifies every column with the given table alias
+func prefixColumns(alias string, columns []string) string {
+	prefixed := make([]string, len(columns))
+	for i, column := range columns {
+		prefixed[i] = alias + "." + column
+	}
+	return strings.Join(prefixed, ", ")
+}
+
+// hotelQuery is a hotel listing or availability search being built: its FROM and WHERE clauses, the
+// expressions its results can be ordered by, and the query arguments
+type hotelQuery struct {
+	from     string
+	rank     string // relevance to the search query; 0 without one
+	distance string // km from the point searched near; empty without one
+	args     []any
+}
+
+// arg adds a query argument and returns its placeholder
+func (q *hotelQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// searchConditions builds the WHERE conditions shared by the hotel listing and the availability search,
+// and sets the rank and distance of the query. Only active hotels are searched. The search query
+// matches the full-text index, or failing that, names, cities and localities that are spelled alike.
+// A search near a point keeps the hotels within radius_km of it, found through a bounding box before
+// their exact distance is computed. The hotel table must be aliased as h.
+func (q *hotelQuery) searchConditions(req *hotelsystem.GetHotelsListRequest) []string {
+	conditions := []string{"h.status = " + q.arg(constants.HOTEL_ACTIVE)}
+	q.rank = "0"
+	if query := strings.TrimSpace(req.SearchQuery); query != "" {
+		queryArg := q.arg(query)
+		// Parsed both with English stemming, for the description, and without, for names and addresses
+		textQuery := fmt.Sprintf("(websearch_to_tsquery('english', %[1]s) || websearch_to_tsquery('simple', %[1]s))", queryArg)
+		conditions = append(conditions, fmt.Sprintf(
+			"(h.search_vector @@ %[1]s OR h.name %% %[2]s OR h.city %% %[2]s OR h.locality %% %[2]s)",
+			textQuery,
+			queryArg,
+		))
+		q.rank = fmt.Sprintf(
+			"(ts_rank(h.search_vector, %[1]s) + GREATEST(similarity(h.name, %[2]s), similarity(h.city, %[2]s), similarity(h.locality, %[2]s)))",
+			textQuery,
+			queryArg,
+		)
+	}
+	if req.City != "" {
+		conditions = append(conditions, "h.city ILIKE "+q.arg(req.City))
+	}
+	if req.State != "" {
+		conditions = append(conditions, "h.state ILIKE "+q.arg(req.State))
+	}
+	if req.MinRating > 0 {
+		conditions = append(conditions, fmt.Sprintf("h.review_count > 0 AND h.rating_total >= %s::float8 * h.review_count", q.arg(req.MinRating)))
+	}
+	if req.NearLatitude != nil && req.NearLongitude != nil {
+		box := utils.NewBoundingBox(*req.NearLatitude, *req.NearLongitude, req.RadiusKm)
+		conditions = append(conditions, fmt.Sprintf("h.latitude BETWEEN %s AND %s", q.arg(box.MinLatitude), q.arg(box.MaxLatitude)))
+		if !box.AllLongitudes {
+			conditions = append(conditions, fmt.Sprintf("h.longitude BETWEEN %s AND %s", q.arg(box.MinLongitude), q.arg(box.MaxLongitude)))
+		}
+		latitude, longitude := q.arg(*req.NearLatitude), q.arg(*req.NearLongitude)
+		// Haversine; LEAST keeps rounding from taking ASIN out of its domain
+		q.distance = fmt.Sprintf(
+			"(2 * %[3]v * ASIN(SQRT(LEAST(1, POWER(SIN(RADIANS(h.latitude - %[1]s::float8) / 2), 2) + "+
+				"COS(RADIANS(%[1]s::float8)) * COS(RADIANS(h.latitude)) * POWER(SIN(RADIANS(h.longitude - %[2]s::float8) / 2), 2)))))",
+			latitude,
+			longitude,
+			constants.EarthRadiusKm,
+		)
+		conditions = append(conditions, fmt.Sprintf("%s <= %s", q.distance, q.arg(req.RadiusKm)))
+	}
+	return conditions
+}
+
+// roomTypePriceConditions filters room types, aliased as r, by the price range of the request
+func (q *hotelQuery) roomTypePriceConditions(req *hotelsystem.GetHotelsListRequest) []string {
+	var conditions []string
+	if req.MinPrice != nil {
+		conditions = append(conditions, "r.cost_per_night >= "+q.arg(*req.MinPrice))
+	}
+	if req.MaxPrice != nil {
+		conditions = append(conditions, "r.cost_per_night <= "+q.arg(*req.MaxPrice))
+	}
+	return conditions
+}
+
+// amenitiesCondition keeps the hotels that offer every amenity of the request, themselves or in a room
+// type matching roomTypes, a condition on the room types aliased as rta_r. The codes are checked to be
+// in the catalog beforehand, so that an unknown one does not simply match nothing.
+func (q *hotelQuery) amenitiesCondition(req *hotelsystem.GetHotelsListRequest, roomTypes string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+			SELECT 1 FROM %[1]s.%[2]s a
+			WHERE a.code = ANY(%[5]s)
+			AND NOT EXISTS (SELECT 1 FROM %[1]s.%[3]s ha WHERE ha.hotel_id = h.id AND ha.amenity_id = a.id)
+			AND NOT EXISTS (
+				SELECT 1 FROM %[1]s.%[4]s rta JOIN %[1]s.%[6]s rta_r ON rta_r.id = rta.room_type_id
+				WHERE rta.amenity_id = a.id AND %[7]s
+			)
+		)`,
+		SchemaName,
+		AmenitiesTableName,
+		HotelAmenitiesTableName,
+		RoomTypeAmenitiesTableName,
+		q.arg(pq.Array(req.Amenities)),
+		RoomTypeTableName,
+		roomTypes,
+	)
+}
+
+// orderBy builds the ORDER BY clause for the sort key of the request; a search near a point is sorted
+// by distance unless another key is asked for. price is the price a hotel is sorted by and ties breaks
+// ties, ending in a unique key so that pages do not overlap.
+func (q *hotelQuery) orderBy(req *hotelsystem.GetHotelsListRequest, price string, ties string) string {
+	sortBy := constants.HotelSort(req.SortBy)
+	if sortBy == "" && q.distance != "" {
+		sortBy = constants.SORT_DISTANCE
+	}
+	switch {
+	case sortBy == constants.SORT_PRICE_ASC:
+		return price + ", h.id"
+	case sortBy == constants.SORT_PRICE_DESC:
+		return price + " DESC, h.id"
+	case sortBy == constants.SORT_DISTANCE && q.distance != "":
+		return fmt.Sprintf("%s, %s", q.distance, ties)
+	case sortBy == constants.SORT_RATING:
+		return "h.rating_total::float8 / NULLIF(h.review_count, 0) DESC NULLS LAST, h.review_count DESC, " + ties
+	case sortBy == constants.SORT_POPULARITY:
+		popularity := fmt.Sprintf(
+			"(SELECT COUNT(*) FROM %s.%s b WHERE b.hotel_id = h.id AND b.booking_time >= %s AND b.status = ANY(%s))",
+			SchemaName,
+			BookingTableName,
+			q.arg(time.Now().Add(-constants.PopularityWindow)),
+			q.arg(pq.Array([]string{string(BOOKING_CONFIRMED), string(BOOKING_COMPLETED)})),
+		)
+		return fmt.Sprintf("%s DESC, %s DESC, %s", popularity, q.rank, ties)
+	default:
+		return fmt.Sprintf("%s DESC, %s", q.rank, ties)
+	}
+}
+
+// selectDistance is the distance column, with a leading comma, selected after the hotel columns of a
+// search near a point
+func (q *hotelQuery) selectDistance() string {
+	if q.distance == "" {
+		return ""
+	}
+	return ", " + q.distance
+}
+
+// scanHotelFields adds the distance selected by selectDistance to the fields a hotel row is scanned into
+func (q *hotelQuery) scanHotelFields(hotel *Hotel) []any {
+	if q.distance == "" {
+		return hotel.scanFields()
+	}
+	return append(hotel.scanFields(), &hotel.DistanceKm)
+}
+
+// newHotelListingQuery builds the hotel listing. The price range keeps hotels with a room type in it;
+// amenities may be offered by any of their room types; min_available_rooms counts the rooms free
+// tonight across all room types.
+func newHotelListingQuery(req *hotelsystem.GetHotelsListRequest) *hotelQuery {
+	q := &hotelQuery{}
+	conditions := q.searchConditions(req)
+	if len(req.Amenities) > 0 {
+		conditions = append(conditions, q.amenitiesCondition(req, "rta_r.hotel_id = h.id"))
+	}
+	if priceConditions := q.roomTypePriceConditions(req); len(priceConditions) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s.%s r WHERE r.hotel_id = h.id AND %s)",
+			SchemaName,
+			RoomTypeTableName,
+			strings.Join(priceConditions, " AND "),
+		))
+	}
+	if req.MinAvailableRooms > 0 {
+		conditions = append(conditions, fmt.Sprintf(`(
+				SELECT COALESCE(SUM(r.total_rooms - COALESCE(hi.rooms_sold, 0)), 0)
+				FROM %[1]s.%[2]s r
+				LEFT JOIN %[1]s.%[3]s hi ON hi.room_type_id = r.id AND hi.stay_date = %[4]s::date
+				WHERE r.hotel_id = h.id
+			) >= %[5]s`,
+			SchemaName,
+			RoomTypeTableName,
+			HotelInventoryTableName,
+			q.arg(time.Now().Truncate(24*time.Hour)),
+			q.arg(req.MinAvailableRooms),
+		))
+	}
+	q.from = fmt.Sprintf("FROM %s.%s h WHERE %s", SchemaName, HotelTableName, strings.Join(conditions, " AND "))
+	return q
+}
+
+// CountHotels returns how many hotels the listing finds for the request, ignoring limit and offset
+func (ds *dataStore) CountHotels(req *hotelsystem.GetHotelsListRequest) (int64, error) {
+	q := newHotelListingQuery(req)
+	var count int64
+	err := ds.db.QueryRow("SELECT COUNT(*) "+q.from, q.args...).Scan(&count)
+	return count, err
+}
+
+// newAvailableHotelsQuery builds the availability search, which pairs each hotel with its cheapest room
+// type in the price range that still has num_rooms, or min_available_rooms if more, free on every night
+// and fits num_guests. Amenities not offered by the hotel itself must be offered by that room type.
+func newAvailableHotelsQuery(req *hotelsystem.GetHotelsListRequest) *hotelQuery {
+	q := &hotelQuery{}
+	checkIn, checkOut := q.arg(req.CheckInDate), q.arg(req.CheckOutDate)
+	roomTypeConditions := append(
+		[]string{"r.hotel_id = h.id", fmt.Sprintf("r.max_occupancy * %s >= %s", q.arg(req.NumRooms), q.arg(req.NumGuests))},
+		q.roomTypePriceConditions(req)...,
+	)
+	if len(req.Amenities) > 0 {
+		roomTypeConditions = append(roomTypeConditions, q.amenitiesCondition(req, "rta_r.id = r.id"))
+	}
+	minRooms := q.arg(max(req.NumRooms, req.MinAvailableRooms))
+	conditions := q.searchConditions(req)
+	q.from = fmt.Sprintf(`
+		FROM %[1]s.%[2]s h
+		JOIN LATERAL (
+			SELECT r.id, r.name, r.cost_per_night, r.total_rooms - COALESCE(MAX(hi.rooms_sold), 0) AS available_rooms
+			FROM %[1]s.%[3]s r
+			LEFT JOIN %[1]s.%[4]s hi
+				ON hi.room_type_id = r.id AND hi.stay_date >= %[5]s::date AND hi.stay_date < %[6]s::date
+			WHERE %[7]s
+			GROUP BY r.id
+			HAVING r.total_rooms - COALESCE(MAX(hi.rooms_sold), 0) >= %[8]s
+			ORDER BY r.cost_per_night, r.id
+			LIMIT 1
+		) rt ON TRUE
+		WHERE %[9]s`,
+		SchemaName,
+		HotelTableName,
+		RoomTypeTableName,
+		HotelInventoryTableName,
+		checkIn,
+		checkOut,
+		strings.Join(roomTypeConditions, " AND "),
+		minRooms,
+		strings.Join(conditions, " AND "),
+	)
+	return q
+}
+
+// GetAvailableHotels returns the hotels matching the search that can host the whole stay, each paired
+// with its cheapest room type that still has num_rooms free on every night and fits num_guests.
+// Results are ordered by the sort key of the request, by default by relevance to the search query, or
+// distance for a search near a point, and then by that room type's price.
+func (ds *dataStore) GetAvailableHotels(req *hotelsystem.GetHotelsListRequest) ([]*AvailableHotel, error) {
+	q := newAvailableHotelsQuery(req)
+	orderBy := q.orderBy(req, "rt.cost_per_night", "rt.cost_per_night, h.id")
+	query := fmt.Sprintf(`
+		SELECT %s%s, rt.id, rt.name, rt.cost_per_night, rt.available_rooms
+		%s
+		ORDER BY %s
+		LIMIT %s OFFSET %s`,
+		prefixColumns("h", HotelTableColumns),
+		q.selectDistance(),
+		q.from,
+		orderBy,
+		q.arg(req.Limit),
+		q.arg(req.Offset),
+	)
+
+	rows, err := ds.db.Query(query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hotels []*AvailableHotel
+	for rows.Next() {
+		var ah AvailableHotel
+		fields := append(q.scanHotelFields(&ah.Hotel), &ah.RoomTypeID, &ah.RoomTypeName, &ah.RoomTypeCostPerNight, &ah.AvailableRooms)
+		err = rows.Scan(fields...)
+		if err != nil {
+			return nil, err
+		}
+		hotels = append(hotels, &ah)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return hotels, nil
+}
+
+// CountAvailableHotels returns how many hotels the availability search finds, ignoring limit and offset
+func (ds *dataStore) CountAvailableHotels(req *hotelsystem.GetHotelsListRequest) (int64, error) {
+	q := newAvailableHotelsQuery(req)
+	var count int64
+	err := ds.db.QueryRow("SELECT COUNT(*) "+q.from, q.args...).Scan(&count)
+	return count, err
+}
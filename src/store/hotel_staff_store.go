@@ -0,0 +1,80 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// AddHotelStaff assigns a user to a hotel; assigning someone already on its staff is a no-op
+func (ds *dataStore) AddHotelStaff(hotelId int64, userId int) error {
+	_, err := ds.db.Exec(addHotelStaffQuery(), hotelId, userId)
+	return err
+}
+
+func (ds *dataStore) AddHotelStaffTx(tx *sql.Tx, hotelId int64, userId int) error {
+	_, err := tx.Exec(addHotelStaffQuery(), hotelId, userId)
+	return err
+}
+
+func addHotelStaffQuery() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s.%s (hotel_id, user_id) VALUES ($1, $2) ON CONFLICT (hotel_id, user_id) DO NOTHING",
+		SchemaName,
+		HotelStaffTableName,
+	)
+}
+
+// RemoveHotelStaff unassigns a user from a hotel, returning false if they were not on its staff
+func (ds *dataStore) RemoveHotelStaff(hotelId int64, userId int) (bool, error) {
+	query := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE hotel_id = $1 AND user_id = $2",
+		SchemaName,
+		HotelStaffTableName,
+	)
+	result, err := ds.db.Exec(query, hotelId, userId)
+	if err != nil {
+		return false, err
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return removed > 0, nil
+}
+
+func (ds *dataStore) IsHotelStaff(hotelId int64, userId int) (bool, error) {
+	query := fmt.Sprintf(
+		"SELECT EXISTS (SELECT 1 FROM %s.%s WHERE hotel_id = $1 AND user_id = $2)",
+		SchemaName,
+		HotelStaffTableName,
+	)
+	var isStaff bool
+	err := ds.db.QueryRow(query, hotelId, userId).Scan(&isStaff)
+	return isStaff, err
+}
+
+// GetBookingsByHotelId returns a page of a hotel's bookings, newest first, optionally of one status only
+func (ds *dataStore) GetBookingsByHotelId(hotelId int64, status BookingStatus, limit int32, offset int32) ([]*Booking, error) {
+	query := "SELECT " + strings.Join(BookingsTableColumns, ", ") +
+		" FROM public.booking WHERE hotel_id = $1 AND ($2 = '' OR status = $2) ORDER BY booking_time DESC LIMIT $3 OFFSET $4"
+
+	rows, err := ds.db.Query(query, hotelId, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*Booking
+	for rows.Next() {
+		var b Booking
+		if err = rows.Scan(b.scanFields()...); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, &b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
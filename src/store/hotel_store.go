@@ -0,0 +1,61 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"hotel-system/src/constants"
+	"time"
+)
+
+// UpdateHotelDetailsTx saves the descriptive fields of a hotel: its name, description and address
+func (ds *dataStore) UpdateHotelDetailsTx(tx *sql.Tx, hotel *Hotel) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.%s SET name = $1, description = $2, street = $3, landmark = $4, locality = $5, city = $6,
+			pincode = $7, state = $8, latitude = $9, longitude = $10, updated_at = NOW()
+		WHERE id = $11`,
+		SchemaName,
+		HotelTableName,
+	)
+	_, err := tx.Exec(query, hotel.Name, hotel.Description, hotel.Street, hotel.Landmark, hotel.Locality, hotel.City,
+		hotel.Pincode, hotel.State, hotel.Latitude, hotel.Longitude, hotel.ID)
+	return err
+}
+
+// RefreshHotelRoomSummaryTx recomputes a hotel's total rooms and price from its room types: all rooms,
+// lowest price, as when the hotel was added
+func (ds *dataStore) RefreshHotelRoomSummaryTx(tx *sql.Tx, hotelId int64) error {
+	query := fmt.Sprintf(`
+		UPDATE %[1]s.%[2]s h SET
+			total_rooms = rt.total_rooms,
+			cost_per_night = rt.cost_per_night,
+			updated_at = NOW()
+		FROM (
+			SELECT SUM(total_rooms) AS total_rooms, MIN(cost_per_night) AS cost_per_night
+			FROM %[1]s.%[3]s WHERE hotel_id = $1
+		) rt
+		WHERE h.id = $1 AND rt.total_rooms IS NOT NULL`,
+		SchemaName,
+		HotelTableName,
+		RoomTypeTableName,
+	)
+	_, err := tx.Exec(query, hotelId)
+	return err
+}
+
+func (ds *dataStore) UpdateHotelStatusTx(tx *sql.Tx, hotelId int64, status constants.HotelStatus) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET status = $1, updated_at = NOW() WHERE id = $2", SchemaName, HotelTableName)
+	_, err := tx.Exec(query, status, hotelId)
+	return err
+}
+
+// CountUpcomingBookingsTx counts the bookings of a hotel that still hold rooms for a night on or after from
+func (ds *dataStore) CountUpcomingBookingsTx(tx *sql.Tx, hotelId int64, from time.Time) (int, error) {
+	query := fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s.%s WHERE hotel_id = $1 AND status IN ($2, $3) AND check_out_date > $4",
+		SchemaName,
+		BookingTableName,
+	)
+	var count int
+	err := tx.QueryRow(query, hotelId, BOOKING_PENDING, BOOKING_CONFIRMED, from).Scan(&count)
+	return count, err
+}
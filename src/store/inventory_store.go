@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetMaxRoomsSoldByRoomType returns, for every room type of the hotel that has sold rooms during the stay,
+// the highest number of rooms sold on any single night. Rooms available for the whole stay are the room
+// type's total rooms minus this value; room types missing from the map have nothing sold.
+func (ds *dataStore) GetMaxRoomsSoldByRoomType(hotelId int64, checkIn, checkOut time.Time) (map[int]int, error) {
+	query := fmt.Sprintf(
+		"SELECT room_type_id, MAX(rooms_sold) FROM %s.%s WHERE hotel_id = $1 AND stay_date >= $2 AND stay_date < $3 GROUP BY room_type_id",
+		SchemaName,
+		HotelInventoryTableName,
+	)
+	rows, err := ds.db.Query(query, hotelId, checkIn, checkOut)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	maxRoomsSold := make(map[int]int)
+	for rows.Next() {
+		var roomTypeId, roomsSold int
+		if err = rows.Scan(&roomTypeId, &roomsSold); err != nil {
+			return nil, err
+		}
+		maxRoomsSold[roomTypeId] = roomsSold
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return maxRoomsSold, nil
+}
+
+// GetMaxRoomsSoldTx returns the highest number of rooms of a room type sold on any night of the stay.
+func (ds *dataStore) GetMaxRoomsSoldTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time) (int, error) {
+	query := fmt.Sprintf(
+		"SELECT COALESCE(MAX(rooms_sold), 0) FROM %s.%s WHERE room_type_id = $1 AND stay_date >= $2 AND stay_date < $3",
+		SchemaName,
+		HotelInventoryTableName,
+	)
+	var maxRoomsSold int
+	err := tx.QueryRow(query, roomTypeId, checkIn, checkOut).Scan(&maxRoomsSold)
+	if err != nil {
+		return 0, err
+	}
+	return maxRoomsSold, nil
+}
+
+// ReserveHotelInventoryTx marks numRooms of a room type as sold for every night of the stay, creating
+// calendar rows for nights that have not been sold before.
+func (ds *dataStore) ReserveHotelInventoryTx(tx *sql.Tx, hotelId int64, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s.%[2]s (hotel_id, room_type_id, stay_date, rooms_sold)
+		SELECT $1, $2, night::date, $5
+		FROM generate_series($3::date, $4::date - 1, INTERVAL '1 day') AS night
+		ON CONFLICT (room_type_id, stay_date)
+		DO UPDATE SET rooms_sold = %[2]s.rooms_sold + EXCLUDED.rooms_sold`,
+		SchemaName,
+		HotelInventoryTableName,
+	)
+	_, err := tx.Exec(query, hotelId, roomTypeId, checkIn, checkOut, numRooms)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReleaseHotelInventoryTx returns numRooms of a room type to the calendar for every night of the stay.
+func (ds *dataStore) ReleaseHotelInventoryTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET rooms_sold = GREATEST(rooms_sold - $4, 0) WHERE room_type_id = $1 AND stay_date >= $2 AND stay_date < $3",
+		SchemaName,
+		HotelInventoryTableName,
+	)
+	_, err := tx.Exec(query, roomTypeId, checkIn, checkOut, numRooms)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetPeakRoomsSoldSinceTx returns the highest number of rooms of a room type sold on any night from the
+// given date on. A room type cannot shrink below it without overbooking.
+func (ds *dataStore) GetPeakRoomsSoldSinceTx(tx *sql.Tx, roomTypeId int64, from time.Time) (int, error) {
+	query := fmt.Sprintf(
+		"SELECT COALESCE(MAX(rooms_sold), 0) FROM %s.%s WHERE room_type_id = $1 AND stay_date >= $2",
+		SchemaName,
+		HotelInventoryTableName,
+	)
+	var peakRoomsSold int
+	err := tx.QueryRow(query, roomTypeId, from).Scan(&peakRoomsSold)
+	if err != nil {
+		return 0, err
+	}
+	return peakRoomsSold, nil
+}
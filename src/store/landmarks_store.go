@@ -0,0 +1,67 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (ds *dataStore) CreateLandmark(landmark *Landmark) (int64, error) {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (name, city, latitude, longitude) VALUES ($1, $2, $3, $4) RETURNING id",
+		SchemaName,
+		LandmarksTableName,
+	)
+	var landmarkId int64
+	err := ds.db.QueryRow(query, landmark.Name, landmark.City, landmark.Latitude, landmark.Longitude).Scan(&landmarkId)
+	return landmarkId, err
+}
+
+// GetLandmarkByName finds a landmark by name, ignoring case, within a city if one is given. It returns
+// nil if there is none; of landmarks with the same name in different cities, the oldest is returned.
+func (ds *dataStore) GetLandmarkByName(name string, city string) (*Landmark, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE LOWER(name) = LOWER($1) AND ($2 = '' OR LOWER(city) = LOWER($2)) ORDER BY id LIMIT 1",
+		strings.Join(LandmarksTableColumns, ", "),
+		SchemaName,
+		LandmarksTableName,
+	)
+	var landmark Landmark
+	err := ds.db.QueryRow(query, name, city).Scan(landmark.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &landmark, nil
+}
+
+// GetLandmarks returns the landmarks of a city, or all of them without one, by name
+func (ds *dataStore) GetLandmarks(city string) ([]*Landmark, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE $1 = '' OR LOWER(city) = LOWER($1) ORDER BY city, name",
+		strings.Join(LandmarksTableColumns, ", "),
+		SchemaName,
+		LandmarksTableName,
+	)
+	rows, err := ds.db.Query(query, city)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var landmarks []*Landmark
+	for rows.Next() {
+		var landmark Landmark
+		if err = rows.Scan(landmark.scanFields()...); err != nil {
+			return nil, err
+		}
+		landmarks = append(landmarks, &landmark)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return landmarks, nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetLoginThrottles returns the throttles of the given keys that have recorded failures
+func (ds *dataStore) GetLoginThrottles(keys []string) ([]*LoginThrottle, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE key = ANY($1)",
+		strings.Join(LoginThrottlesTableColumns, ", "),
+		SchemaName,
+		LoginThrottlesTableName,
+	)
+	rows, err := ds.db.Query(query, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var throttles []*LoginThrottle
+	for rows.Next() {
+		var throttle LoginThrottle
+		if err = rows.Scan(throttle.scanFields()...); err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, &throttle)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return throttles, nil
+}
+
+// RecordLoginFailure counts a failed login for a key and returns the number of failures since
+// windowStart. A key whose last failure is older than windowStart starts counting again.
+func (ds *dataStore) RecordLoginFailure(key string, now time.Time, windowStart time.Time) (int, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s.%[2]s (key, failed_count, last_failed_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failed_count = CASE WHEN %[2]s.last_failed_at < $3 THEN 1 ELSE %[2]s.failed_count + 1 END,
+			last_failed_at = $2
+		RETURNING failed_count`,
+		SchemaName,
+		LoginThrottlesTableName,
+	)
+	var failedCount int
+	err := ds.db.QueryRow(query, key, now, windowStart).Scan(&failedCount)
+	return failedCount, err
+}
+
+func (ds *dataStore) LockLoginKey(key string, until time.Time) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET locked_until = $1 WHERE key = $2", SchemaName, LoginThrottlesTableName)
+	_, err := ds.db.Exec(query, until, key)
+	return err
+}
+
+// ClearLoginThrottle forgets the failures of a key, lifting any lock. It returns false if the key
+// had no failures recorded.
+func (ds *dataStore) ClearLoginThrottle(key string) (bool, error) {
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE key = $1", SchemaName, LoginThrottlesTableName)
+	result, err := ds.db.Exec(query, key)
+	if err != nil {
+		return false, err
+	}
+	cleared, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return cleared > 0, nil
+}
+
+func (ds *dataStore) CreateAuthAuditEvent(event *AuthAuditEvent) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		SchemaName,
+		AuthAuditEventsTableName,
+		strings.Join(AuthAuditEventsTableColumns, ", "),
+	)
+	_, err := ds.db.Exec(query, event.ID, event.Type, event.Username, event.IP, event.ActorUserID, event.Detail, event.CreatedAt)
+	return err
+}
+
+// GetAuthAuditEvents lists audit events, newest first, optionally only those about one username
+func (ds *dataStore) GetAuthAuditEvents(username string, limit int32, offset int32) ([]*AuthAuditEvent, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE ($1 = '' OR username = $1) ORDER BY created_at DESC LIMIT $2 OFFSET $3",
+		strings.Join(AuthAuditEventsTableColumns, ", "),
+		SchemaName,
+		AuthAuditEventsTableName,
+	)
+	rows, err := ds.db.Query(query, username, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*AuthAuditEvent
+	for rows.Next() {
+		var event AuthAuditEvent
+		if err = rows.Scan(event.scanFields()...); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
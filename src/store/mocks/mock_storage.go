@@ -11,6 +11,7 @@ import (
 	store "hotel-system/src/store"
 	hotelsystem "hotel-system/src/types/hotelsystem"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -38,18 +39,47 @@ func (m *MockStorageService) EXPECT() *MockStorageServiceMockRecorder {
 	return m.recorder
 }
 
-// AddHotel mocks base method.
-func (m *MockStorageService) AddHotel(addHotelRequest hotelsystem.AddHotelRequest) error {
+// AddHotelStaff mocks base method.
+func (m *MockStorageService) AddHotelStaff(hotelId int64, userId int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddHotel", addHotelRequest)
+	ret := m.ctrl.Call(m, "AddHotelStaff", hotelId, userId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// AddHotel indicates an expected call of AddHotel.
-func (mr *MockStorageServiceMockRecorder) AddHotel(addHotelRequest interface{}) *gomock.Call {
+// AddHotelStaff indicates an expected call of AddHotelStaff.
+func (mr *MockStorageServiceMockRecorder) AddHotelStaff(hotelId, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHotel", reflect.TypeOf((*MockStorageService)(nil).AddHotel), addHotelRequest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHotelStaff", reflect.TypeOf((*MockStorageService)(nil).AddHotelStaff), hotelId, userId)
+}
+
+// AddHotelStaffTx mocks base method.
+func (m *MockStorageService) AddHotelStaffTx(tx *sql.Tx, hotelId int64, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddHotelStaffTx", tx, hotelId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddHotelStaffTx indicates an expected call of AddHotelStaffTx.
+func (mr *MockStorageServiceMockRecorder) AddHotelStaffTx(tx, hotelId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHotelStaffTx", reflect.TypeOf((*MockStorageService)(nil).AddHotelStaffTx), tx, hotelId, userId)
+}
+
+// AddHotelTx mocks base method.
+func (m *MockStorageService) AddHotelTx(tx *sql.Tx, addHotelRequest hotelsystem.AddHotelRequest) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddHotelTx", tx, addHotelRequest)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddHotelTx indicates an expected call of AddHotelTx.
+func (mr *MockStorageServiceMockRecorder) AddHotelTx(tx, addHotelRequest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHotelTx", reflect.TypeOf((*MockStorageService)(nil).AddHotelTx), tx, addHotelRequest)
 }
 
 // AddUser mocks base method.
@@ -82,382 +112,2052 @@ func (mr *MockStorageServiceMockRecorder) BeginTransaction(ctx interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTransaction", reflect.TypeOf((*MockStorageService)(nil).BeginTransaction), ctx)
 }
 
-// CreateBooking mocks base method.
-func (m *MockStorageService) CreateBooking(booking *store.Booking) (int64, error) {
+// ClaimOutboxMessage mocks base method.
+func (m *MockStorageService) ClaimOutboxMessage(messageId string, now, leaseUntil time.Time) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBooking", booking)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "ClaimOutboxMessage", messageId, now, leaseUntil)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateBooking indicates an expected call of CreateBooking.
-func (mr *MockStorageServiceMockRecorder) CreateBooking(booking interface{}) *gomock.Call {
+// ClaimOutboxMessage indicates an expected call of ClaimOutboxMessage.
+func (mr *MockStorageServiceMockRecorder) ClaimOutboxMessage(messageId, now, leaseUntil interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockStorageService)(nil).CreateBooking), booking)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOutboxMessage", reflect.TypeOf((*MockStorageService)(nil).ClaimOutboxMessage), messageId, now, leaseUntil)
 }
 
-// CreateBookingTx mocks base method.
-func (m *MockStorageService) CreateBookingTx(tx *sql.Tx, booking *store.Booking) (int64, error) {
+// ClaimWebhookEvent mocks base method.
+func (m *MockStorageService) ClaimWebhookEvent(eventId string, now, leaseUntil time.Time) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateBookingTx", tx, booking)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "ClaimWebhookEvent", eventId, now, leaseUntil)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateBookingTx indicates an expected call of CreateBookingTx.
-func (mr *MockStorageServiceMockRecorder) CreateBookingTx(tx, booking interface{}) *gomock.Call {
+// ClaimWebhookEvent indicates an expected call of ClaimWebhookEvent.
+func (mr *MockStorageServiceMockRecorder) ClaimWebhookEvent(eventId, now, leaseUntil interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBookingTx", reflect.TypeOf((*MockStorageService)(nil).CreateBookingTx), tx, booking)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookEvent", reflect.TypeOf((*MockStorageService)(nil).ClaimWebhookEvent), eventId, now, leaseUntil)
 }
 
-// CreateIdempotencyKey mocks base method.
-func (m *MockStorageService) CreateIdempotencyKey(ik *store.IdempotencyKey) error {
+// ClearLoginThrottle mocks base method.
+func (m *MockStorageService) ClearLoginThrottle(key string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ik)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ClearLoginThrottle", key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
-func (mr *MockStorageServiceMockRecorder) CreateIdempotencyKey(ik interface{}) *gomock.Call {
+// ClearLoginThrottle indicates an expected call of ClearLoginThrottle.
+func (mr *MockStorageServiceMockRecorder) ClearLoginThrottle(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStorageService)(nil).CreateIdempotencyKey), ik)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLoginThrottle", reflect.TypeOf((*MockStorageService)(nil).ClearLoginThrottle), key)
 }
 
-// CreatePayment mocks base method.
-func (m *MockStorageService) CreatePayment(payment *store.Payment) error {
+// CountAvailableHotels mocks base method.
+func (m *MockStorageService) CountAvailableHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePayment", payment)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CountAvailableHotels", getHotelsListReq)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CreatePayment indicates an expected call of CreatePayment.
-func (mr *MockStorageServiceMockRecorder) CreatePayment(payment interface{}) *gomock.Call {
+// CountAvailableHotels indicates an expected call of CountAvailableHotels.
+func (mr *MockStorageServiceMockRecorder) CountAvailableHotels(getHotelsListReq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockStorageService)(nil).CreatePayment), payment)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAvailableHotels", reflect.TypeOf((*MockStorageService)(nil).CountAvailableHotels), getHotelsListReq)
 }
 
-// DecrementHotelRoomsTx mocks base method.
-func (m *MockStorageService) DecrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error {
+// CountHotelReviews mocks base method.
+func (m *MockStorageService) CountHotelReviews(hotelId int64, includeHidden bool) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DecrementHotelRoomsTx", tx, hotelId, numRooms)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "CountHotelReviews", hotelId, includeHidden)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DecrementHotelRoomsTx indicates an expected call of DecrementHotelRoomsTx.
-func (mr *MockStorageServiceMockRecorder) DecrementHotelRoomsTx(tx, hotelId, numRooms interface{}) *gomock.Call {
+// CountHotelReviews indicates an expected call of CountHotelReviews.
+func (mr *MockStorageServiceMockRecorder) CountHotelReviews(hotelId, includeHidden interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecrementHotelRoomsTx", reflect.TypeOf((*MockStorageService)(nil).DecrementHotelRoomsTx), tx, hotelId, numRooms)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountHotelReviews", reflect.TypeOf((*MockStorageService)(nil).CountHotelReviews), hotelId, includeHidden)
 }
 
-// GetBookingById mocks base method.
-func (m *MockStorageService) GetBookingById(bookingId int64) (store.Booking, error) {
+// CountHotels mocks base method.
+func (m *MockStorageService) CountHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBookingById", bookingId)
-	ret0, _ := ret[0].(store.Booking)
+	ret := m.ctrl.Call(m, "CountHotels", getHotelsListReq)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBookingById indicates an expected call of GetBookingById.
-func (mr *MockStorageServiceMockRecorder) GetBookingById(bookingId interface{}) *gomock.Call {
+// CountHotels indicates an expected call of CountHotels.
+func (mr *MockStorageServiceMockRecorder) CountHotels(getHotelsListReq interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingById", reflect.TypeOf((*MockStorageService)(nil).GetBookingById), bookingId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountHotels", reflect.TypeOf((*MockStorageService)(nil).CountHotels), getHotelsListReq)
 }
 
-// GetBookingByIdTx mocks base method.
-func (m *MockStorageService) GetBookingByIdTx(tx *sql.Tx, bookingId int64) (store.Booking, error) {
+// CountUpcomingBookingsTx mocks base method.
+func (m *MockStorageService) CountUpcomingBookingsTx(tx *sql.Tx, hotelId int64, from time.Time) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBookingByIdTx", tx, bookingId)
-	ret0, _ := ret[0].(store.Booking)
+	ret := m.ctrl.Call(m, "CountUpcomingBookingsTx", tx, hotelId, from)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBookingByIdTx indicates an expected call of GetBookingByIdTx.
-func (mr *MockStorageServiceMockRecorder) GetBookingByIdTx(tx, bookingId interface{}) *gomock.Call {
+// CountUpcomingBookingsTx indicates an expected call of CountUpcomingBookingsTx.
+func (mr *MockStorageServiceMockRecorder) CountUpcomingBookingsTx(tx, hotelId, from interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetBookingByIdTx), tx, bookingId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUpcomingBookingsTx", reflect.TypeOf((*MockStorageService)(nil).CountUpcomingBookingsTx), tx, hotelId, from)
 }
 
-// GetCompletedBookings mocks base method.
-func (m *MockStorageService) GetCompletedBookings() ([]*store.Booking, error) {
+// CountUserBookings mocks base method.
+func (m *MockStorageService) CountUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCompletedBookings")
-	ret0, _ := ret[0].([]*store.Booking)
+	ret := m.ctrl.Call(m, "CountUserBookings", userId, req, today)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCompletedBookings indicates an expected call of GetCompletedBookings.
-func (mr *MockStorageServiceMockRecorder) GetCompletedBookings() *gomock.Call {
+// CountUserBookings indicates an expected call of CountUserBookings.
+func (mr *MockStorageServiceMockRecorder) CountUserBookings(userId, req, today interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompletedBookings", reflect.TypeOf((*MockStorageService)(nil).GetCompletedBookings))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserBookings", reflect.TypeOf((*MockStorageService)(nil).CountUserBookings), userId, req, today)
 }
 
-// GetExpiredBookings mocks base method.
-func (m *MockStorageService) GetExpiredBookings() ([]*store.Booking, error) {
+// CreateAPIKey mocks base method.
+func (m *MockStorageService) CreateAPIKey(key *store.APIKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetExpiredBookings")
-	ret0, _ := ret[0].([]*store.Booking)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateAPIKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetExpiredBookings indicates an expected call of GetExpiredBookings.
-func (mr *MockStorageServiceMockRecorder) GetExpiredBookings() *gomock.Call {
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockStorageServiceMockRecorder) CreateAPIKey(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredBookings", reflect.TypeOf((*MockStorageService)(nil).GetExpiredBookings))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockStorageService)(nil).CreateAPIKey), key)
 }
 
-// GetHotelById mocks base method.
-func (m *MockStorageService) GetHotelById(id int64) (store.Hotel, error) {
+// CreateAmenity mocks base method.
+func (m *MockStorageService) CreateAmenity(amenity *store.Amenity) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHotelById", id)
-	ret0, _ := ret[0].(store.Hotel)
+	ret := m.ctrl.Call(m, "CreateAmenity", amenity)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetHotelById indicates an expected call of GetHotelById.
-func (mr *MockStorageServiceMockRecorder) GetHotelById(id interface{}) *gomock.Call {
+// CreateAmenity indicates an expected call of CreateAmenity.
+func (mr *MockStorageServiceMockRecorder) CreateAmenity(amenity interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelById", reflect.TypeOf((*MockStorageService)(nil).GetHotelById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAmenity", reflect.TypeOf((*MockStorageService)(nil).CreateAmenity), amenity)
 }
 
-// GetHotelByIdTx mocks base method.
-func (m *MockStorageService) GetHotelByIdTx(tx *sql.Tx, hotelId int64) (store.Hotel, error) {
+// CreateAuthAuditEvent mocks base method.
+func (m *MockStorageService) CreateAuthAuditEvent(event *store.AuthAuditEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHotelByIdTx", tx, hotelId)
-	ret0, _ := ret[0].(store.Hotel)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateAuthAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetHotelByIdTx indicates an expected call of GetHotelByIdTx.
-func (mr *MockStorageServiceMockRecorder) GetHotelByIdTx(tx, hotelId interface{}) *gomock.Call {
+// CreateAuthAuditEvent indicates an expected call of CreateAuthAuditEvent.
+func (mr *MockStorageServiceMockRecorder) CreateAuthAuditEvent(event interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetHotelByIdTx), tx, hotelId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthAuditEvent", reflect.TypeOf((*MockStorageService)(nil).CreateAuthAuditEvent), event)
 }
 
-// GetHotelForUpdate mocks base method.
-func (m *MockStorageService) GetHotelForUpdate(tx *sql.Tx, hotelId int64) (store.Hotel, error) {
+// CreateBooking mocks base method.
+func (m *MockStorageService) CreateBooking(booking *store.Booking) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHotelForUpdate", tx, hotelId)
-	ret0, _ := ret[0].(store.Hotel)
+	ret := m.ctrl.Call(m, "CreateBooking", booking)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetHotelForUpdate indicates an expected call of GetHotelForUpdate.
-func (mr *MockStorageServiceMockRecorder) GetHotelForUpdate(tx, hotelId interface{}) *gomock.Call {
+// CreateBooking indicates an expected call of CreateBooking.
+func (mr *MockStorageServiceMockRecorder) CreateBooking(booking interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelForUpdate", reflect.TypeOf((*MockStorageService)(nil).GetHotelForUpdate), tx, hotelId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooking", reflect.TypeOf((*MockStorageService)(nil).CreateBooking), booking)
 }
 
-// GetHotels mocks base method.
-func (m *MockStorageService) GetHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) ([]store.Hotel, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetHotels", getHotelsListReq)
-	ret0, _ := ret[0].([]store.Hotel)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+// CreateBookingModificationTx mocks base method.
+func (m_2 *MockStorageService) CreateBookingModificationTx(tx *sql.Tx, m *store.BookingModification) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "CreateBookingModificationTx", tx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetHotels indicates an expected call of GetHotels.
-func (mr *MockStorageServiceMockRecorder) GetHotels(getHotelsListReq interface{}) *gomock.Call {
+// CreateBookingModificationTx indicates an expected call of CreateBookingModificationTx.
+func (mr *MockStorageServiceMockRecorder) CreateBookingModificationTx(tx, m interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotels", reflect.TypeOf((*MockStorageService)(nil).GetHotels), getHotelsListReq)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBookingModificationTx", reflect.TypeOf((*MockStorageService)(nil).CreateBookingModificationTx), tx, m)
 }
 
-// GetIdempotentPayloadByKey mocks base method.
-func (m *MockStorageService) GetIdempotentPayloadByKey(key string) (*store.IdempotencyKey, error) {
+// CreateBookingTx mocks base method.
+func (m *MockStorageService) CreateBookingTx(tx *sql.Tx, booking *store.Booking) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIdempotentPayloadByKey", key)
-	ret0, _ := ret[0].(*store.IdempotencyKey)
+	ret := m.ctrl.Call(m, "CreateBookingTx", tx, booking)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetIdempotentPayloadByKey indicates an expected call of GetIdempotentPayloadByKey.
-func (mr *MockStorageServiceMockRecorder) GetIdempotentPayloadByKey(key interface{}) *gomock.Call {
+// CreateBookingTx indicates an expected call of CreateBookingTx.
+func (mr *MockStorageServiceMockRecorder) CreateBookingTx(tx, booking interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotentPayloadByKey", reflect.TypeOf((*MockStorageService)(nil).GetIdempotentPayloadByKey), key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBookingTx", reflect.TypeOf((*MockStorageService)(nil).CreateBookingTx), tx, booking)
 }
 
-// GetPaymentByBookingId mocks base method.
-func (m *MockStorageService) GetPaymentByBookingId(bookingId int64) (*store.Payment, error) {
+// CreateHotelImageTx mocks base method.
+func (m *MockStorageService) CreateHotelImageTx(tx *sql.Tx, image *store.HotelImage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPaymentByBookingId", bookingId)
-	ret0, _ := ret[0].(*store.Payment)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateHotelImageTx", tx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetPaymentByBookingId indicates an expected call of GetPaymentByBookingId.
-func (mr *MockStorageServiceMockRecorder) GetPaymentByBookingId(bookingId interface{}) *gomock.Call {
+// CreateHotelImageTx indicates an expected call of CreateHotelImageTx.
+func (mr *MockStorageServiceMockRecorder) CreateHotelImageTx(tx, image interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByBookingId", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByBookingId), bookingId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHotelImageTx", reflect.TypeOf((*MockStorageService)(nil).CreateHotelImageTx), tx, image)
 }
 
-// GetPaymentByCheckoutSessionId mocks base method.
-func (m *MockStorageService) GetPaymentByCheckoutSessionId(checkoutSessionId string) (*store.Payment, error) {
+// CreateIdempotencyKey mocks base method.
+func (m *MockStorageService) CreateIdempotencyKey(ik *store.IdempotencyKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPaymentByCheckoutSessionId", checkoutSessionId)
-	ret0, _ := ret[0].(*store.Payment)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ik)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetPaymentByCheckoutSessionId indicates an expected call of GetPaymentByCheckoutSessionId.
-func (mr *MockStorageServiceMockRecorder) GetPaymentByCheckoutSessionId(checkoutSessionId interface{}) *gomock.Call {
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStorageServiceMockRecorder) CreateIdempotencyKey(ik interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByCheckoutSessionId", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByCheckoutSessionId), checkoutSessionId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStorageService)(nil).CreateIdempotencyKey), ik)
 }
 
-// GetPaymentByCheckoutSessionIdTx mocks base method.
-func (m *MockStorageService) GetPaymentByCheckoutSessionIdTx(tx *sql.Tx, checkoutSessionId string) (*store.Payment, error) {
+// CreateLandmark mocks base method.
+func (m *MockStorageService) CreateLandmark(landmark *store.Landmark) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPaymentByCheckoutSessionIdTx", tx, checkoutSessionId)
-	ret0, _ := ret[0].(*store.Payment)
+	ret := m.ctrl.Call(m, "CreateLandmark", landmark)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPaymentByCheckoutSessionIdTx indicates an expected call of GetPaymentByCheckoutSessionIdTx.
-func (mr *MockStorageServiceMockRecorder) GetPaymentByCheckoutSessionIdTx(tx, checkoutSessionId interface{}) *gomock.Call {
+// CreateLandmark indicates an expected call of CreateLandmark.
+func (mr *MockStorageServiceMockRecorder) CreateLandmark(landmark interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByCheckoutSessionIdTx", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByCheckoutSessionIdTx), tx, checkoutSessionId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLandmark", reflect.TypeOf((*MockStorageService)(nil).CreateLandmark), landmark)
 }
 
-// GetUserById mocks base method.
-func (m *MockStorageService) GetUserById(id int) (store.User, error) {
+// CreateOutboxMessage mocks base method.
+func (m *MockStorageService) CreateOutboxMessage(message *store.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserById", id)
-	ret0, _ := ret[0].(store.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateOutboxMessage", message)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserById indicates an expected call of GetUserById.
-func (mr *MockStorageServiceMockRecorder) GetUserById(id interface{}) *gomock.Call {
+// CreateOutboxMessage indicates an expected call of CreateOutboxMessage.
+func (mr *MockStorageServiceMockRecorder) CreateOutboxMessage(message interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockStorageService)(nil).GetUserById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxMessage", reflect.TypeOf((*MockStorageService)(nil).CreateOutboxMessage), message)
 }
 
-// GetUserByUsername mocks base method.
-func (m *MockStorageService) GetUserByUsername(username string) (*store.User, error) {
+// CreateOutboxMessageTx mocks base method.
+func (m *MockStorageService) CreateOutboxMessageTx(tx *sql.Tx, message *store.OutboxMessage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", username)
-	ret0, _ := ret[0].(*store.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateOutboxMessageTx", tx, message)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockStorageServiceMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+// CreateOutboxMessageTx indicates an expected call of CreateOutboxMessageTx.
+func (mr *MockStorageServiceMockRecorder) CreateOutboxMessageTx(tx, message interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStorageService)(nil).GetUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxMessageTx", reflect.TypeOf((*MockStorageService)(nil).CreateOutboxMessageTx), tx, message)
 }
 
-// IncrementHotelRoomsTx mocks base method.
-func (m *MockStorageService) IncrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error {
+// CreatePayment mocks base method.
+func (m *MockStorageService) CreatePayment(payment *store.Payment) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrementHotelRoomsTx", tx, hotelId, numRooms)
+	ret := m.ctrl.Call(m, "CreatePayment", payment)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// IncrementHotelRoomsTx indicates an expected call of IncrementHotelRoomsTx.
-func (mr *MockStorageServiceMockRecorder) IncrementHotelRoomsTx(tx, hotelId, numRooms interface{}) *gomock.Call {
+// CreatePayment indicates an expected call of CreatePayment.
+func (mr *MockStorageServiceMockRecorder) CreatePayment(payment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementHotelRoomsTx", reflect.TypeOf((*MockStorageService)(nil).IncrementHotelRoomsTx), tx, hotelId, numRooms)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockStorageService)(nil).CreatePayment), payment)
 }
 
-// UpdateBookingStatus mocks base method.
-func (m *MockStorageService) UpdateBookingStatus(bookingId int64, status store.BookingStatus) error {
+// CreatePaymentTx mocks base method.
+func (m *MockStorageService) CreatePaymentTx(tx *sql.Tx, payment *store.Payment) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBookingStatus", bookingId, status)
+	ret := m.ctrl.Call(m, "CreatePaymentTx", tx, payment)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateBookingStatus indicates an expected call of UpdateBookingStatus.
-func (mr *MockStorageServiceMockRecorder) UpdateBookingStatus(bookingId, status interface{}) *gomock.Call {
+// CreatePaymentTx indicates an expected call of CreatePaymentTx.
+func (mr *MockStorageServiceMockRecorder) CreatePaymentTx(tx, payment interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStatus", reflect.TypeOf((*MockStorageService)(nil).UpdateBookingStatus), bookingId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentTx", reflect.TypeOf((*MockStorageService)(nil).CreatePaymentTx), tx, payment)
 }
 
-// UpdateBookingStatusTx mocks base method.
-func (m *MockStorageService) UpdateBookingStatusTx(tx *sql.Tx, bookingId int64, status store.BookingStatus) error {
+// CreateRecoveryCodesTx mocks base method.
+func (m *MockStorageService) CreateRecoveryCodesTx(tx *sql.Tx, userId int, codeHashes []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBookingStatusTx", tx, bookingId, status)
+	ret := m.ctrl.Call(m, "CreateRecoveryCodesTx", tx, userId, codeHashes)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateBookingStatusTx indicates an expected call of UpdateBookingStatusTx.
-func (mr *MockStorageServiceMockRecorder) UpdateBookingStatusTx(tx, bookingId, status interface{}) *gomock.Call {
+// CreateRecoveryCodesTx indicates an expected call of CreateRecoveryCodesTx.
+func (mr *MockStorageServiceMockRecorder) CreateRecoveryCodesTx(tx, userId, codeHashes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStatusTx", reflect.TypeOf((*MockStorageService)(nil).UpdateBookingStatusTx), tx, bookingId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCodesTx", reflect.TypeOf((*MockStorageService)(nil).CreateRecoveryCodesTx), tx, userId, codeHashes)
 }
 
-// UpdateHotelRooms mocks base method.
-func (m *MockStorageService) UpdateHotelRooms(hotelId int64, newRoomCount int) error {
+// CreateRefreshToken mocks base method.
+func (m *MockStorageService) CreateRefreshToken(token *store.RefreshToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateHotelRooms", hotelId, newRoomCount)
+	ret := m.ctrl.Call(m, "CreateRefreshToken", token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateHotelRooms indicates an expected call of UpdateHotelRooms.
-func (mr *MockStorageServiceMockRecorder) UpdateHotelRooms(hotelId, newRoomCount interface{}) *gomock.Call {
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockStorageServiceMockRecorder) CreateRefreshToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelRooms", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelRooms), hotelId, newRoomCount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockStorageService)(nil).CreateRefreshToken), token)
 }
 
-// UpdateHotelRoomsTx mocks base method.
-func (m *MockStorageService) UpdateHotelRoomsTx(tx *sql.Tx, hotelId int64, newRoomCount int) error {
+// CreateRefreshTokenTx mocks base method.
+func (m *MockStorageService) CreateRefreshTokenTx(tx *sql.Tx, token *store.RefreshToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateHotelRoomsTx", tx, hotelId, newRoomCount)
+	ret := m.ctrl.Call(m, "CreateRefreshTokenTx", tx, token)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateHotelRoomsTx indicates an expected call of UpdateHotelRoomsTx.
-func (mr *MockStorageServiceMockRecorder) UpdateHotelRoomsTx(tx, hotelId, newRoomCount interface{}) *gomock.Call {
+// CreateRefreshTokenTx indicates an expected call of CreateRefreshTokenTx.
+func (mr *MockStorageServiceMockRecorder) CreateRefreshTokenTx(tx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelRoomsTx", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelRoomsTx), tx, hotelId, newRoomCount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshTokenTx", reflect.TypeOf((*MockStorageService)(nil).CreateRefreshTokenTx), tx, token)
 }
 
-// UpdatePaymentStatus mocks base method.
-func (m *MockStorageService) UpdatePaymentStatus(paymentId int, status constants.PaymentStatus) error {
+// CreateRefund mocks base method.
+func (m *MockStorageService) CreateRefund(refund *store.Refund) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePaymentStatus", paymentId, status)
+	ret := m.ctrl.Call(m, "CreateRefund", refund)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdatePaymentStatus indicates an expected call of UpdatePaymentStatus.
-func (mr *MockStorageServiceMockRecorder) UpdatePaymentStatus(paymentId, status interface{}) *gomock.Call {
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockStorageServiceMockRecorder) CreateRefund(refund interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatus", reflect.TypeOf((*MockStorageService)(nil).UpdatePaymentStatus), paymentId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockStorageService)(nil).CreateRefund), refund)
 }
 
-// UpdatePaymentStatusTx mocks base method.
-func (m *MockStorageService) UpdatePaymentStatusTx(tx *sql.Tx, paymentId int, status constants.PaymentStatus) error {
+// CreateRefundTx mocks base method.
+func (m *MockStorageService) CreateRefundTx(tx *sql.Tx, refund *store.Refund) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePaymentStatusTx", tx, paymentId, status)
+	ret := m.ctrl.Call(m, "CreateRefundTx", tx, refund)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdatePaymentStatusTx indicates an expected call of UpdatePaymentStatusTx.
-func (mr *MockStorageServiceMockRecorder) UpdatePaymentStatusTx(tx, paymentId, status interface{}) *gomock.Call {
+// CreateRefundTx indicates an expected call of CreateRefundTx.
+func (mr *MockStorageServiceMockRecorder) CreateRefundTx(tx, refund interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatusTx", reflect.TypeOf((*MockStorageService)(nil).UpdatePaymentStatusTx), tx, paymentId, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefundTx", reflect.TypeOf((*MockStorageService)(nil).CreateRefundTx), tx, refund)
+}
+
+// CreateReviewTx mocks base method.
+func (m *MockStorageService) CreateReviewTx(tx *sql.Tx, review *store.Review) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReviewTx", tx, review)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReviewTx indicates an expected call of CreateReviewTx.
+func (mr *MockStorageServiceMockRecorder) CreateReviewTx(tx, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReviewTx", reflect.TypeOf((*MockStorageService)(nil).CreateReviewTx), tx, review)
+}
+
+// CreateRoomTypeTx mocks base method.
+func (m *MockStorageService) CreateRoomTypeTx(tx *sql.Tx, roomType *store.RoomType) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRoomTypeTx", tx, roomType)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRoomTypeTx indicates an expected call of CreateRoomTypeTx.
+func (mr *MockStorageServiceMockRecorder) CreateRoomTypeTx(tx, roomType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoomTypeTx", reflect.TypeOf((*MockStorageService)(nil).CreateRoomTypeTx), tx, roomType)
+}
+
+// CreateUserTokenTx mocks base method.
+func (m *MockStorageService) CreateUserTokenTx(tx *sql.Tx, token *store.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTokenTx", tx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUserTokenTx indicates an expected call of CreateUserTokenTx.
+func (mr *MockStorageServiceMockRecorder) CreateUserTokenTx(tx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTokenTx", reflect.TypeOf((*MockStorageService)(nil).CreateUserTokenTx), tx, token)
+}
+
+// CreateWebhookEvent mocks base method.
+func (m *MockStorageService) CreateWebhookEvent(event *store.WebhookEvent) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookEvent", event)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookEvent indicates an expected call of CreateWebhookEvent.
+func (mr *MockStorageServiceMockRecorder) CreateWebhookEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookEvent", reflect.TypeOf((*MockStorageService)(nil).CreateWebhookEvent), event)
+}
+
+// DeleteAmenity mocks base method.
+func (m *MockStorageService) DeleteAmenity(code string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAmenity", code)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteAmenity indicates an expected call of DeleteAmenity.
+func (mr *MockStorageServiceMockRecorder) DeleteAmenity(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAmenity", reflect.TypeOf((*MockStorageService)(nil).DeleteAmenity), code)
+}
+
+// DeleteHotelImageTx mocks base method.
+func (m *MockStorageService) DeleteHotelImageTx(tx *sql.Tx, hotelId int64, imageId string) (*store.HotelImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHotelImageTx", tx, hotelId, imageId)
+	ret0, _ := ret[0].(*store.HotelImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteHotelImageTx indicates an expected call of DeleteHotelImageTx.
+func (mr *MockStorageServiceMockRecorder) DeleteHotelImageTx(tx, hotelId, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHotelImageTx", reflect.TypeOf((*MockStorageService)(nil).DeleteHotelImageTx), tx, hotelId, imageId)
+}
+
+// DeleteRecoveryCodesTx mocks base method.
+func (m *MockStorageService) DeleteRecoveryCodesTx(tx *sql.Tx, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodesTx", tx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodesTx indicates an expected call of DeleteRecoveryCodesTx.
+func (mr *MockStorageServiceMockRecorder) DeleteRecoveryCodesTx(tx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodesTx", reflect.TypeOf((*MockStorageService)(nil).DeleteRecoveryCodesTx), tx, userId)
+}
+
+// DeleteUserTOTPTx mocks base method.
+func (m *MockStorageService) DeleteUserTOTPTx(tx *sql.Tx, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTPTx", tx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTPTx indicates an expected call of DeleteUserTOTPTx.
+func (mr *MockStorageServiceMockRecorder) DeleteUserTOTPTx(tx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTPTx", reflect.TypeOf((*MockStorageService)(nil).DeleteUserTOTPTx), tx, userId)
+}
+
+// EnableUserTOTPTx mocks base method.
+func (m *MockStorageService) EnableUserTOTPTx(tx *sql.Tx, userId int, lastUsedStep int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTOTPTx", tx, userId, lastUsedStep)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTOTPTx indicates an expected call of EnableUserTOTPTx.
+func (mr *MockStorageServiceMockRecorder) EnableUserTOTPTx(tx, userId, lastUsedStep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTOTPTx", reflect.TypeOf((*MockStorageService)(nil).EnableUserTOTPTx), tx, userId, lastUsedStep)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockStorageService) GetAPIKeyByHash(keyHash string) (*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", keyHash)
+	ret0, _ := ret[0].(*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockStorageServiceMockRecorder) GetAPIKeyByHash(keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockStorageService)(nil).GetAPIKeyByHash), keyHash)
+}
+
+// GetAPIKeysByUserId mocks base method.
+func (m *MockStorageService) GetAPIKeysByUserId(userId int) ([]*store.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeysByUserId", userId)
+	ret0, _ := ret[0].([]*store.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeysByUserId indicates an expected call of GetAPIKeysByUserId.
+func (mr *MockStorageServiceMockRecorder) GetAPIKeysByUserId(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysByUserId", reflect.TypeOf((*MockStorageService)(nil).GetAPIKeysByUserId), userId)
+}
+
+// GetAmenities mocks base method.
+func (m *MockStorageService) GetAmenities() ([]*store.Amenity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAmenities")
+	ret0, _ := ret[0].([]*store.Amenity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAmenities indicates an expected call of GetAmenities.
+func (mr *MockStorageServiceMockRecorder) GetAmenities() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAmenities", reflect.TypeOf((*MockStorageService)(nil).GetAmenities))
+}
+
+// GetAmenitiesByCodes mocks base method.
+func (m *MockStorageService) GetAmenitiesByCodes(codes []string) ([]*store.Amenity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAmenitiesByCodes", codes)
+	ret0, _ := ret[0].([]*store.Amenity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAmenitiesByCodes indicates an expected call of GetAmenitiesByCodes.
+func (mr *MockStorageServiceMockRecorder) GetAmenitiesByCodes(codes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAmenitiesByCodes", reflect.TypeOf((*MockStorageService)(nil).GetAmenitiesByCodes), codes)
+}
+
+// GetAuthAuditEvents mocks base method.
+func (m *MockStorageService) GetAuthAuditEvents(username string, limit, offset int32) ([]*store.AuthAuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthAuditEvents", username, limit, offset)
+	ret0, _ := ret[0].([]*store.AuthAuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthAuditEvents indicates an expected call of GetAuthAuditEvents.
+func (mr *MockStorageServiceMockRecorder) GetAuthAuditEvents(username, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthAuditEvents", reflect.TypeOf((*MockStorageService)(nil).GetAuthAuditEvents), username, limit, offset)
+}
+
+// GetAvailableHotels mocks base method.
+func (m *MockStorageService) GetAvailableHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) ([]*store.AvailableHotel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAvailableHotels", getHotelsListReq)
+	ret0, _ := ret[0].([]*store.AvailableHotel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAvailableHotels indicates an expected call of GetAvailableHotels.
+func (mr *MockStorageServiceMockRecorder) GetAvailableHotels(getHotelsListReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailableHotels", reflect.TypeOf((*MockStorageService)(nil).GetAvailableHotels), getHotelsListReq)
+}
+
+// GetBookingById mocks base method.
+func (m *MockStorageService) GetBookingById(bookingId int64) (*store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingById", bookingId)
+	ret0, _ := ret[0].(*store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingById indicates an expected call of GetBookingById.
+func (mr *MockStorageServiceMockRecorder) GetBookingById(bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingById", reflect.TypeOf((*MockStorageService)(nil).GetBookingById), bookingId)
+}
+
+// GetBookingByIdTx mocks base method.
+func (m *MockStorageService) GetBookingByIdTx(tx *sql.Tx, bookingId int64) (store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingByIdTx", tx, bookingId)
+	ret0, _ := ret[0].(store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingByIdTx indicates an expected call of GetBookingByIdTx.
+func (mr *MockStorageServiceMockRecorder) GetBookingByIdTx(tx, bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetBookingByIdTx), tx, bookingId)
+}
+
+// GetBookingByReference mocks base method.
+func (m *MockStorageService) GetBookingByReference(reference string) (*store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingByReference", reference)
+	ret0, _ := ret[0].(*store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingByReference indicates an expected call of GetBookingByReference.
+func (mr *MockStorageServiceMockRecorder) GetBookingByReference(reference interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingByReference", reflect.TypeOf((*MockStorageService)(nil).GetBookingByReference), reference)
+}
+
+// GetBookingModificationByPaymentIdTx mocks base method.
+func (m *MockStorageService) GetBookingModificationByPaymentIdTx(tx *sql.Tx, paymentId string) (*store.BookingModification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingModificationByPaymentIdTx", tx, paymentId)
+	ret0, _ := ret[0].(*store.BookingModification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingModificationByPaymentIdTx indicates an expected call of GetBookingModificationByPaymentIdTx.
+func (mr *MockStorageServiceMockRecorder) GetBookingModificationByPaymentIdTx(tx, paymentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingModificationByPaymentIdTx", reflect.TypeOf((*MockStorageService)(nil).GetBookingModificationByPaymentIdTx), tx, paymentId)
+}
+
+// GetBookingsByHotelId mocks base method.
+func (m *MockStorageService) GetBookingsByHotelId(hotelId int64, status store.BookingStatus, limit, offset int32) ([]*store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookingsByHotelId", hotelId, status, limit, offset)
+	ret0, _ := ret[0].([]*store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookingsByHotelId indicates an expected call of GetBookingsByHotelId.
+func (mr *MockStorageServiceMockRecorder) GetBookingsByHotelId(hotelId, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookingsByHotelId", reflect.TypeOf((*MockStorageService)(nil).GetBookingsByHotelId), hotelId, status, limit, offset)
+}
+
+// GetCancellationPolicy mocks base method.
+func (m *MockStorageService) GetCancellationPolicy(hotelId int64) (*store.CancellationPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCancellationPolicy", hotelId)
+	ret0, _ := ret[0].(*store.CancellationPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCancellationPolicy indicates an expected call of GetCancellationPolicy.
+func (mr *MockStorageServiceMockRecorder) GetCancellationPolicy(hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCancellationPolicy", reflect.TypeOf((*MockStorageService)(nil).GetCancellationPolicy), hotelId)
+}
+
+// GetCompletedBookings mocks base method.
+func (m *MockStorageService) GetCompletedBookings() ([]*store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCompletedBookings")
+	ret0, _ := ret[0].([]*store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCompletedBookings indicates an expected call of GetCompletedBookings.
+func (mr *MockStorageServiceMockRecorder) GetCompletedBookings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCompletedBookings", reflect.TypeOf((*MockStorageService)(nil).GetCompletedBookings))
+}
+
+// GetDueOutboxMessages mocks base method.
+func (m *MockStorageService) GetDueOutboxMessages(now time.Time, limit int) ([]*store.OutboxMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueOutboxMessages", now, limit)
+	ret0, _ := ret[0].([]*store.OutboxMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueOutboxMessages indicates an expected call of GetDueOutboxMessages.
+func (mr *MockStorageServiceMockRecorder) GetDueOutboxMessages(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueOutboxMessages", reflect.TypeOf((*MockStorageService)(nil).GetDueOutboxMessages), now, limit)
+}
+
+// GetDueWebhookEvents mocks base method.
+func (m *MockStorageService) GetDueWebhookEvents(now time.Time, limit int) ([]*store.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueWebhookEvents", now, limit)
+	ret0, _ := ret[0].([]*store.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueWebhookEvents indicates an expected call of GetDueWebhookEvents.
+func (mr *MockStorageServiceMockRecorder) GetDueWebhookEvents(now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueWebhookEvents", reflect.TypeOf((*MockStorageService)(nil).GetDueWebhookEvents), now, limit)
+}
+
+// GetExpiredBookings mocks base method.
+func (m *MockStorageService) GetExpiredBookings() ([]*store.Booking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredBookings")
+	ret0, _ := ret[0].([]*store.Booking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredBookings indicates an expected call of GetExpiredBookings.
+func (mr *MockStorageServiceMockRecorder) GetExpiredBookings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredBookings", reflect.TypeOf((*MockStorageService)(nil).GetExpiredBookings))
+}
+
+// GetHotelAmenities mocks base method.
+func (m *MockStorageService) GetHotelAmenities(hotelIds []int64) (map[int][]*store.Amenity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelAmenities", hotelIds)
+	ret0, _ := ret[0].(map[int][]*store.Amenity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelAmenities indicates an expected call of GetHotelAmenities.
+func (mr *MockStorageServiceMockRecorder) GetHotelAmenities(hotelIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelAmenities", reflect.TypeOf((*MockStorageService)(nil).GetHotelAmenities), hotelIds)
+}
+
+// GetHotelById mocks base method.
+func (m *MockStorageService) GetHotelById(id int64) (store.Hotel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelById", id)
+	ret0, _ := ret[0].(store.Hotel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelById indicates an expected call of GetHotelById.
+func (mr *MockStorageServiceMockRecorder) GetHotelById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelById", reflect.TypeOf((*MockStorageService)(nil).GetHotelById), id)
+}
+
+// GetHotelByIdTx mocks base method.
+func (m *MockStorageService) GetHotelByIdTx(tx *sql.Tx, hotelId int64) (store.Hotel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelByIdTx", tx, hotelId)
+	ret0, _ := ret[0].(store.Hotel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelByIdTx indicates an expected call of GetHotelByIdTx.
+func (mr *MockStorageServiceMockRecorder) GetHotelByIdTx(tx, hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetHotelByIdTx), tx, hotelId)
+}
+
+// GetHotelForUpdate mocks base method.
+func (m *MockStorageService) GetHotelForUpdate(tx *sql.Tx, hotelId int64) (store.Hotel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelForUpdate", tx, hotelId)
+	ret0, _ := ret[0].(store.Hotel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelForUpdate indicates an expected call of GetHotelForUpdate.
+func (mr *MockStorageServiceMockRecorder) GetHotelForUpdate(tx, hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelForUpdate", reflect.TypeOf((*MockStorageService)(nil).GetHotelForUpdate), tx, hotelId)
+}
+
+// GetHotelImages mocks base method.
+func (m *MockStorageService) GetHotelImages(hotelId int64) ([]*store.HotelImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelImages", hotelId)
+	ret0, _ := ret[0].([]*store.HotelImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelImages indicates an expected call of GetHotelImages.
+func (mr *MockStorageServiceMockRecorder) GetHotelImages(hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelImages", reflect.TypeOf((*MockStorageService)(nil).GetHotelImages), hotelId)
+}
+
+// GetHotelImagesTx mocks base method.
+func (m *MockStorageService) GetHotelImagesTx(tx *sql.Tx, hotelId int64) ([]*store.HotelImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelImagesTx", tx, hotelId)
+	ret0, _ := ret[0].([]*store.HotelImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelImagesTx indicates an expected call of GetHotelImagesTx.
+func (mr *MockStorageServiceMockRecorder) GetHotelImagesTx(tx, hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelImagesTx", reflect.TypeOf((*MockStorageService)(nil).GetHotelImagesTx), tx, hotelId)
+}
+
+// GetHotelReviews mocks base method.
+func (m *MockStorageService) GetHotelReviews(hotelId int64, includeHidden bool, limit, offset int32) ([]*store.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotelReviews", hotelId, includeHidden, limit, offset)
+	ret0, _ := ret[0].([]*store.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotelReviews indicates an expected call of GetHotelReviews.
+func (mr *MockStorageServiceMockRecorder) GetHotelReviews(hotelId, includeHidden, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotelReviews", reflect.TypeOf((*MockStorageService)(nil).GetHotelReviews), hotelId, includeHidden, limit, offset)
+}
+
+// GetHotels mocks base method.
+func (m *MockStorageService) GetHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) ([]store.Hotel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHotels", getHotelsListReq)
+	ret0, _ := ret[0].([]store.Hotel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHotels indicates an expected call of GetHotels.
+func (mr *MockStorageServiceMockRecorder) GetHotels(getHotelsListReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHotels", reflect.TypeOf((*MockStorageService)(nil).GetHotels), getHotelsListReq)
+}
+
+// GetIdempotentPayloadByKey mocks base method.
+func (m *MockStorageService) GetIdempotentPayloadByKey(key string) (*store.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotentPayloadByKey", key)
+	ret0, _ := ret[0].(*store.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotentPayloadByKey indicates an expected call of GetIdempotentPayloadByKey.
+func (mr *MockStorageServiceMockRecorder) GetIdempotentPayloadByKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotentPayloadByKey", reflect.TypeOf((*MockStorageService)(nil).GetIdempotentPayloadByKey), key)
+}
+
+// GetLandmarkByName mocks base method.
+func (m *MockStorageService) GetLandmarkByName(name, city string) (*store.Landmark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLandmarkByName", name, city)
+	ret0, _ := ret[0].(*store.Landmark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLandmarkByName indicates an expected call of GetLandmarkByName.
+func (mr *MockStorageServiceMockRecorder) GetLandmarkByName(name, city interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLandmarkByName", reflect.TypeOf((*MockStorageService)(nil).GetLandmarkByName), name, city)
+}
+
+// GetLandmarks mocks base method.
+func (m *MockStorageService) GetLandmarks(city string) ([]*store.Landmark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLandmarks", city)
+	ret0, _ := ret[0].([]*store.Landmark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLandmarks indicates an expected call of GetLandmarks.
+func (mr *MockStorageServiceMockRecorder) GetLandmarks(city interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLandmarks", reflect.TypeOf((*MockStorageService)(nil).GetLandmarks), city)
+}
+
+// GetLoginThrottles mocks base method.
+func (m *MockStorageService) GetLoginThrottles(keys []string) ([]*store.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginThrottles", keys)
+	ret0, _ := ret[0].([]*store.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginThrottles indicates an expected call of GetLoginThrottles.
+func (mr *MockStorageServiceMockRecorder) GetLoginThrottles(keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginThrottles", reflect.TypeOf((*MockStorageService)(nil).GetLoginThrottles), keys)
+}
+
+// GetMaxRoomsSoldByRoomType mocks base method.
+func (m *MockStorageService) GetMaxRoomsSoldByRoomType(hotelId int64, checkIn, checkOut time.Time) (map[int]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxRoomsSoldByRoomType", hotelId, checkIn, checkOut)
+	ret0, _ := ret[0].(map[int]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxRoomsSoldByRoomType indicates an expected call of GetMaxRoomsSoldByRoomType.
+func (mr *MockStorageServiceMockRecorder) GetMaxRoomsSoldByRoomType(hotelId, checkIn, checkOut interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxRoomsSoldByRoomType", reflect.TypeOf((*MockStorageService)(nil).GetMaxRoomsSoldByRoomType), hotelId, checkIn, checkOut)
+}
+
+// GetMaxRoomsSoldTx mocks base method.
+func (m *MockStorageService) GetMaxRoomsSoldTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxRoomsSoldTx", tx, roomTypeId, checkIn, checkOut)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxRoomsSoldTx indicates an expected call of GetMaxRoomsSoldTx.
+func (mr *MockStorageServiceMockRecorder) GetMaxRoomsSoldTx(tx, roomTypeId, checkIn, checkOut interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxRoomsSoldTx", reflect.TypeOf((*MockStorageService)(nil).GetMaxRoomsSoldTx), tx, roomTypeId, checkIn, checkOut)
+}
+
+// GetPaymentByBookingId mocks base method.
+func (m *MockStorageService) GetPaymentByBookingId(bookingId int64) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByBookingId", bookingId)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByBookingId indicates an expected call of GetPaymentByBookingId.
+func (mr *MockStorageServiceMockRecorder) GetPaymentByBookingId(bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByBookingId", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByBookingId), bookingId)
+}
+
+// GetPaymentByCheckoutSessionId mocks base method.
+func (m *MockStorageService) GetPaymentByCheckoutSessionId(checkoutSessionId string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByCheckoutSessionId", checkoutSessionId)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByCheckoutSessionId indicates an expected call of GetPaymentByCheckoutSessionId.
+func (mr *MockStorageServiceMockRecorder) GetPaymentByCheckoutSessionId(checkoutSessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByCheckoutSessionId", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByCheckoutSessionId), checkoutSessionId)
+}
+
+// GetPaymentByCheckoutSessionIdTx mocks base method.
+func (m *MockStorageService) GetPaymentByCheckoutSessionIdTx(tx *sql.Tx, checkoutSessionId string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByCheckoutSessionIdTx", tx, checkoutSessionId)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByCheckoutSessionIdTx indicates an expected call of GetPaymentByCheckoutSessionIdTx.
+func (mr *MockStorageServiceMockRecorder) GetPaymentByCheckoutSessionIdTx(tx, checkoutSessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByCheckoutSessionIdTx", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByCheckoutSessionIdTx), tx, checkoutSessionId)
+}
+
+// GetPaymentById mocks base method.
+func (m *MockStorageService) GetPaymentById(paymentId string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentById", paymentId)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentById indicates an expected call of GetPaymentById.
+func (mr *MockStorageServiceMockRecorder) GetPaymentById(paymentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentById", reflect.TypeOf((*MockStorageService)(nil).GetPaymentById), paymentId)
+}
+
+// GetPaymentByIdTx mocks base method.
+func (m *MockStorageService) GetPaymentByIdTx(tx *sql.Tx, paymentId string) (*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByIdTx", tx, paymentId)
+	ret0, _ := ret[0].(*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByIdTx indicates an expected call of GetPaymentByIdTx.
+func (mr *MockStorageServiceMockRecorder) GetPaymentByIdTx(tx, paymentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetPaymentByIdTx), tx, paymentId)
+}
+
+// GetPaymentsByBookingIdTx mocks base method.
+func (m *MockStorageService) GetPaymentsByBookingIdTx(tx *sql.Tx, bookingId int64) ([]*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentsByBookingIdTx", tx, bookingId)
+	ret0, _ := ret[0].([]*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentsByBookingIdTx indicates an expected call of GetPaymentsByBookingIdTx.
+func (mr *MockStorageServiceMockRecorder) GetPaymentsByBookingIdTx(tx, bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentsByBookingIdTx", reflect.TypeOf((*MockStorageService)(nil).GetPaymentsByBookingIdTx), tx, bookingId)
+}
+
+// GetPeakRoomsSoldSinceTx mocks base method.
+func (m *MockStorageService) GetPeakRoomsSoldSinceTx(tx *sql.Tx, roomTypeId int64, from time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPeakRoomsSoldSinceTx", tx, roomTypeId, from)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPeakRoomsSoldSinceTx indicates an expected call of GetPeakRoomsSoldSinceTx.
+func (mr *MockStorageServiceMockRecorder) GetPeakRoomsSoldSinceTx(tx, roomTypeId, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeakRoomsSoldSinceTx", reflect.TypeOf((*MockStorageService)(nil).GetPeakRoomsSoldSinceTx), tx, roomTypeId, from)
+}
+
+// GetPendingBookingModificationTx mocks base method.
+func (m *MockStorageService) GetPendingBookingModificationTx(tx *sql.Tx, bookingId int64) (*store.BookingModification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingBookingModificationTx", tx, bookingId)
+	ret0, _ := ret[0].(*store.BookingModification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingBookingModificationTx indicates an expected call of GetPendingBookingModificationTx.
+func (mr *MockStorageServiceMockRecorder) GetPendingBookingModificationTx(tx, bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingBookingModificationTx", reflect.TypeOf((*MockStorageService)(nil).GetPendingBookingModificationTx), tx, bookingId)
+}
+
+// GetPendingBookingModificationsCreatedBefore mocks base method.
+func (m *MockStorageService) GetPendingBookingModificationsCreatedBefore(createdBefore time.Time) ([]*store.BookingModification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingBookingModificationsCreatedBefore", createdBefore)
+	ret0, _ := ret[0].([]*store.BookingModification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingBookingModificationsCreatedBefore indicates an expected call of GetPendingBookingModificationsCreatedBefore.
+func (mr *MockStorageServiceMockRecorder) GetPendingBookingModificationsCreatedBefore(createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingBookingModificationsCreatedBefore", reflect.TypeOf((*MockStorageService)(nil).GetPendingBookingModificationsCreatedBefore), createdBefore)
+}
+
+// GetPendingPaymentsCreatedBefore mocks base method.
+func (m *MockStorageService) GetPendingPaymentsCreatedBefore(createdBefore time.Time) ([]*store.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingPaymentsCreatedBefore", createdBefore)
+	ret0, _ := ret[0].([]*store.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingPaymentsCreatedBefore indicates an expected call of GetPendingPaymentsCreatedBefore.
+func (mr *MockStorageServiceMockRecorder) GetPendingPaymentsCreatedBefore(createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingPaymentsCreatedBefore", reflect.TypeOf((*MockStorageService)(nil).GetPendingPaymentsCreatedBefore), createdBefore)
+}
+
+// GetPendingRefunds mocks base method.
+func (m *MockStorageService) GetPendingRefunds() ([]*store.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRefunds")
+	ret0, _ := ret[0].([]*store.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRefunds indicates an expected call of GetPendingRefunds.
+func (mr *MockStorageServiceMockRecorder) GetPendingRefunds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRefunds", reflect.TypeOf((*MockStorageService)(nil).GetPendingRefunds))
+}
+
+// GetRefreshTokenByHashTx mocks base method.
+func (m *MockStorageService) GetRefreshTokenByHashTx(tx *sql.Tx, tokenHash string) (*store.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHashTx", tx, tokenHash)
+	ret0, _ := ret[0].(*store.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHashTx indicates an expected call of GetRefreshTokenByHashTx.
+func (mr *MockStorageServiceMockRecorder) GetRefreshTokenByHashTx(tx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHashTx", reflect.TypeOf((*MockStorageService)(nil).GetRefreshTokenByHashTx), tx, tokenHash)
+}
+
+// GetRefundById mocks base method.
+func (m *MockStorageService) GetRefundById(refundId string) (*store.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundById", refundId)
+	ret0, _ := ret[0].(*store.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundById indicates an expected call of GetRefundById.
+func (mr *MockStorageServiceMockRecorder) GetRefundById(refundId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundById", reflect.TypeOf((*MockStorageService)(nil).GetRefundById), refundId)
+}
+
+// GetRefundByIdTx mocks base method.
+func (m *MockStorageService) GetRefundByIdTx(tx *sql.Tx, refundId string) (*store.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundByIdTx", tx, refundId)
+	ret0, _ := ret[0].(*store.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundByIdTx indicates an expected call of GetRefundByIdTx.
+func (mr *MockStorageServiceMockRecorder) GetRefundByIdTx(tx, refundId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetRefundByIdTx), tx, refundId)
+}
+
+// GetRefundsByBookingId mocks base method.
+func (m *MockStorageService) GetRefundsByBookingId(bookingId int64) ([]*store.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByBookingId", bookingId)
+	ret0, _ := ret[0].([]*store.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByBookingId indicates an expected call of GetRefundsByBookingId.
+func (mr *MockStorageServiceMockRecorder) GetRefundsByBookingId(bookingId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByBookingId", reflect.TypeOf((*MockStorageService)(nil).GetRefundsByBookingId), bookingId)
+}
+
+// GetReviewById mocks base method.
+func (m *MockStorageService) GetReviewById(reviewId int64) (*store.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewById", reviewId)
+	ret0, _ := ret[0].(*store.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewById indicates an expected call of GetReviewById.
+func (mr *MockStorageServiceMockRecorder) GetReviewById(reviewId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewById", reflect.TypeOf((*MockStorageService)(nil).GetReviewById), reviewId)
+}
+
+// GetRoomTypeAmenities mocks base method.
+func (m *MockStorageService) GetRoomTypeAmenities(hotelId int64) (map[int][]*store.Amenity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomTypeAmenities", hotelId)
+	ret0, _ := ret[0].(map[int][]*store.Amenity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomTypeAmenities indicates an expected call of GetRoomTypeAmenities.
+func (mr *MockStorageServiceMockRecorder) GetRoomTypeAmenities(hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomTypeAmenities", reflect.TypeOf((*MockStorageService)(nil).GetRoomTypeAmenities), hotelId)
+}
+
+// GetRoomTypeByIdTx mocks base method.
+func (m *MockStorageService) GetRoomTypeByIdTx(tx *sql.Tx, hotelId, roomTypeId int64) (*store.RoomType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomTypeByIdTx", tx, hotelId, roomTypeId)
+	ret0, _ := ret[0].(*store.RoomType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomTypeByIdTx indicates an expected call of GetRoomTypeByIdTx.
+func (mr *MockStorageServiceMockRecorder) GetRoomTypeByIdTx(tx, hotelId, roomTypeId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomTypeByIdTx", reflect.TypeOf((*MockStorageService)(nil).GetRoomTypeByIdTx), tx, hotelId, roomTypeId)
+}
+
+// GetRoomTypesByHotelId mocks base method.
+func (m *MockStorageService) GetRoomTypesByHotelId(hotelId int64) ([]*store.RoomType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomTypesByHotelId", hotelId)
+	ret0, _ := ret[0].([]*store.RoomType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomTypesByHotelId indicates an expected call of GetRoomTypesByHotelId.
+func (mr *MockStorageServiceMockRecorder) GetRoomTypesByHotelId(hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomTypesByHotelId", reflect.TypeOf((*MockStorageService)(nil).GetRoomTypesByHotelId), hotelId)
+}
+
+// GetUserBookings mocks base method.
+func (m *MockStorageService) GetUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) ([]*store.UserBooking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserBookings", userId, req, today)
+	ret0, _ := ret[0].([]*store.UserBooking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserBookings indicates an expected call of GetUserBookings.
+func (mr *MockStorageServiceMockRecorder) GetUserBookings(userId, req, today interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBookings", reflect.TypeOf((*MockStorageService)(nil).GetUserBookings), userId, req, today)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStorageService) GetUserByEmail(email string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStorageServiceMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStorageService)(nil).GetUserByEmail), email)
+}
+
+// GetUserById mocks base method.
+func (m *MockStorageService) GetUserById(id int) (store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserById", id)
+	ret0, _ := ret[0].(store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserById indicates an expected call of GetUserById.
+func (mr *MockStorageServiceMockRecorder) GetUserById(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockStorageService)(nil).GetUserById), id)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockStorageService) GetUserByUsername(username string) (*store.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", username)
+	ret0, _ := ret[0].(*store.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockStorageServiceMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStorageService)(nil).GetUserByUsername), username)
+}
+
+// GetUserTOTP mocks base method.
+func (m *MockStorageService) GetUserTOTP(userId int) (*store.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTP", userId)
+	ret0, _ := ret[0].(*store.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTP indicates an expected call of GetUserTOTP.
+func (mr *MockStorageServiceMockRecorder) GetUserTOTP(userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTP", reflect.TypeOf((*MockStorageService)(nil).GetUserTOTP), userId)
+}
+
+// GetUserTokenByHashTx mocks base method.
+func (m *MockStorageService) GetUserTokenByHashTx(tx *sql.Tx, purpose constants.UserTokenPurpose, tokenHash string) (*store.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTokenByHashTx", tx, purpose, tokenHash)
+	ret0, _ := ret[0].(*store.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTokenByHashTx indicates an expected call of GetUserTokenByHashTx.
+func (mr *MockStorageServiceMockRecorder) GetUserTokenByHashTx(tx, purpose, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTokenByHashTx", reflect.TypeOf((*MockStorageService)(nil).GetUserTokenByHashTx), tx, purpose, tokenHash)
+}
+
+// GetWebhookEventById mocks base method.
+func (m *MockStorageService) GetWebhookEventById(eventId string) (*store.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEventById", eventId)
+	ret0, _ := ret[0].(*store.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEventById indicates an expected call of GetWebhookEventById.
+func (mr *MockStorageServiceMockRecorder) GetWebhookEventById(eventId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEventById", reflect.TypeOf((*MockStorageService)(nil).GetWebhookEventById), eventId)
+}
+
+// GetWebhookEvents mocks base method.
+func (m *MockStorageService) GetWebhookEvents(status constants.WebhookEventStatus, limit, offset int32) ([]*store.WebhookEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookEvents", status, limit, offset)
+	ret0, _ := ret[0].([]*store.WebhookEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookEvents indicates an expected call of GetWebhookEvents.
+func (mr *MockStorageServiceMockRecorder) GetWebhookEvents(status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookEvents", reflect.TypeOf((*MockStorageService)(nil).GetWebhookEvents), status, limit, offset)
+}
+
+// IsHotelStaff mocks base method.
+func (m *MockStorageService) IsHotelStaff(hotelId int64, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsHotelStaff", hotelId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsHotelStaff indicates an expected call of IsHotelStaff.
+func (mr *MockStorageServiceMockRecorder) IsHotelStaff(hotelId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsHotelStaff", reflect.TypeOf((*MockStorageService)(nil).IsHotelStaff), hotelId, userId)
+}
+
+// LockLoginKey mocks base method.
+func (m *MockStorageService) LockLoginKey(key string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginKey", key, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLoginKey indicates an expected call of LockLoginKey.
+func (mr *MockStorageServiceMockRecorder) LockLoginKey(key, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginKey", reflect.TypeOf((*MockStorageService)(nil).LockLoginKey), key, until)
+}
+
+// MarkUserEmailVerifiedTx mocks base method.
+func (m *MockStorageService) MarkUserEmailVerifiedTx(tx *sql.Tx, userId int, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUserEmailVerifiedTx", tx, userId, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUserEmailVerifiedTx indicates an expected call of MarkUserEmailVerifiedTx.
+func (mr *MockStorageServiceMockRecorder) MarkUserEmailVerifiedTx(tx, userId, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUserEmailVerifiedTx", reflect.TypeOf((*MockStorageService)(nil).MarkUserEmailVerifiedTx), tx, userId, email)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockStorageService) RecordLoginFailure(key string, now, windowStart time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", key, now, windowStart)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockStorageServiceMockRecorder) RecordLoginFailure(key, now, windowStart interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockStorageService)(nil).RecordLoginFailure), key, now, windowStart)
+}
+
+// RefreshHotelRoomSummaryTx mocks base method.
+func (m *MockStorageService) RefreshHotelRoomSummaryTx(tx *sql.Tx, hotelId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshHotelRoomSummaryTx", tx, hotelId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshHotelRoomSummaryTx indicates an expected call of RefreshHotelRoomSummaryTx.
+func (mr *MockStorageServiceMockRecorder) RefreshHotelRoomSummaryTx(tx, hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshHotelRoomSummaryTx", reflect.TypeOf((*MockStorageService)(nil).RefreshHotelRoomSummaryTx), tx, hotelId)
+}
+
+// ReleaseHotelInventoryTx mocks base method.
+func (m *MockStorageService) ReleaseHotelInventoryTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseHotelInventoryTx", tx, roomTypeId, checkIn, checkOut, numRooms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseHotelInventoryTx indicates an expected call of ReleaseHotelInventoryTx.
+func (mr *MockStorageServiceMockRecorder) ReleaseHotelInventoryTx(tx, roomTypeId, checkIn, checkOut, numRooms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseHotelInventoryTx", reflect.TypeOf((*MockStorageService)(nil).ReleaseHotelInventoryTx), tx, roomTypeId, checkIn, checkOut, numRooms)
+}
+
+// RemoveHotelStaff mocks base method.
+func (m *MockStorageService) RemoveHotelStaff(hotelId int64, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveHotelStaff", hotelId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveHotelStaff indicates an expected call of RemoveHotelStaff.
+func (mr *MockStorageServiceMockRecorder) RemoveHotelStaff(hotelId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHotelStaff", reflect.TypeOf((*MockStorageService)(nil).RemoveHotelStaff), hotelId, userId)
+}
+
+// ReorderHotelImagesTx mocks base method.
+func (m *MockStorageService) ReorderHotelImagesTx(tx *sql.Tx, hotelId int64, imageIds []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderHotelImagesTx", tx, hotelId, imageIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderHotelImagesTx indicates an expected call of ReorderHotelImagesTx.
+func (mr *MockStorageServiceMockRecorder) ReorderHotelImagesTx(tx, hotelId, imageIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderHotelImagesTx", reflect.TypeOf((*MockStorageService)(nil).ReorderHotelImagesTx), tx, hotelId, imageIds)
+}
+
+// ReserveHotelInventoryTx mocks base method.
+func (m *MockStorageService) ReserveHotelInventoryTx(tx *sql.Tx, hotelId, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveHotelInventoryTx", tx, hotelId, roomTypeId, checkIn, checkOut, numRooms)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveHotelInventoryTx indicates an expected call of ReserveHotelInventoryTx.
+func (mr *MockStorageServiceMockRecorder) ReserveHotelInventoryTx(tx, hotelId, roomTypeId, checkIn, checkOut, numRooms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveHotelInventoryTx", reflect.TypeOf((*MockStorageService)(nil).ReserveHotelInventoryTx), tx, hotelId, roomTypeId, checkIn, checkOut, numRooms)
+}
+
+// ResetWebhookEvent mocks base method.
+func (m *MockStorageService) ResetWebhookEvent(eventId string, now time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetWebhookEvent", eventId, now)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetWebhookEvent indicates an expected call of ResetWebhookEvent.
+func (mr *MockStorageServiceMockRecorder) ResetWebhookEvent(eventId, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetWebhookEvent", reflect.TypeOf((*MockStorageService)(nil).ResetWebhookEvent), eventId, now)
+}
+
+// ResolveBookingModificationTx mocks base method.
+func (m *MockStorageService) ResolveBookingModificationTx(tx *sql.Tx, modificationId int, status store.ModificationStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveBookingModificationTx", tx, modificationId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResolveBookingModificationTx indicates an expected call of ResolveBookingModificationTx.
+func (mr *MockStorageServiceMockRecorder) ResolveBookingModificationTx(tx, modificationId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBookingModificationTx", reflect.TypeOf((*MockStorageService)(nil).ResolveBookingModificationTx), tx, modificationId, status)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockStorageService) RevokeAPIKey(keyId string, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", keyId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockStorageServiceMockRecorder) RevokeAPIKey(keyId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockStorageService)(nil).RevokeAPIKey), keyId, userId)
+}
+
+// RevokeRefreshTokenFamilyTx mocks base method.
+func (m *MockStorageService) RevokeRefreshTokenFamilyTx(tx *sql.Tx, familyId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamilyTx", tx, familyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamilyTx indicates an expected call of RevokeRefreshTokenFamilyTx.
+func (mr *MockStorageServiceMockRecorder) RevokeRefreshTokenFamilyTx(tx, familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamilyTx", reflect.TypeOf((*MockStorageService)(nil).RevokeRefreshTokenFamilyTx), tx, familyId)
+}
+
+// RevokeRefreshTokenTx mocks base method.
+func (m *MockStorageService) RevokeRefreshTokenTx(tx *sql.Tx, tokenId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenTx", tx, tokenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenTx indicates an expected call of RevokeRefreshTokenTx.
+func (mr *MockStorageServiceMockRecorder) RevokeRefreshTokenTx(tx, tokenId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenTx", reflect.TypeOf((*MockStorageService)(nil).RevokeRefreshTokenTx), tx, tokenId)
+}
+
+// RevokeUserRefreshTokensTx mocks base method.
+func (m *MockStorageService) RevokeUserRefreshTokensTx(tx *sql.Tx, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokensTx", tx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokensTx indicates an expected call of RevokeUserRefreshTokensTx.
+func (mr *MockStorageServiceMockRecorder) RevokeUserRefreshTokensTx(tx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokensTx", reflect.TypeOf((*MockStorageService)(nil).RevokeUserRefreshTokensTx), tx, userId)
+}
+
+// SetBookingLatePaymentResolutionTx mocks base method.
+func (m *MockStorageService) SetBookingLatePaymentResolutionTx(tx *sql.Tx, bookingId int64, resolution constants.LatePaymentResolution) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBookingLatePaymentResolutionTx", tx, bookingId, resolution)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBookingLatePaymentResolutionTx indicates an expected call of SetBookingLatePaymentResolutionTx.
+func (mr *MockStorageServiceMockRecorder) SetBookingLatePaymentResolutionTx(tx, bookingId, resolution interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBookingLatePaymentResolutionTx", reflect.TypeOf((*MockStorageService)(nil).SetBookingLatePaymentResolutionTx), tx, bookingId, resolution)
+}
+
+// SetHotelAmenitiesTx mocks base method.
+func (m *MockStorageService) SetHotelAmenitiesTx(tx *sql.Tx, hotelId int64, amenityIds []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHotelAmenitiesTx", tx, hotelId, amenityIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHotelAmenitiesTx indicates an expected call of SetHotelAmenitiesTx.
+func (mr *MockStorageServiceMockRecorder) SetHotelAmenitiesTx(tx, hotelId, amenityIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHotelAmenitiesTx", reflect.TypeOf((*MockStorageService)(nil).SetHotelAmenitiesTx), tx, hotelId, amenityIds)
+}
+
+// SetPaymentLatePaymentResolutionTx mocks base method.
+func (m *MockStorageService) SetPaymentLatePaymentResolutionTx(tx *sql.Tx, paymentId string, resolution constants.LatePaymentResolution) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPaymentLatePaymentResolutionTx", tx, paymentId, resolution)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPaymentLatePaymentResolutionTx indicates an expected call of SetPaymentLatePaymentResolutionTx.
+func (mr *MockStorageServiceMockRecorder) SetPaymentLatePaymentResolutionTx(tx, paymentId, resolution interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPaymentLatePaymentResolutionTx", reflect.TypeOf((*MockStorageService)(nil).SetPaymentLatePaymentResolutionTx), tx, paymentId, resolution)
+}
+
+// SetReviewHiddenTx mocks base method.
+func (m *MockStorageService) SetReviewHiddenTx(tx *sql.Tx, reviewId int64, hidden bool) (*store.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReviewHiddenTx", tx, reviewId, hidden)
+	ret0, _ := ret[0].(*store.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetReviewHiddenTx indicates an expected call of SetReviewHiddenTx.
+func (mr *MockStorageServiceMockRecorder) SetReviewHiddenTx(tx, reviewId, hidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReviewHiddenTx", reflect.TypeOf((*MockStorageService)(nil).SetReviewHiddenTx), tx, reviewId, hidden)
+}
+
+// SetReviewReply mocks base method.
+func (m *MockStorageService) SetReviewReply(reviewId int64, reply string, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReviewReply", reviewId, reply, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetReviewReply indicates an expected call of SetReviewReply.
+func (mr *MockStorageServiceMockRecorder) SetReviewReply(reviewId, reply, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReviewReply", reflect.TypeOf((*MockStorageService)(nil).SetReviewReply), reviewId, reply, userId)
+}
+
+// SetRoomTypeAmenitiesTx mocks base method.
+func (m *MockStorageService) SetRoomTypeAmenitiesTx(tx *sql.Tx, roomTypeId int64, amenityIds []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoomTypeAmenitiesTx", tx, roomTypeId, amenityIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoomTypeAmenitiesTx indicates an expected call of SetRoomTypeAmenitiesTx.
+func (mr *MockStorageServiceMockRecorder) SetRoomTypeAmenitiesTx(tx, roomTypeId, amenityIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoomTypeAmenitiesTx", reflect.TypeOf((*MockStorageService)(nil).SetRoomTypeAmenitiesTx), tx, roomTypeId, amenityIds)
+}
+
+// SumRefundsByPaymentIdTx mocks base method.
+func (m *MockStorageService) SumRefundsByPaymentIdTx(tx *sql.Tx, paymentId string, statuses []constants.RefundStatus) (float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumRefundsByPaymentIdTx", tx, paymentId, statuses)
+	ret0, _ := ret[0].(float32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumRefundsByPaymentIdTx indicates an expected call of SumRefundsByPaymentIdTx.
+func (mr *MockStorageServiceMockRecorder) SumRefundsByPaymentIdTx(tx, paymentId, statuses interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumRefundsByPaymentIdTx", reflect.TypeOf((*MockStorageService)(nil).SumRefundsByPaymentIdTx), tx, paymentId, statuses)
+}
+
+// SyncHotelImageUrlsTx mocks base method.
+func (m *MockStorageService) SyncHotelImageUrlsTx(tx *sql.Tx, hotelId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncHotelImageUrlsTx", tx, hotelId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncHotelImageUrlsTx indicates an expected call of SyncHotelImageUrlsTx.
+func (mr *MockStorageServiceMockRecorder) SyncHotelImageUrlsTx(tx, hotelId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncHotelImageUrlsTx", reflect.TypeOf((*MockStorageService)(nil).SyncHotelImageUrlsTx), tx, hotelId)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockStorageService) TouchAPIKey(keyId string, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", keyId, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockStorageServiceMockRecorder) TouchAPIKey(keyId, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockStorageService)(nil).TouchAPIKey), keyId, now)
+}
+
+// UpdateAmenity mocks base method.
+func (m *MockStorageService) UpdateAmenity(amenity *store.Amenity) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAmenity", amenity)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAmenity indicates an expected call of UpdateAmenity.
+func (mr *MockStorageServiceMockRecorder) UpdateAmenity(amenity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAmenity", reflect.TypeOf((*MockStorageService)(nil).UpdateAmenity), amenity)
+}
+
+// UpdateBookingStatus mocks base method.
+func (m *MockStorageService) UpdateBookingStatus(bookingId int64, status store.BookingStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookingStatus", bookingId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookingStatus indicates an expected call of UpdateBookingStatus.
+func (mr *MockStorageServiceMockRecorder) UpdateBookingStatus(bookingId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStatus", reflect.TypeOf((*MockStorageService)(nil).UpdateBookingStatus), bookingId, status)
+}
+
+// UpdateBookingStatusTx mocks base method.
+func (m *MockStorageService) UpdateBookingStatusTx(tx *sql.Tx, bookingId int64, status store.BookingStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookingStatusTx", tx, bookingId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookingStatusTx indicates an expected call of UpdateBookingStatusTx.
+func (mr *MockStorageServiceMockRecorder) UpdateBookingStatusTx(tx, bookingId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStatusTx", reflect.TypeOf((*MockStorageService)(nil).UpdateBookingStatusTx), tx, bookingId, status)
+}
+
+// UpdateBookingStayTx mocks base method.
+func (m *MockStorageService) UpdateBookingStayTx(tx *sql.Tx, bookingId int64, checkIn, checkOut time.Time, numRooms int, totalCost float32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBookingStayTx", tx, bookingId, checkIn, checkOut, numRooms, totalCost)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBookingStayTx indicates an expected call of UpdateBookingStayTx.
+func (mr *MockStorageServiceMockRecorder) UpdateBookingStayTx(tx, bookingId, checkIn, checkOut, numRooms, totalCost interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBookingStayTx", reflect.TypeOf((*MockStorageService)(nil).UpdateBookingStayTx), tx, bookingId, checkIn, checkOut, numRooms, totalCost)
+}
+
+// UpdateHotelDetailsTx mocks base method.
+func (m *MockStorageService) UpdateHotelDetailsTx(tx *sql.Tx, hotel *store.Hotel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHotelDetailsTx", tx, hotel)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHotelDetailsTx indicates an expected call of UpdateHotelDetailsTx.
+func (mr *MockStorageServiceMockRecorder) UpdateHotelDetailsTx(tx, hotel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelDetailsTx", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelDetailsTx), tx, hotel)
+}
+
+// UpdateHotelRatingTx mocks base method.
+func (m *MockStorageService) UpdateHotelRatingTx(tx *sql.Tx, hotelId int64, countDelta, ratingDelta int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHotelRatingTx", tx, hotelId, countDelta, ratingDelta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHotelRatingTx indicates an expected call of UpdateHotelRatingTx.
+func (mr *MockStorageServiceMockRecorder) UpdateHotelRatingTx(tx, hotelId, countDelta, ratingDelta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelRatingTx", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelRatingTx), tx, hotelId, countDelta, ratingDelta)
+}
+
+// UpdateHotelRooms mocks base method.
+func (m *MockStorageService) UpdateHotelRooms(hotelId int64, newRoomCount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHotelRooms", hotelId, newRoomCount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHotelRooms indicates an expected call of UpdateHotelRooms.
+func (mr *MockStorageServiceMockRecorder) UpdateHotelRooms(hotelId, newRoomCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelRooms", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelRooms), hotelId, newRoomCount)
+}
+
+// UpdateHotelRoomsTx mocks base method.
+func (m *MockStorageService) UpdateHotelRoomsTx(tx *sql.Tx, hotelId int64, newRoomCount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHotelRoomsTx", tx, hotelId, newRoomCount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHotelRoomsTx indicates an expected call of UpdateHotelRoomsTx.
+func (mr *MockStorageServiceMockRecorder) UpdateHotelRoomsTx(tx, hotelId, newRoomCount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelRoomsTx", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelRoomsTx), tx, hotelId, newRoomCount)
+}
+
+// UpdateHotelStatusTx mocks base method.
+func (m *MockStorageService) UpdateHotelStatusTx(tx *sql.Tx, hotelId int64, status constants.HotelStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHotelStatusTx", tx, hotelId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHotelStatusTx indicates an expected call of UpdateHotelStatusTx.
+func (mr *MockStorageServiceMockRecorder) UpdateHotelStatusTx(tx, hotelId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHotelStatusTx", reflect.TypeOf((*MockStorageService)(nil).UpdateHotelStatusTx), tx, hotelId, status)
+}
+
+// UpdateOutboxMessage mocks base method.
+func (m *MockStorageService) UpdateOutboxMessage(message *store.OutboxMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOutboxMessage", message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOutboxMessage indicates an expected call of UpdateOutboxMessage.
+func (mr *MockStorageServiceMockRecorder) UpdateOutboxMessage(message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutboxMessage", reflect.TypeOf((*MockStorageService)(nil).UpdateOutboxMessage), message)
+}
+
+// UpdatePaymentStatus mocks base method.
+func (m *MockStorageService) UpdatePaymentStatus(paymentId string, status constants.PaymentStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentStatus", paymentId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentStatus indicates an expected call of UpdatePaymentStatus.
+func (mr *MockStorageServiceMockRecorder) UpdatePaymentStatus(paymentId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatus", reflect.TypeOf((*MockStorageService)(nil).UpdatePaymentStatus), paymentId, status)
+}
+
+// UpdatePaymentStatusTx mocks base method.
+func (m *MockStorageService) UpdatePaymentStatusTx(tx *sql.Tx, paymentId string, status constants.PaymentStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentStatusTx", tx, paymentId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentStatusTx indicates an expected call of UpdatePaymentStatusTx.
+func (mr *MockStorageServiceMockRecorder) UpdatePaymentStatusTx(tx, paymentId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatusTx", reflect.TypeOf((*MockStorageService)(nil).UpdatePaymentStatusTx), tx, paymentId, status)
+}
+
+// UpdateRefund mocks base method.
+func (m *MockStorageService) UpdateRefund(refund *store.Refund, fromStatus constants.RefundStatus) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefund", refund, fromStatus)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRefund indicates an expected call of UpdateRefund.
+func (mr *MockStorageServiceMockRecorder) UpdateRefund(refund, fromStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefund", reflect.TypeOf((*MockStorageService)(nil).UpdateRefund), refund, fromStatus)
+}
+
+// UpdateRefundTx mocks base method.
+func (m *MockStorageService) UpdateRefundTx(tx *sql.Tx, refund *store.Refund) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefundTx", tx, refund)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRefundTx indicates an expected call of UpdateRefundTx.
+func (mr *MockStorageServiceMockRecorder) UpdateRefundTx(tx, refund interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefundTx", reflect.TypeOf((*MockStorageService)(nil).UpdateRefundTx), tx, refund)
+}
+
+// UpdateRoomTypeTx mocks base method.
+func (m *MockStorageService) UpdateRoomTypeTx(tx *sql.Tx, roomType *store.RoomType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoomTypeTx", tx, roomType)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRoomTypeTx indicates an expected call of UpdateRoomTypeTx.
+func (mr *MockStorageServiceMockRecorder) UpdateRoomTypeTx(tx, roomType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoomTypeTx", reflect.TypeOf((*MockStorageService)(nil).UpdateRoomTypeTx), tx, roomType)
+}
+
+// UpdateUserPasswordTx mocks base method.
+func (m *MockStorageService) UpdateUserPasswordTx(tx *sql.Tx, userId int, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPasswordTx", tx, userId, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPasswordTx indicates an expected call of UpdateUserPasswordTx.
+func (mr *MockStorageServiceMockRecorder) UpdateUserPasswordTx(tx, userId, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordTx", reflect.TypeOf((*MockStorageService)(nil).UpdateUserPasswordTx), tx, userId, passwordHash)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStorageService) UpdateUserRole(userId int, role constants.UserRole) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", userId, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStorageServiceMockRecorder) UpdateUserRole(userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStorageService)(nil).UpdateUserRole), userId, role)
+}
+
+// UpdateUserTOTPLastUsedStep mocks base method.
+func (m *MockStorageService) UpdateUserTOTPLastUsedStep(userId int, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPLastUsedStep", userId, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPLastUsedStep indicates an expected call of UpdateUserTOTPLastUsedStep.
+func (mr *MockStorageServiceMockRecorder) UpdateUserTOTPLastUsedStep(userId, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPLastUsedStep", reflect.TypeOf((*MockStorageService)(nil).UpdateUserTOTPLastUsedStep), userId, step)
+}
+
+// UpdateWebhookEvent mocks base method.
+func (m *MockStorageService) UpdateWebhookEvent(event *store.WebhookEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhookEvent indicates an expected call of UpdateWebhookEvent.
+func (mr *MockStorageServiceMockRecorder) UpdateWebhookEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookEvent", reflect.TypeOf((*MockStorageService)(nil).UpdateWebhookEvent), event)
+}
+
+// UpsertCancellationPolicy mocks base method.
+func (m *MockStorageService) UpsertCancellationPolicy(policy *store.CancellationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCancellationPolicy", policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCancellationPolicy indicates an expected call of UpsertCancellationPolicy.
+func (mr *MockStorageServiceMockRecorder) UpsertCancellationPolicy(policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCancellationPolicy", reflect.TypeOf((*MockStorageService)(nil).UpsertCancellationPolicy), policy)
+}
+
+// UpsertCancellationPolicyTx mocks base method.
+func (m *MockStorageService) UpsertCancellationPolicyTx(tx *sql.Tx, policy *store.CancellationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCancellationPolicyTx", tx, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCancellationPolicyTx indicates an expected call of UpsertCancellationPolicyTx.
+func (mr *MockStorageServiceMockRecorder) UpsertCancellationPolicyTx(tx, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCancellationPolicyTx", reflect.TypeOf((*MockStorageService)(nil).UpsertCancellationPolicyTx), tx, policy)
+}
+
+// UpsertPendingUserTOTP mocks base method.
+func (m *MockStorageService) UpsertPendingUserTOTP(userId int, secret string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPendingUserTOTP", userId, secret)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertPendingUserTOTP indicates an expected call of UpsertPendingUserTOTP.
+func (mr *MockStorageServiceMockRecorder) UpsertPendingUserTOTP(userId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPendingUserTOTP", reflect.TypeOf((*MockStorageService)(nil).UpsertPendingUserTOTP), userId, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockStorageService) UseRecoveryCode(userId int, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", userId, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStorageServiceMockRecorder) UseRecoveryCode(userId, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStorageService)(nil).UseRecoveryCode), userId, codeHash)
+}
+
+// UseUserTokensTx mocks base method.
+func (m *MockStorageService) UseUserTokensTx(tx *sql.Tx, userId int, purpose constants.UserTokenPurpose) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseUserTokensTx", tx, userId, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseUserTokensTx indicates an expected call of UseUserTokensTx.
+func (mr *MockStorageServiceMockRecorder) UseUserTokensTx(tx, userId, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseUserTokensTx", reflect.TypeOf((*MockStorageService)(nil).UseUserTokensTx), tx, userId, purpose)
 }
@@ -1,39 +1,163 @@
 package store
 
 import (
+	"database/sql"
 	"encoding/json"
 	"hotel-system/src/constants"
+	"math"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const SchemaName = "public"
 
 const HotelTableName = "hotel"
-const UserTableName = "user"
+const UserTableName = "users"
 const BookingTableName = "booking"
 const PaymentsTableName = "payments"
 const IdempotencyKeyTableName = "idempotency_keys"
+const HotelInventoryTableName = "hotel_inventory"
+const RoomTypeTableName = "room_types"
+const CancellationPolicyTableName = "cancellation_policies"
+const RefundsTableName = "refunds"
+const WebhookEventsTableName = "webhook_events"
+const RefreshTokensTableName = "refresh_tokens"
+const HotelStaffTableName = "hotel_staff"
+const UserTokensTableName = "user_tokens"
+const OutboxTableName = "notification_outbox"
+const LoginThrottlesTableName = "login_throttles"
+const AuthAuditEventsTableName = "auth_audit_events"
+const UserTOTPTableName = "user_totp"
+const RecoveryCodesTableName = "totp_recovery_codes"
+const APIKeysTableName = "api_keys"
+const HotelImagesTableName = "hotel_images"
+const LandmarksTableName = "landmarks"
+const AmenitiesTableName = "amenities"
+const HotelAmenitiesTableName = "hotel_amenities"
+const RoomTypeAmenitiesTableName = "room_type_amenities"
+const ReviewsTableName = "reviews"
+const BookingModificationsTableName = "booking_modifications"
 
 type Hotel struct {
-	ID             int      `json:"id"`
-	Name           string   `json:"name"`
-	Description    string   `json:"description"`
-	AvailableRooms int      `json:"available_rooms"`
-	TotalRooms     int      `json:"total_rooms"`
-	Street         string   `json:"street"`
-	Landmark       string   `json:"landmark"`
-	Locality       string   `json:"locality"`
-	City           string   `json:"city"`
-	Pincode        int      `json:"pincode"`
-	State          string   `json:"state"`
-	ImageUrls      []string `json:"image_urls"`
-	CostPerNight   float32  `json:"cost_per_night"`
+	ID             int                   `json:"id"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description"`
+	AvailableRooms int                   `json:"available_rooms"`
+	TotalRooms     int                   `json:"total_rooms"`
+	Street         string                `json:"street"`
+	Landmark       string                `json:"landmark"`
+	Locality       string                `json:"locality"`
+	City           string                `json:"city"`
+	Pincode        int                   `json:"pincode"`
+	State          string                `json:"state"`
+	ImageUrls      []string              `json:"image_urls"`
+	CostPerNight   float32               `json:"cost_per_night"`
+	Status         constants.HotelStatus `json:"status"`
+	Latitude       sql.NullFloat64       `json:"latitude"` // not known for hotels added before geolocation
+	Longitude      sql.NullFloat64       `json:"longitude"`
+	DistanceKm     *float64              `json:"distance_km"`  // from the point searched near, only set by such searches
+	ReviewCount    int                   `json:"review_count"` // visible reviews
+	RatingTotal    int                   `json:"rating_total"` // sum of the overall scores of the visible reviews
+}
+
+// AverageRating is the mean overall score of the hotel's visible reviews, to two decimals, or nil if
+// it has none
+func (h *Hotel) AverageRating() *float64 {
+	if h.ReviewCount == 0 {
+		return nil
+	}
+	average := math.Round(float64(h.RatingTotal)/float64(h.ReviewCount)*100) / 100
+	return &average
+}
+
+// scanFields returns pointers to the hotel fields in HotelTableColumns order
+func (h *Hotel) scanFields() []any {
+	return []any{
+		&h.ID,
+		&h.Name,
+		&h.Description,
+		&h.AvailableRooms,
+		&h.TotalRooms,
+		&h.Street,
+		&h.Landmark,
+		&h.Locality,
+		&h.City,
+		&h.Pincode,
+		&h.State,
+		pq.Array(&h.ImageUrls),
+		&h.CostPerNight,
+		&h.Status,
+		&h.Latitude,
+		&h.Longitude,
+		&h.ReviewCount,
+		&h.RatingTotal,
+	}
+}
+
+// RoomType is a category of rooms in a hotel (e.g. Standard, Deluxe, Suite)
+// with its own capacity and price.
+type RoomType struct {
+	ID           int       `db:"id"`
+	HotelID      int       `db:"hotel_id"`
+	Name         string    `db:"name"`
+	Description  string    `db:"description"`
+	TotalRooms   int       `db:"total_rooms"`
+	MaxOccupancy int       `db:"max_occupancy"`
+	CostPerNight float32   `db:"cost_per_night"`
+	ImageUrls    []string  `db:"image_urls"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// CancellationPolicy decides how much of the amount paid is refunded when a guest cancels.
+// Cancellation is free until FreeCancellationHours before check-in; afterwards PenaltyPercent
+// of the amount is kept. A non-refundable rate never refunds anything.
+type CancellationPolicy struct {
+	HotelID               int       `db:"hotel_id"`
+	FreeCancellationHours int       `db:"free_cancellation_hours"`
+	PenaltyPercent        float32   `db:"penalty_percent"`
+	NonRefundable         bool      `db:"non_refundable"`
+	UpdatedAt             time.Time `db:"updated_at"`
+}
+
+// AvailableHotel is a hotel search result for a stay, carrying the cheapest room type
+// that can host it and how many rooms of it are free on every night.
+type AvailableHotel struct {
+	Hotel                Hotel
+	RoomTypeID           int
+	RoomTypeName         string
+	RoomTypeCostPerNight float32
+	AvailableRooms       int
+}
+
+// UserBooking is a booking as listed to the guest who made it, with the names of its hotel and room type
+// and the status of its latest payment, if it has one
+type UserBooking struct {
+	Booking       Booking
+	HotelName     string
+	RoomTypeName  string
+	PaymentStatus sql.NullString
 }
 
 type User struct {
-	ID       int    `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	ID              int                `json:"id"`
+	Username        string             `json:"username"`
+	Password        string             `json:"password"`
+	Role            constants.UserRole `json:"role"`
+	Email           string             `json:"email"`
+	EmailVerifiedAt sql.NullTime       `json:"email_verified_at"` // null until the email is verified
+}
+
+// scanFields returns pointers to the user fields in UserTableColumns order
+func (u *User) scanFields() []any {
+	return []any{
+		&u.ID,
+		&u.Username,
+		&u.Password,
+		&u.Role,
+		&u.Email,
+		&u.EmailVerifiedAt,
+	}
 }
 
 type BookingStatus string
@@ -49,7 +173,9 @@ const (
 
 type Booking struct {
 	BookingID     int           `json:"booking_id"`
+	Reference     string        `json:"reference"` // PNR-style code guests use instead of the serial id
 	HotelID       int           `json:"hotel_id"`
+	RoomTypeID    int           `json:"room_type_id"`
 	UserID        int           `json:"user_id"`
 	NumberOfRooms int           `json:"number_of_rooms"`
 	NumberOfDays  int           `json:"number_of_days"`
@@ -57,6 +183,9 @@ type Booking struct {
 	CheckInDate   time.Time     `json:"check_in_date"`
 	CheckOutDate  time.Time     `json:"check_out_date"`
 	Status        BookingStatus `json:"status"` // booking status
+	// LatePaymentResolution is set when the payment arrived after the booking had lapsed
+	LatePaymentResolution constants.LatePaymentResolution `json:"late_payment_resolution"`
+	TotalCost             float32                         `json:"total_cost"` // price of the stay as last booked or modified
 }
 
 type Payment struct {
@@ -68,6 +197,40 @@ type Payment struct {
 	Status            constants.PaymentStatus `db:"status"`
 	CreatedAt         time.Time               `db:"created_at"`
 	CheckoutSessionId string                  `db:"checkout_session_id"`
+	// LatePaymentResolution is set when the payment arrived after its booking had lapsed
+	LatePaymentResolution constants.LatePaymentResolution `db:"late_payment_resolution"`
+}
+
+// scanFields returns pointers to the payment fields in PaymentsTableColumns order
+func (p *Payment) scanFields() []any {
+	return []any{
+		&p.ID,
+		&p.BookingID,
+		&p.OrderID,
+		&p.Amount,
+		&p.Currency,
+		&p.Status,
+		&p.CreatedAt,
+		&p.CheckoutSessionId,
+		&p.LatePaymentResolution,
+	}
+}
+
+// Refund is money returned to a guest for a payment, either on cancellation or requested by an admin.
+// GatewayRefundID is empty until the gateway has accepted the refund.
+type Refund struct {
+	ID              string                 `db:"id"`
+	PaymentID       string                 `db:"payment_id"`
+	BookingID       int                    `db:"booking_id"`
+	GatewayRefundID string                 `db:"gateway_refund_id"`
+	Status          constants.RefundStatus `db:"status"`
+	Reason          string                 `db:"reason"`
+	Amount          float32                `db:"amount"`
+	Attempts        int                    `db:"attempts"`
+	LastAttemptAt   sql.NullTime           `db:"last_attempt_at"`
+	FailureReason   string                 `db:"failure_reason"`
+	CreatedAt       time.Time              `db:"created_at"`
+	UpdatedAt       time.Time              `db:"updated_at"`
 }
 
 type IdempotencyKey struct {
@@ -81,8 +244,9 @@ type IdempotencyKey struct {
 }
 
 var BookingsTableColumns = []string{
-	"booking_id", "hotel_id", "user_id", "number_of_rooms", "number_of_days",
-	"booking_time", "check_in_date", "check_out_date", "status",
+	"booking_id", "hotel_id", "room_type_id", "user_id", "number_of_rooms", "number_of_days",
+	"booking_time", "check_in_date", "check_out_date", "status", "late_payment_resolution", "reference",
+	"total_cost",
 }
 
 var HotelTableColumns = []string{
@@ -99,10 +263,215 @@ var HotelTableColumns = []string{
 	"state",
 	"image_urls",
 	"cost_per_night",
+	"status",
+	"latitude",
+	"longitude",
+	"review_count",
+	"rating_total",
 }
 
 var PaymentsTableColumns = []string{
 	"id", "booking_id", "order_id", "amount", "currency", "status", "created_at", "checkout_session_id",
+	"late_payment_resolution",
+}
+
+var RoomTypeColumns = []string{
+	"id",
+	"hotel_id",
+	"name",
+	"description",
+	"total_rooms",
+	"max_occupancy",
+	"cost_per_night",
+	"image_urls",
+	"created_at",
+}
+
+var CancellationPolicyColumns = []string{
+	"hotel_id",
+	"free_cancellation_hours",
+	"penalty_percent",
+	"non_refundable",
+	"updated_at",
+}
+
+var RefundsTableColumns = []string{
+	"id",
+	"payment_id",
+	"booking_id",
+	"gateway_refund_id",
+	"status",
+	"reason",
+	"amount",
+	"attempts",
+	"last_attempt_at",
+	"failure_reason",
+	"created_at",
+	"updated_at",
+}
+
+var WebhookEventsTableColumns = []string{
+	"id",
+	"type",
+	"payload",
+	"gateway_type",
+	"raw_body",
+	"status",
+	"attempts",
+	"last_error",
+	"next_attempt_at",
+	"received_at",
+	"processed_at",
+}
+
+var UserTableColumns = []string{
+	"id",
+	"username",
+	"password",
+	"role",
+	"email",
+	"email_verified_at",
+}
+
+var UserTokensTableColumns = []string{
+	"id",
+	"user_id",
+	"purpose",
+	"token_hash",
+	"email",
+	"expires_at",
+	"created_at",
+	"used_at",
+}
+
+var OutboxTableColumns = []string{
+	"id",
+	"user_id",
+	"recipient",
+	"subject",
+	"body",
+	"status",
+	"attempts",
+	"last_error",
+	"next_attempt_at",
+	"created_at",
+	"sent_at",
+}
+
+var LoginThrottlesTableColumns = []string{
+	"key",
+	"failed_count",
+	"last_failed_at",
+	"locked_until",
+}
+
+var AuthAuditEventsTableColumns = []string{
+	"id",
+	"type",
+	"username",
+	"ip",
+	"actor_user_id",
+	"detail",
+	"created_at",
+}
+
+var RefreshTokensTableColumns = []string{
+	"id",
+	"user_id",
+	"family_id",
+	"token_hash",
+	"expires_at",
+	"created_at",
+	"revoked_at",
+	"mfa",
+}
+
+var AmenitiesTableColumns = []string{
+	"id",
+	"code",
+	"name",
+	"category",
+	"icon",
+	"created_at",
+}
+
+var ReviewsTableColumns = []string{
+	"id",
+	"booking_id",
+	"hotel_id",
+	"user_id",
+	"overall",
+	"cleanliness",
+	"service",
+	"location",
+	"value",
+	"comment",
+	"reply",
+	"reply_user_id",
+	"replied_at",
+	"hidden_at",
+	"created_at",
+}
+
+var BookingModificationsTableColumns = []string{
+	"id",
+	"booking_id",
+	"old_check_in_date",
+	"old_check_out_date",
+	"old_number_of_rooms",
+	"old_total_cost",
+	"new_check_in_date",
+	"new_check_out_date",
+	"new_number_of_rooms",
+	"new_total_cost",
+	"status",
+	"payment_id",
+	"created_at",
+	"resolved_at",
+}
+
+var LandmarksTableColumns = []string{
+	"id",
+	"name",
+	"city",
+	"latitude",
+	"longitude",
+	"created_at",
+}
+
+var HotelImagesTableColumns = []string{
+	"id",
+	"hotel_id",
+	"position",
+	"url",
+	"thumbnail_url",
+	"blob_key",
+	"thumbnail_key",
+	"content_type",
+	"size_bytes",
+	"width",
+	"height",
+	"created_at",
+}
+
+var APIKeysTableColumns = []string{
+	"id",
+	"user_id",
+	"name",
+	"prefix",
+	"key_hash",
+	"scopes",
+	"created_at",
+	"last_used_at",
+	"revoked_at",
+}
+
+var UserTOTPTableColumns = []string{
+	"user_id",
+	"secret",
+	"enabled_at",
+	"last_used_step",
+	"created_at",
 }
 
 var IdempotencyKeyColumns = []string{
@@ -115,38 +484,6 @@ var IdempotencyKeyColumns = []string{
 	"created_at",
 }
 
-func NewHotel(
-	id int,
-	name string,
-	description string,
-	availableRooms int,
-	totalRooms int,
-	street string,
-	landmark string,
-	locality string,
-	city string,
-	pincode int,
-	state string,
-	imageUrls []string,
-	costPerNight float32,
-) Hotel {
-	return Hotel{
-		ID:             id,
-		Name:           name,
-		Description:    description,
-		AvailableRooms: availableRooms,
-		TotalRooms:     totalRooms,
-		Street:         street,
-		Landmark:       landmark,
-		Locality:       locality,
-		City:           city,
-		Pincode:        pincode,
-		State:          state,
-		ImageUrls:      imageUrls,
-		CostPerNight:   costPerNight,
-	}
-}
-
 func NewIdempotencyKey(
 	id string,
 	key string,
@@ -164,3 +501,584 @@ func NewIdempotencyKey(
 		CreatedAt:       time.Now(),
 	}
 }
+
+func NewRoomType(
+	hotelID int,
+	name string,
+	description string,
+	totalRooms int,
+	maxOccupancy int,
+	costPerNight float32,
+	imageUrls []string,
+) *RoomType {
+	return &RoomType{
+		HotelID:      hotelID,
+		Name:         name,
+		Description:  description,
+		TotalRooms:   totalRooms,
+		MaxOccupancy: maxOccupancy,
+		CostPerNight: costPerNight,
+		ImageUrls:    imageUrls,
+		CreatedAt:    time.Now(),
+	}
+}
+
+func NewCancellationPolicy(hotelID int, freeCancellationHours int, penaltyPercent float32, nonRefundable bool) *CancellationPolicy {
+	return &CancellationPolicy{
+		HotelID:               hotelID,
+		FreeCancellationHours: freeCancellationHours,
+		PenaltyPercent:        penaltyPercent,
+		NonRefundable:         nonRefundable,
+		UpdatedAt:             time.Now(),
+	}
+}
+
+func NewRefund(id string, paymentID string, bookingID int, amount float32, reason string) *Refund {
+	now := time.Now()
+	return &Refund{
+		ID:        id,
+		PaymentID: paymentID,
+		BookingID: bookingID,
+		Status:    constants.REFUND_PENDING,
+		Reason:    reason,
+		Amount:    amount,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// scanFields returns pointers to the refund fields in RefundsTableColumns order
+func (r *Refund) scanFields() []any {
+	return []any{
+		&r.ID,
+		&r.PaymentID,
+		&r.BookingID,
+		&r.GatewayRefundID,
+		&r.Status,
+		&r.Reason,
+		&r.Amount,
+		&r.Attempts,
+		&r.LastAttemptAt,
+		&r.FailureReason,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	}
+}
+
+// scanFields returns pointers to the booking fields in BookingsTableColumns order
+func (b *Booking) scanFields() []any {
+	return []any{
+		&b.BookingID,
+		&b.HotelID,
+		&b.RoomTypeID,
+		&b.UserID,
+		&b.NumberOfRooms,
+		&b.NumberOfDays,
+		&b.BookingTime,
+		&b.CheckInDate,
+		&b.CheckOutDate,
+		&b.Status,
+		&b.LatePaymentResolution,
+		&b.Reference,
+		&b.TotalCost,
+	}
+}
+
+// scanFields returns pointers to the room type fields in RoomTypeColumns order
+func (rt *RoomType) scanFields() []any {
+	return []any{
+		&rt.ID,
+		&rt.HotelID,
+		&rt.Name,
+		&rt.Description,
+		&rt.TotalRooms,
+		&rt.MaxOccupancy,
+		&rt.CostPerNight,
+		pq.Array(&rt.ImageUrls),
+		&rt.CreatedAt,
+	}
+}
+
+// WebhookEvent is a verified gateway notification kept in the webhook inbox, keyed by the gateway's
+// event id. Payload holds the gateway-neutral event as JSON; GatewayType and RawBody keep what the
+// gateway sent, so an event can be parsed again if the neutral form turns out to be wrong.
+type WebhookEvent struct {
+	ID            string                       `db:"id"`
+	Type          string                       `db:"type"`
+	Payload       []byte                       `db:"payload"`
+	GatewayType   string                       `db:"gateway_type"`
+	RawBody       []byte                       `db:"raw_body"`
+	Status        constants.WebhookEventStatus `db:"status"`
+	Attempts      int                          `db:"attempts"`
+	LastError     string                       `db:"last_error"`
+	NextAttemptAt time.Time                    `db:"next_attempt_at"`
+	ReceivedAt    time.Time                    `db:"received_at"`
+	ProcessedAt   sql.NullTime                 `db:"processed_at"`
+}
+
+func NewWebhookEvent(id string, eventType string, payload []byte, gatewayType string, rawBody []byte) *WebhookEvent {
+	now := time.Now()
+	return &WebhookEvent{
+		ID:            id,
+		Type:          eventType,
+		Payload:       payload,
+		GatewayType:   gatewayType,
+		RawBody:       rawBody,
+		Status:        constants.WEBHOOK_PENDING,
+		NextAttemptAt: now,
+		ReceivedAt:    now,
+	}
+}
+
+// scanFields returns pointers to the webhook event fields in WebhookEventsTableColumns order
+func (e *WebhookEvent) scanFields() []any {
+	return []any{
+		&e.ID,
+		&e.Type,
+		&e.Payload,
+		&e.GatewayType,
+		&e.RawBody,
+		&e.Status,
+		&e.Attempts,
+		&e.LastError,
+		&e.NextAttemptAt,
+		&e.ReceivedAt,
+		&e.ProcessedAt,
+	}
+}
+
+// RefreshToken is a single-use refresh token, stored by hash. Each refresh replaces the token with a
+// new one of the same family; a family is one login session.
+type RefreshToken struct {
+	ID        string       `db:"id"`
+	UserID    int          `db:"user_id"`
+	FamilyID  string       `db:"family_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	CreatedAt time.Time    `db:"created_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+	MFA       bool         `db:"mfa"` // the session was started with a second factor
+}
+
+func NewRefreshToken(id string, userID int, familyID string, tokenHash string, mfa bool, ttl time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		MFA:       mfa,
+	}
+}
+
+// scanFields returns pointers to the refresh token fields in RefreshTokensTableColumns order
+func (t *RefreshToken) scanFields() []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&t.RevokedAt,
+		&t.MFA,
+	}
+}
+
+// UserToken is a single-use token emailed to a user, stored by hash, for verifying their email or
+// resetting their password
+type UserToken struct {
+	ID        string                     `db:"id"`
+	UserID    int                        `db:"user_id"`
+	Purpose   constants.UserTokenPurpose `db:"purpose"`
+	TokenHash string                     `db:"token_hash"`
+	Email     string                     `db:"email"` // address the token was sent to
+	ExpiresAt time.Time                  `db:"expires_at"`
+	CreatedAt time.Time                  `db:"created_at"`
+	UsedAt    sql.NullTime               `db:"used_at"`
+}
+
+func NewUserToken(id string, userID int, purpose constants.UserTokenPurpose, tokenHash string, email string, ttl time.Duration) *UserToken {
+	now := time.Now()
+	return &UserToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		Email:     email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// scanFields returns pointers to the user token fields in UserTokensTableColumns order
+func (t *UserToken) scanFields() []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.Purpose,
+		&t.TokenHash,
+		&t.Email,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&t.UsedAt,
+	}
+}
+
+// OutboxMessage is an email waiting in the notification outbox, written in the same transaction as
+// the change it reports and sent afterwards by the outbox dispatcher
+type OutboxMessage struct {
+	ID            string                 `db:"id"`
+	UserID        int                    `db:"user_id"`
+	Recipient     string                 `db:"recipient"`
+	Subject       string                 `db:"subject"`
+	Body          string                 `db:"body"`
+	Status        constants.OutboxStatus `db:"status"`
+	Attempts      int                    `db:"attempts"`
+	LastError     string                 `db:"last_error"`
+	NextAttemptAt time.Time              `db:"next_attempt_at"`
+	CreatedAt     time.Time              `db:"created_at"`
+	SentAt        sql.NullTime           `db:"sent_at"`
+}
+
+func NewOutboxMessage(id string, userID int, recipient string, subject string, body string) *OutboxMessage {
+	now := time.Now()
+	return &OutboxMessage{
+		ID:            id,
+		UserID:        userID,
+		Recipient:     recipient,
+		Subject:       subject,
+		Body:          body,
+		Status:        constants.OUTBOX_PENDING,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+}
+
+// scanFields returns pointers to the outbox message fields in OutboxTableColumns order
+func (m *OutboxMessage) scanFields() []any {
+	return []any{
+		&m.ID,
+		&m.UserID,
+		&m.Recipient,
+		&m.Subject,
+		&m.Body,
+		&m.Status,
+		&m.Attempts,
+		&m.LastError,
+		&m.NextAttemptAt,
+		&m.CreatedAt,
+		&m.SentAt,
+	}
+}
+
+// LoginThrottle counts recent failed logins for a key, a username or a client IP
+type LoginThrottle struct {
+	Key          string       `db:"key"`
+	FailedCount  int          `db:"failed_count"`
+	LastFailedAt time.Time    `db:"last_failed_at"`
+	LockedUntil  sql.NullTime `db:"locked_until"` // no login attempts for the key until then
+}
+
+// scanFields returns pointers to the login throttle fields in LoginThrottlesTableColumns order
+func (t *LoginThrottle) scanFields() []any {
+	return []any{
+		&t.Key,
+		&t.FailedCount,
+		&t.LastFailedAt,
+		&t.LockedUntil,
+	}
+}
+
+// AuthAuditEvent records a security-relevant change to how an account may log in
+type AuthAuditEvent struct {
+	ID          string                       `db:"id"`
+	Type        constants.AuthAuditEventType `db:"type"`
+	Username    string                       `db:"username"`      // empty for events about an IP only
+	IP          string                       `db:"ip"`            // client IP of the request that caused the event
+	ActorUserID sql.NullInt64                `db:"actor_user_id"` // the admin who acted, if any
+	Detail      string                       `db:"detail"`
+	CreatedAt   time.Time                    `db:"created_at"`
+}
+
+func NewAuthAuditEvent(id string, eventType constants.AuthAuditEventType, username string, ip string, detail string) *AuthAuditEvent {
+	return &AuthAuditEvent{
+		ID:        id,
+		Type:      eventType,
+		Username:  username,
+		IP:        ip,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	}
+}
+
+// scanFields returns pointers to the audit event fields in AuthAuditEventsTableColumns order
+func (e *AuthAuditEvent) scanFields() []any {
+	return []any{
+		&e.ID,
+		&e.Type,
+		&e.Username,
+		&e.IP,
+		&e.ActorUserID,
+		&e.Detail,
+		&e.CreatedAt,
+	}
+}
+
+// UserTOTP is the TOTP secret of a user. Two-factor login is on once EnabledAt is set, i.e. after the
+// user has proven their authenticator works by entering a code from it.
+type UserTOTP struct {
+	UserID       int          `db:"user_id"`
+	Secret       string       `db:"secret"`
+	EnabledAt    sql.NullTime `db:"enabled_at"`
+	LastUsedStep int64        `db:"last_used_step"` // codes of this step and earlier are refused
+	CreatedAt    time.Time    `db:"created_at"`
+}
+
+// scanFields returns pointers to the user TOTP fields in UserTOTPTableColumns order
+func (t *UserTOTP) scanFields() []any {
+	return []any{
+		&t.UserID,
+		&t.Secret,
+		&t.EnabledAt,
+		&t.LastUsedStep,
+		&t.CreatedAt,
+	}
+}
+
+// APIKey lets a partner's systems call the API without a user login, stored by hash. The key acts as
+// the partner user that owns it, limited to its scopes.
+type APIKey struct {
+	ID         string       `db:"id"`
+	UserID     int          `db:"user_id"` // partner account the key belongs to
+	Name       string       `db:"name"`
+	Prefix     string       `db:"prefix"` // first characters of the key, to recognise it by
+	KeyHash    string       `db:"key_hash"`
+	Scopes     []string     `db:"scopes"`
+	CreatedAt  time.Time    `db:"created_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	RevokedAt  sql.NullTime `db:"revoked_at"`
+}
+
+func NewAPIKey(id string, userID int, name string, prefix string, keyHash string, scopes []constants.APIKeyScope) *APIKey {
+	scopeNames := make([]string, len(scopes))
+	for i, scope := range scopes {
+		scopeNames[i] = string(scope)
+	}
+	return &APIKey{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    scopeNames,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (k *APIKey) HasScope(scope constants.APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == string(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFields returns pointers to the API key fields in APIKeysTableColumns order
+func (k *APIKey) scanFields() []any {
+	return []any{
+		&k.ID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		pq.Array(&k.Scopes),
+		&k.CreatedAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+	}
+}
+
+// HotelImage is a picture in a hotel's gallery. Uploaded images live in the blob store under BlobKey,
+// with a thumbnail under ThumbnailKey; images added by URL with the hotel have no blobs.
+type HotelImage struct {
+	ID           string    `db:"id"`
+	HotelID      int       `db:"hotel_id"`
+	Position     int       `db:"position"` // order in the gallery, from 0
+	URL          string    `db:"url"`
+	ThumbnailURL string    `db:"thumbnail_url"`
+	BlobKey      string    `db:"blob_key"`
+	ThumbnailKey string    `db:"thumbnail_key"`
+	ContentType  string    `db:"content_type"`
+	SizeBytes    int64     `db:"size_bytes"`
+	Width        int       `db:"width"`
+	Height       int       `db:"height"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// NewLinkedHotelImage is a gallery image that points at an image hosted elsewhere
+func NewLinkedHotelImage(id string, hotelID int, url string) *HotelImage {
+	return &HotelImage{
+		ID:           id,
+		HotelID:      hotelID,
+		URL:          url,
+		ThumbnailURL: url,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// scanFields returns pointers to the hotel image fields in HotelImagesTableColumns order
+func (i *HotelImage) scanFields() []any {
+	return []any{
+		&i.ID,
+		&i.HotelID,
+		&i.Position,
+		&i.URL,
+		&i.ThumbnailURL,
+		&i.BlobKey,
+		&i.ThumbnailKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Width,
+		&i.Height,
+		&i.CreatedAt,
+	}
+}
+
+// Landmark is a named place, such as a station or a monument, that hotels can be searched around
+type Landmark struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	City      string    `db:"city"`
+	Latitude  float64   `db:"latitude"`
+	Longitude float64   `db:"longitude"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// scanFields returns pointers to the landmark fields in LandmarksTableColumns order
+func (l *Landmark) scanFields() []any {
+	return []any{
+		&l.ID,
+		&l.Name,
+		&l.City,
+		&l.Latitude,
+		&l.Longitude,
+		&l.CreatedAt,
+	}
+}
+
+// Amenity is an entry of the amenities catalog, such as Wi-Fi or a pool, that hotels and room types
+// can offer. Code is its stable key, used by clients and search filters.
+type Amenity struct {
+	ID        int       `db:"id"`
+	Code      string    `db:"code"`
+	Name      string    `db:"name"`
+	Category  string    `db:"category"` // e.g. general, room, bathroom, accessibility
+	Icon      string    `db:"icon"`     // name of the icon clients show with it
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// scanFields returns pointers to the amenity fields in AmenitiesTableColumns order
+func (a *Amenity) scanFields() []any {
+	return []any{
+		&a.ID,
+		&a.Code,
+		&a.Name,
+		&a.Category,
+		&a.Icon,
+		&a.CreatedAt,
+	}
+}
+
+// Review is a guest's review of a completed stay, one per booking. Scores run from 1 to 5. Staff of the
+// hotel may answer it with a public reply; admins may hide it, which also takes it out of the hotel's
+// rating.
+type Review struct {
+	ID          int           `db:"id"`
+	BookingID   int           `db:"booking_id"`
+	HotelID     int           `db:"hotel_id"`
+	UserID      int           `db:"user_id"`
+	Overall     int           `db:"overall"`
+	Cleanliness int           `db:"cleanliness"`
+	Service     int           `db:"service"`
+	Location    int           `db:"location"`
+	Value       int           `db:"value"`
+	Comment     string        `db:"comment"`
+	Reply       string        `db:"reply"`
+	ReplyUserID sql.NullInt64 `db:"reply_user_id"`
+	RepliedAt   sql.NullTime  `db:"replied_at"`
+	HiddenAt    sql.NullTime  `db:"hidden_at"`
+	CreatedAt   time.Time     `db:"created_at"`
+}
+
+// scanFields returns pointers to the review fields in ReviewsTableColumns order
+func (rv *Review) scanFields() []any {
+	return []any{
+		&rv.ID,
+		&rv.BookingID,
+		&rv.HotelID,
+		&rv.UserID,
+		&rv.Overall,
+		&rv.Cleanliness,
+		&rv.Service,
+		&rv.Location,
+		&rv.Value,
+		&rv.Comment,
+		&rv.Reply,
+		&rv.ReplyUserID,
+		&rv.RepliedAt,
+		&rv.HiddenAt,
+		&rv.CreatedAt,
+	}
+}
+
+type ModificationStatus string
+
+const (
+	MODIFICATION_PENDING ModificationStatus = "pending" // waiting for the guest to pay the difference
+	MODIFICATION_APPLIED ModificationStatus = "applied"
+	MODIFICATION_FAILED  ModificationStatus = "failed" // not paid in time, or the payment failed
+)
+
+// BookingModification is a change of the dates or room count of a confirmed booking. A change that costs
+// more stays pending until its top-up payment succeeds, holding the extra rooms it needs meanwhile; the
+// booking itself keeps its old stay until then.
+type BookingModification struct {
+	ID               int                `db:"id"`
+	BookingID        int                `db:"booking_id"`
+	OldCheckInDate   time.Time          `db:"old_check_in_date"`
+	OldCheckOutDate  time.Time          `db:"old_check_out_date"`
+	OldNumberOfRooms int                `db:"old_number_of_rooms"`
+	OldTotalCost     float32            `db:"old_total_cost"`
+	NewCheckInDate   time.Time          `db:"new_check_in_date"`
+	NewCheckOutDate  time.Time          `db:"new_check_out_date"`
+	NewNumberOfRooms int                `db:"new_number_of_rooms"`
+	NewTotalCost     float32            `db:"new_total_cost"`
+	Status           ModificationStatus `db:"status"`
+	PaymentID        sql.NullString     `db:"payment_id"` // the top-up payment, if the change costs more
+	CreatedAt        time.Time          `db:"created_at"`
+	ResolvedAt       sql.NullTime       `db:"resolved_at"`
+}
+
+// scanFields returns pointers to the modification fields in BookingModificationsTableColumns order
+func (m *BookingModification) scanFields() []any {
+	return []any{
+		&m.ID,
+		&m.BookingID,
+		&m.OldCheckInDate,
+		&m.OldCheckOutDate,
+		&m.OldNumberOfRooms,
+		&m.OldTotalCost,
+		&m.NewCheckInDate,
+		&m.NewCheckOutDate,
+		&m.NewNumberOfRooms,
+		&m.NewTotalCost,
+		&m.Status,
+		&m.PaymentID,
+		&m.CreatedAt,
+		&m.ResolvedAt,
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"hotel-system/src/constants"
+	"strings"
+	"time"
+)
+
+func (ds *dataStore) CreateOutboxMessage(message *OutboxMessage) error {
+	_, err := ds.db.Exec(createOutboxMessageQuery(), message.createArgs()...)
+	return err
+}
+
+func (ds *dataStore) CreateOutboxMessageTx(tx *sql.Tx, message *OutboxMessage) error {
+	_, err := tx.Exec(createOutboxMessageQuery(), message.createArgs()...)
+	return err
+}
+
+func createOutboxMessageQuery() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		SchemaName,
+		OutboxTableName,
+		strings.Join(OutboxTableColumns, ", "),
+	)
+}
+
+func (m *OutboxMessage) createArgs() []any {
+	return []any{m.ID, m.UserID, m.Recipient, m.Subject, m.Body, m.Status, m.Attempts, m.LastError, m.NextAttemptAt, m.CreatedAt, m.SentAt}
+}
+
+// GetDueOutboxMessages returns pending messages whose next attempt is due, oldest first
+func (ds *dataStore) GetDueOutboxMessages(now time.Time, limit int) ([]*OutboxMessage, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at LIMIT $3",
+		strings.Join(OutboxTableColumns, ", "),
+		SchemaName,
+		OutboxTableName,
+	)
+	rows, err := ds.db.Query(query, constants.OUTBOX_PENDING, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*OutboxMessage
+	for rows.Next() {
+		var message OutboxMessage
+		if err = rows.Scan(message.scanFields()...); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &message)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ClaimOutboxMessage pushes the next attempt of a due message out to leaseUntil, so that other workers
+// skip it while it is being sent. It returns false if the message is not due or is already claimed.
+func (ds *dataStore) ClaimOutboxMessage(messageId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET next_attempt_at = $1 WHERE id = $2 AND status = $3 AND next_attempt_at <= $4",
+		SchemaName,
+		OutboxTableName,
+	)
+	result, err := ds.db.Exec(query, leaseUntil, messageId, constants.OUTBOX_PENDING, now)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// UpdateOutboxMessage saves the outcome of an attempt
+func (ds *dataStore) UpdateOutboxMessage(message *OutboxMessage) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, sent_at = $5 WHERE id = $6",
+		SchemaName,
+		OutboxTableName,
+	)
+	_, err := ds.db.Exec(query, message.Status, message.Attempts, message.LastError, message.NextAttemptAt, message.SentAt, message.ID)
+	return err
+}
@@ -5,17 +5,18 @@ import (
 	"fmt"
 	"hotel-system/src/constants"
 	"strings"
+	"time"
 )
 
-// GetExpiredPayments returns all pending payments created more than 15 minutes ago
-func (ds *dataStore) GetExpiredPayments() ([]*Payment, error) {
+// GetPendingPaymentsCreatedBefore returns the pending payments created before the given time, oldest first
+func (ds *dataStore) GetPendingPaymentsCreatedBefore(createdBefore time.Time) ([]*Payment, error) {
 	query := fmt.Sprintf(
-		"SELECT %s FROM %s.%s WHERE status = $1 AND created_at < NOW() - INTERVAL '15 minutes'",
+		"SELECT %s FROM %s.%s WHERE status = $1 AND created_at < $2 ORDER BY created_at",
 		strings.Join(PaymentsTableColumns, ", "),
 		SchemaName,
 		PaymentsTableName,
 	)
-	rows, err := ds.db.Query(query, constants.PAYMENT_PENDING)
+	rows, err := ds.db.Query(query, constants.PAYMENT_PENDING, createdBefore)
 	if err != nil {
 		return nil, err
 	}
@@ -24,16 +25,7 @@ func (ds *dataStore) GetExpiredPayments() ([]*Payment, error) {
 	var payments []*Payment
 	for rows.Next() {
 		var payment Payment
-		err := rows.Scan(
-			&payment.ID,
-			&payment.BookingID,
-			&payment.OrderID,
-			&payment.Amount,
-			&payment.Currency,
-			&payment.Status,
-			&payment.CreatedAt,
-			&payment.CheckoutSessionId,
-		)
+		err := rows.Scan(payment.scanFields()...)
 		if err != nil {
 			return nil, err
 		}
@@ -66,6 +58,14 @@ func (ds *dataStore) CreatePayment(payment *Payment) error {
 	return nil
 }
 
+// CreatePaymentTx records a payment in the transaction that creates what it pays for
+func (ds *dataStore) CreatePaymentTx(tx *sql.Tx, payment *Payment) error {
+	query := fmt.Sprintf("INSERT INTO %s.%s (id, booking_id, order_id, amount, currency, status, created_at, checkout_session_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", SchemaName, PaymentsTableName)
+	_, err := tx.Exec(query, payment.ID, payment.BookingID, payment.OrderID, payment.Amount, payment.Currency, payment.Status,
+		payment.CreatedAt, payment.CheckoutSessionId)
+	return err
+}
+
 func (ds *dataStore) UpdatePaymentStatus(paymentId string, status constants.PaymentStatus) error {
 	query := fmt.Sprintf("UPDATE %s.%s SET status = $1 WHERE id = $2", SchemaName, PaymentsTableName)
 	_, err := ds.db.Exec(query, status, paymentId)
@@ -88,19 +88,12 @@ func (ds *dataStore) GetPaymentByCheckoutSessionId(checkoutSessionid string) (*P
 	return &payment, nil // payment exists
 }
 
+// GetPaymentByBookingId returns the payment a booking was made with, leaving out any top-ups paid when
+// the booking was modified, or nil if the booking has no payment
 func (ds *dataStore) GetPaymentByBookingId(bookingId int64) (*Payment, error) {
-	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE booking_id = $1", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE booking_id = $1 ORDER BY created_at, id LIMIT 1", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
 	var payment Payment
-	err := ds.db.QueryRow(query, bookingId).Scan(
-		&payment.ID,
-		&payment.BookingID,
-		&payment.OrderID,
-		&payment.Amount,
-		&payment.Currency,
-		&payment.Status,
-		&payment.CreatedAt,
-		&payment.CheckoutSessionId,
-	)
+	err := ds.db.QueryRow(query, bookingId).Scan(payment.scanFields()...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // payment does not exist
@@ -119,18 +112,17 @@ func (ds *dataStore) UpdatePaymentStatusTx(tx *sql.Tx, paymentId string, status
 	return nil
 }
 
+// SetPaymentLatePaymentResolutionTx records how a payment that arrived after its booking lapsed was settled
+func (ds *dataStore) SetPaymentLatePaymentResolutionTx(tx *sql.Tx, paymentId string, resolution constants.LatePaymentResolution) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET late_payment_resolution = $1 WHERE id = $2", SchemaName, PaymentsTableName)
+	_, err := tx.Exec(query, resolution, paymentId)
+	return err
+}
+
 func (ds *dataStore) GetPaymentByCheckoutSessionIdTx(tx *sql.Tx, checkoutSessionId string) (*Payment, error) {
 	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE checkout_session_id = $1 FOR UPDATE", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
 	var payment Payment
-	err := tx.QueryRow(query, checkoutSessionId).Scan(
-		&payment.ID,
-		&payment.BookingID,
-		&payment.OrderID,
-		&payment.Amount,
-		&payment.Currency,
-		&payment.Status,
-		&payment.CreatedAt,
-	)
+	err := tx.QueryRow(query, checkoutSessionId).Scan(payment.scanFields()...)
 	if err != nil {
 		return nil, err
 	}
@@ -141,16 +133,20 @@ func (ds *dataStore) GetPaymentByCheckoutSessionIdTx(tx *sql.Tx, checkoutSession
 func (ds *dataStore) GetPaymentByIdTx(tx *sql.Tx, paymentId string) (*Payment, error) {
 	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1 FOR UPDATE", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
 	var payment Payment
-	err := tx.QueryRow(query, paymentId).Scan(
-		&payment.ID,
-		&payment.BookingID,
-		&payment.OrderID,
-		&payment.Amount,
-		&payment.Currency,
-		&payment.Status,
-		&payment.CreatedAt,
-		&payment.CheckoutSessionId,
-	)
+	err := tx.QueryRow(query, paymentId).Scan(payment.scanFields()...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (ds *dataStore) GetPaymentById(paymentId string) (*Payment, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
+	var payment Payment
+	err := ds.db.QueryRow(query, paymentId).Scan(payment.scanFields()...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -159,3 +155,27 @@ func (ds *dataStore) GetPaymentByIdTx(tx *sql.Tx, paymentId string) (*Payment, e
 	}
 	return &payment, nil
 }
+
+// GetPaymentsByBookingIdTx fetches and locks every payment of a booking, the one it was made with first
+// and then any top-ups paid when it was modified
+func (ds *dataStore) GetPaymentsByBookingIdTx(tx *sql.Tx, bookingId int64) ([]*Payment, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE booking_id = $1 ORDER BY created_at, id FOR UPDATE", strings.Join(PaymentsTableColumns, ", "), SchemaName, PaymentsTableName)
+	rows, err := tx.Query(query, bookingId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*Payment
+	for rows.Next() {
+		var payment Payment
+		if err = rows.Scan(payment.scanFields()...); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &payment)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (ds *dataStore) CreateRefreshToken(token *RefreshToken) error {
+	_, err := ds.db.Exec(createRefreshTokenQuery(), token.createArgs()...)
+	return err
+}
+
+func (ds *dataStore) CreateRefreshTokenTx(tx *sql.Tx, token *RefreshToken) error {
+	_, err := tx.Exec(createRefreshTokenQuery(), token.createArgs()...)
+	return err
+}
+
+func createRefreshTokenQuery() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		SchemaName,
+		RefreshTokensTableName,
+		strings.Join(RefreshTokensTableColumns, ", "),
+	)
+}
+
+func (t *RefreshToken) createArgs() []any {
+	return []any{t.ID, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, t.CreatedAt, t.RevokedAt, t.MFA}
+}
+
+// GetRefreshTokenByHashTx fetches and locks a refresh token, returning nil if it does not exist
+func (ds *dataStore) GetRefreshTokenByHashTx(tx *sql.Tx, tokenHash string) (*RefreshToken, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE token_hash = $1 FOR UPDATE",
+		strings.Join(RefreshTokensTableColumns, ", "),
+		SchemaName,
+		RefreshTokensTableName,
+	)
+	var token RefreshToken
+	err := tx.QueryRow(query, tokenHash).Scan(token.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (ds *dataStore) RevokeRefreshTokenTx(tx *sql.Tx, tokenId string) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL", SchemaName, RefreshTokensTableName)
+	_, err := tx.Exec(query, tokenId)
+	return err
+}
+
+// RevokeRefreshTokenFamilyTx revokes every token of a login session
+func (ds *dataStore) RevokeRefreshTokenFamilyTx(tx *sql.Tx, familyId string) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", SchemaName, RefreshTokensTableName)
+	_, err := tx.Exec(query, familyId)
+	return err
+}
+
+// RevokeUserRefreshTokensTx revokes every token of every session of a user, logging them out everywhere
+func (ds *dataStore) RevokeUserRefreshTokensTx(tx *sql.Tx, userId int) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL", SchemaName, RefreshTokensTableName)
+	_, err := tx.Exec(query, userId)
+	return err
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (ds *dataStore) CreateRefund(refund *Refund) error {
+	_, err := ds.db.Exec(createRefundQuery(), refund.createArgs()...)
+	return err
+}
+
+func (ds *dataStore) CreateRefundTx(tx *sql.Tx, refund *Refund) error {
+	_, err := tx.Exec(createRefundQuery(), refund.createArgs()...)
+	return err
+}
+
+func createRefundQuery() string {
+	return fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+		SchemaName,
+		RefundsTableName,
+		strings.Join(RefundsTableColumns, ", "),
+	)
+}
+
+func (r *Refund) createArgs() []any {
+	return []any{
+		r.ID,
+		r.PaymentID,
+		r.BookingID,
+		r.GatewayRefundID,
+		r.Status,
+		r.Reason,
+		r.Amount,
+		r.Attempts,
+		r.LastAttemptAt,
+		r.FailureReason,
+		r.CreatedAt,
+		r.UpdatedAt,
+	}
+}
+
+func (ds *dataStore) GetRefundById(refundId string) (*Refund, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1", strings.Join(RefundsTableColumns, ", "), SchemaName, RefundsTableName)
+	var refund Refund
+	err := ds.db.QueryRow(query, refundId).Scan(refund.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// GetRefundByIdTx fetches and locks a refund, returning nil if it does not exist
+func (ds *dataStore) GetRefundByIdTx(tx *sql.Tx, refundId string) (*Refund, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1 FOR UPDATE", strings.Join(RefundsTableColumns, ", "), SchemaName, RefundsTableName)
+	var refund Refund
+	err := tx.QueryRow(query, refundId).Scan(refund.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (ds *dataStore) GetRefundsByBookingId(bookingId int64) ([]*Refund, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE booking_id = $1 ORDER BY created_at",
+		strings.Join(RefundsTableColumns, ", "),
+		SchemaName,
+		RefundsTableName,
+	)
+	return ds.queryRefunds(query, bookingId)
+}
+
+// GetPendingRefunds returns the refunds the gateway has not accepted yet that still have attempts left
+func (ds *dataStore) GetPendingRefunds() ([]*Refund, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE status = $1 AND attempts < $2 ORDER BY created_at",
+		strings.Join(RefundsTableColumns, ", "),
+		SchemaName,
+		RefundsTableName,
+	)
+	return ds.queryRefunds(query, constants.REFUND_PENDING, constants.MaxRefundAttempts)
+}
+
+func (ds *dataStore) queryRefunds(query string, args ...any) ([]*Refund, error) {
+	rows, err := ds.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []*Refund
+	for rows.Next() {
+		var refund Refund
+		if err = rows.Scan(refund.scanFields()...); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, &refund)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// SumRefundsByPaymentIdTx returns the total amount of a payment's refunds that are in one of the given statuses
+func (ds *dataStore) SumRefundsByPaymentIdTx(tx *sql.Tx, paymentId string, statuses []constants.RefundStatus) (float32, error) {
+	query := fmt.Sprintf(
+		"SELECT COALESCE(SUM(amount), 0) FROM %s.%s WHERE payment_id = $1 AND status = ANY($2)",
+		SchemaName,
+		RefundsTableName,
+	)
+	statusNames := make([]string, len(statuses))
+	for i, status := range statuses {
+		statusNames[i] = string(status)
+	}
+	var total float32
+	err := tx.QueryRow(query, paymentId, pq.Array(statusNames)).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// UpdateRefund saves the gateway outcome of a refund: its status, gateway id and attempt bookkeeping.
+// The refund is only saved while its status is still fromStatus, so that a status written meanwhile by
+// a refund webhook is not overwritten; it returns false if the refund had already moved on.
+func (ds *dataStore) UpdateRefund(refund *Refund, fromStatus constants.RefundStatus) (bool, error) {
+	result, err := ds.db.Exec(updateRefundQuery()+" AND status = $8", append(refund.updateArgs(), fromStatus)...)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func (ds *dataStore) UpdateRefundTx(tx *sql.Tx, refund *Refund) error {
+	_, err := tx.Exec(updateRefundQuery(), refund.updateArgs()...)
+	return err
+}
+
+func updateRefundQuery() string {
+	return fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, gateway_refund_id = $2, attempts = $3, last_attempt_at = $4, failure_reason = $5, updated_at = $6 WHERE id = $7",
+		SchemaName,
+		RefundsTableName,
+	)
+}
+
+func (r *Refund) updateArgs() []any {
+	r.UpdatedAt = time.Now()
+	return []any{r.Status, r.GatewayRefundID, r.Attempts, r.LastAttemptAt, r.FailureReason, r.UpdatedAt, r.ID}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CreateReviewTx stores a review and sets its id. It returns false if the booking was reviewed already.
+func (ds *dataStore) CreateReviewTx(tx *sql.Tx, review *Review) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.%s (booking_id, hotel_id, user_id, overall, cleanliness, service, location, value, comment)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (booking_id) DO NOTHING
+		RETURNING id, created_at`,
+		SchemaName,
+		ReviewsTableName,
+	)
+	err := tx.QueryRow(query, review.BookingID, review.HotelID, review.UserID, review.Overall, review.Cleanliness,
+		review.Service, review.Location, review.Value, review.Comment).Scan(&review.ID, &review.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UpdateHotelRatingTx adds a review to the rating of a hotel, or with negative deltas takes one out, so
+// that the rating does not have to be computed over all reviews
+func (ds *dataStore) UpdateHotelRatingTx(tx *sql.Tx, hotelId int64, countDelta int, ratingDelta int) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET review_count = review_count + $1, rating_total = rating_total + $2 WHERE id = $3",
+		SchemaName,
+		HotelTableName,
+	)
+	_, err := tx.Exec(query, countDelta, ratingDelta, hotelId)
+	return err
+}
+
+// GetReviewById returns a review, hidden or not, or nil if there is none
+func (ds *dataStore) GetReviewById(reviewId int64) (*Review, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE id = $1",
+		strings.Join(ReviewsTableColumns, ", "),
+		SchemaName,
+		ReviewsTableName,
+	)
+	var review Review
+	err := ds.db.QueryRow(query, reviewId).Scan(review.scanFields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// SetReviewReply sets or replaces the hotel's public reply to a review. It returns false if there is no
+// such review.
+func (ds *dataStore) SetReviewReply(reviewId int64, reply string, userId int) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET reply = $1, reply_user_id = $2, replied_at = NOW() WHERE id = $3",
+		SchemaName,
+		ReviewsTableName,
+	)
+	result, err := ds.db.Exec(query, reply, userId, reviewId)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+// SetReviewHiddenTx hides or shows a review. It returns the review if its visibility changed, and nil
+// if there is no such review or it was already hidden or shown.
+func (ds *dataStore) SetReviewHiddenTx(tx *sql.Tx, reviewId int64, hidden bool) (*Review, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.%s SET hidden_at = CASE WHEN $2 THEN NOW() END
+		WHERE id = $1 AND (hidden_at IS NULL) = $2
+		RETURNING %s`,
+		SchemaName,
+		ReviewsTableName,
+		strings.Join(ReviewsTableColumns, ", "),
+	)
+	var review Review
+	err := tx.QueryRow(query, reviewId, hidden).Scan(review.scanFields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetHotelReviews returns a page of the reviews of a hotel, newest first. Hidden reviews are left out
+// unless includeHidden is set.
+func (ds *dataStore) GetHotelReviews(hotelId int64, includeHidden bool, limit int32, offset int32) ([]*Review, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE hotel_id = $1 AND ($2 OR hidden_at IS NULL) ORDER BY created_at DESC, id DESC LIMIT $3 OFFSET $4",
+		strings.Join(ReviewsTableColumns, ", "),
+		SchemaName,
+		ReviewsTableName,
+	)
+	rows, err := ds.db.Query(query, hotelId, includeHidden, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*Review
+	for rows.Next() {
+		var review Review
+		if err = rows.Scan(review.scanFields()...); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// CountHotelReviews returns how many reviews GetHotelReviews pages through
+func (ds *dataStore) CountHotelReviews(hotelId int64, includeHidden bool) (int64, error) {
+	query := fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s.%s WHERE hotel_id = $1 AND ($2 OR hidden_at IS NULL)",
+		SchemaName,
+		ReviewsTableName,
+	)
+	var count int64
+	err := ds.db.QueryRow(query, hotelId, includeHidden).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+func (ds *dataStore) CreateRoomTypeTx(tx *sql.Tx, roomType *RoomType) (int64, error) {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (hotel_id, name, description, total_rooms, max_occupancy, cost_per_night, image_urls, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		SchemaName,
+		RoomTypeTableName,
+	)
+	var roomTypeId int64
+	err := tx.QueryRow(
+		query,
+		roomType.HotelID,
+		roomType.Name,
+		roomType.Description,
+		roomType.TotalRooms,
+		roomType.MaxOccupancy,
+		roomType.CostPerNight,
+		pq.Array(roomType.ImageUrls),
+		roomType.CreatedAt,
+	).Scan(&roomTypeId)
+	if err != nil {
+		return 0, err
+	}
+	return roomTypeId, nil
+}
+
+func (ds *dataStore) GetRoomTypesByHotelId(hotelId int64) ([]*RoomType, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE hotel_id = $1 ORDER BY cost_per_night, id",
+		strings.Join(RoomTypeColumns, ", "),
+		SchemaName,
+		RoomTypeTableName,
+	)
+	rows, err := ds.db.Query(query, hotelId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roomTypes []*RoomType
+	for rows.Next() {
+		var rt RoomType
+		if err = rows.Scan(rt.scanFields()...); err != nil {
+			return nil, err
+		}
+		roomTypes = append(roomTypes, &rt)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return roomTypes, nil
+}
+
+// GetRoomTypeByIdTx fetches a room type of the given hotel, returning nil if the hotel has no such room type
+func (ds *dataStore) GetRoomTypeByIdTx(tx *sql.Tx, hotelId int64, roomTypeId int64) (*RoomType, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE id = $1 AND hotel_id = $2",
+		strings.Join(RoomTypeColumns, ", "),
+		SchemaName,
+		RoomTypeTableName,
+	)
+	var rt RoomType
+	err := tx.QueryRow(query, roomTypeId, hotelId).Scan(rt.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rt, nil
+}
+
+func (ds *dataStore) UpdateRoomTypeTx(tx *sql.Tx, roomType *RoomType) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET name = $1, description = $2, total_rooms = $3, max_occupancy = $4, cost_per_night = $5 WHERE id = $6 AND hotel_id = $7",
+		SchemaName,
+		RoomTypeTableName,
+	)
+	_, err := tx.Exec(query, roomType.Name, roomType.Description, roomType.TotalRooms, roomType.MaxOccupancy, roomType.CostPerNight, roomType.ID, roomType.HotelID)
+	return err
+}
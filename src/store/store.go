@@ -3,8 +3,12 @@ package store
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
 	"hotel-system/src/types/hotelsystem"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 )
@@ -13,50 +17,34 @@ type dataStore struct {
 	db *sql.DB
 }
 
+// GetHotels returns a page of the hotels matching the search and filters, ordered by the sort key of
+// the request, by default most relevant first
 func (ds *dataStore) GetHotels(getHotelsListRequest *hotelsystem.GetHotelsListRequest) ([]Hotel, error) {
 	var hotels []Hotel
-	query := `SELECT id, name, description, available_rooms, total_rooms, street, landmark, 
-			   locality, city, pincode, state, image_urls, cost_per_night
-		FROM public.hotel
-		WHERE name      ILIKE $1 OR
-			  street    ILIKE $1 OR
-			  landmark  ILIKE $1 OR
-			  locality  ILIKE $1 OR
-			  city      ILIKE $1 OR
-			  state     ILIKE $1
-		LIMIT $2 OFFSET $3;`
-
-	likePattern := "%" + getHotelsListRequest.SearchQuery + "%"
-	rows, err := ds.db.Query(query, likePattern, getHotelsListRequest.Limit, getHotelsListRequest.Offset)
+	q := newHotelListingQuery(getHotelsListRequest)
+	// A hotel's own price is that of its cheapest room type
+	orderBy := q.orderBy(getHotelsListRequest, "h.cost_per_night", "h.id")
+	query := fmt.Sprintf(
+		"SELECT %s%s %s ORDER BY %s LIMIT %s OFFSET %s",
+		prefixColumns("h", HotelTableColumns),
+		q.selectDistance(),
+		q.from,
+		orderBy,
+		q.arg(getHotelsListRequest.Limit),
+		q.arg(getHotelsListRequest.Offset),
+	)
+
+	rows, err := ds.db.Query(query, q.args...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 	for rows.Next() {
-		var id, availableRooms, totalRooms, pincode int
-		var costPerNight float32
-		var imageUrls []string
-		var name, description, street, landmark, locality, city, state string
-		err = rows.Scan(&id, &name, &description, &availableRooms, &totalRooms, &street, &landmark, &locality, &city, &pincode, &state, pq.Array(&imageUrls), &costPerNight)
-		if err != nil {
+		var hotel Hotel
+		if err = rows.Scan(q.scanHotelFields(&hotel)...); err != nil {
 			return nil, err
 		}
-		hotel := Hotel{
-			ID:             id,
-			Name:           name,
-			Description:    description,
-			AvailableRooms: availableRooms,
-			TotalRooms:     totalRooms,
-			Street:         street,
-			Landmark:       landmark,
-			Locality:       locality,
-			City:           city,
-			Pincode:        pincode,
-			State:          state,
-			ImageUrls:      imageUrls, // Convert string to []string
-			CostPerNight:   costPerNight,
-		}
 		hotels = append(hotels, hotel)
 	}
 
@@ -68,41 +56,24 @@ func (ds *dataStore) GetHotels(getHotelsListRequest *hotelsystem.GetHotelsListRe
 	return hotels, nil
 }
 
-func (ds *dataStore) AddHotel(addHotelRequest hotelsystem.AddHotelRequest) error {
-	query := "INSERT INTO public.hotel (name, description, available_rooms, total_rooms, street, landmark, locality, city, pincode, state, image_urls, cost_per_night) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)"
-	_, err := ds.db.Exec(query, addHotelRequest.Name, addHotelRequest.Description, addHotelRequest.TotalRooms, addHotelRequest.TotalRooms, addHotelRequest.Street, addHotelRequest.Landmark, addHotelRequest.Locality, addHotelRequest.City, addHotelRequest.Pincode, addHotelRequest.State, nil, addHotelRequest.CostPerNight)
+func (ds *dataStore) AddHotelTx(tx *sql.Tx, addHotelRequest hotelsystem.AddHotelRequest) (int64, error) {
+	query := "INSERT INTO public.hotel (name, description, available_rooms, total_rooms, street, landmark, locality, city, pincode, state, image_urls, cost_per_night, status, latitude, longitude) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id"
+	var hotelId int64
+	err := tx.QueryRow(query, addHotelRequest.Name, addHotelRequest.Description, addHotelRequest.TotalRooms, addHotelRequest.TotalRooms, addHotelRequest.Street, addHotelRequest.Landmark, addHotelRequest.Locality, addHotelRequest.City, addHotelRequest.Pincode, addHotelRequest.State, pq.Array(addHotelRequest.GetImages()), addHotelRequest.CostPerNight, constants.HOTEL_ACTIVE, addHotelRequest.Latitude, addHotelRequest.Longitude).Scan(&hotelId)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return nil
+	return hotelId, nil
 }
 
+// GetHotelById returns a hotel whatever its status; callers decide what a deleted hotel means to them
 func (ds *dataStore) GetHotelById(hotelId int64) (Hotel, error) {
-	var id, availableRooms, totalRooms, pincode int
-	var costPerNight float32
-	var imageUrls []string
-	var name, description, street, landmark, locality, city, state string
-
 	query := "SELECT " + strings.Join(HotelTableColumns, ", ") + " FROM public.hotel WHERE id = $1"
-	err := ds.db.QueryRow(query, hotelId).Scan(&id, &name, &description, &availableRooms, &totalRooms, &street, &landmark, &locality, &city, &pincode, &state, pq.Array(&imageUrls), &costPerNight)
+	var hotel Hotel
+	err := ds.db.QueryRow(query, hotelId).Scan(hotel.scanFields()...)
 	if err != nil {
 		return Hotel{}, err
 	}
-	hotel := Hotel{
-		ID:             id,
-		Name:           name,
-		Description:    description,
-		AvailableRooms: availableRooms,
-		TotalRooms:     totalRooms,
-		Street:         street,
-		Landmark:       landmark,
-		Locality:       locality,
-		City:           city,
-		Pincode:        pincode,
-		State:          state,
-		ImageUrls:      imageUrls, // Convert string to []string
-		CostPerNight:   costPerNight,
-	}
 	return hotel, nil
 }
 
@@ -115,25 +86,15 @@ func (ds *dataStore) UpdateHotelRooms(hotelId int64, newRoomCount int) error {
 	return nil
 }
 
-func (ds *dataStore) CreateBooking(booking *Booking) (int64, error) {
-	query := ` INSERT INTO public.booking 
-		(hotel_id, user_id, number_of_rooms, number_of_days, booking_time, check_in_date, check_out_date, status) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING booking_id`
+// ErrBookingReferenceTaken is returned when creating a booking whose reference another booking has
+var ErrBookingReferenceTaken = errors.New("booking reference already taken")
 
+func (ds *dataStore) CreateBooking(booking *Booking) (int64, error) {
 	var bookingID int64
-	err := ds.db.QueryRow(
-		query,
-		booking.HotelID,
-		booking.UserID,
-		booking.NumberOfRooms,
-		booking.NumberOfDays,
-		booking.BookingTime,
-		booking.CheckInDate,
-		booking.CheckOutDate,
-		booking.Status,
-	).Scan(&bookingID)
-
+	err := ds.db.QueryRow(createBookingQuery, booking.createArgs()...).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrBookingReferenceTaken
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -141,10 +102,36 @@ func (ds *dataStore) CreateBooking(booking *Booking) (int64, error) {
 	return bookingID, nil
 }
 
+// createBookingQuery skips the insert rather than failing on a taken reference, so a transaction
+// creating the booking survives and can retry with another reference
+const createBookingQuery = `
+		INSERT INTO public.booking 
+		(hotel_id, room_type_id, user_id, number_of_rooms, number_of_days, booking_time, check_in_date, check_out_date, status, reference, total_cost) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (reference) DO NOTHING
+		RETURNING booking_id
+	`
+
+func (b *Booking) createArgs() []any {
+	return []any{
+		b.HotelID,
+		b.RoomTypeID,
+		b.UserID,
+		b.NumberOfRooms,
+		b.NumberOfDays,
+		b.BookingTime,
+		b.CheckInDate,
+		b.CheckOutDate,
+		b.Status,
+		b.Reference,
+		b.TotalCost,
+	}
+}
+
 func (ds *dataStore) GetUserById(id int) (User, error) {
-	query := "SELECT id, username FROM public.user WHERE id = $1"
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1", strings.Join(UserTableColumns, ", "), SchemaName, UserTableName)
 	var user User
-	err := ds.db.QueryRow(query, id).Scan(&user.ID, &user.Username)
+	err := ds.db.QueryRow(query, id).Scan(user.scanFields()...)
 	if err != nil {
 		return User{}, err
 	}
@@ -161,19 +148,23 @@ func (ds *dataStore) UpdateBookingStatus(bookingId int64, status BookingStatus)
 }
 
 func (ds *dataStore) GetBookingById(bookingId int64) (*Booking, error) {
-	query := "SELECT booking_id, hotel_id, user_id, number_of_rooms, number_of_days, booking_time, check_in_date, check_out_date, status FROM public.booking WHERE booking_id = $1"
+	query := "SELECT " + strings.Join(BookingsTableColumns, ", ") + " FROM public.booking WHERE booking_id = $1"
 	b := Booking{}
-	err := ds.db.QueryRow(query, bookingId).Scan(
-		&b.BookingID,
-		&b.HotelID,
-		&b.UserID,
-		&b.NumberOfRooms,
-		&b.NumberOfDays,
-		&b.BookingTime,
-		&b.CheckInDate,
-		&b.CheckOutDate,
-		&b.Status,
-	)
+	err := ds.db.QueryRow(query, bookingId).Scan(b.scanFields()...)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetBookingByReference returns the booking with the given reference, or nil if there is none
+func (ds *dataStore) GetBookingByReference(reference string) (*Booking, error) {
+	query := "SELECT " + strings.Join(BookingsTableColumns, ", ") + " FROM public.booking WHERE reference = $1"
+	b := Booking{}
+	err := ds.db.QueryRow(query, reference).Scan(b.scanFields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -194,17 +185,7 @@ func (ds *dataStore) GetCompletedBookings() ([]*Booking, error) {
 	var bookings []*Booking
 	for rows.Next() {
 		var b Booking
-		if err := rows.Scan(
-			&b.BookingID,
-			&b.HotelID,
-			&b.UserID,
-			&b.NumberOfRooms,
-			&b.NumberOfDays,
-			&b.BookingTime,
-			&b.CheckInDate,
-			&b.CheckOutDate,
-			&b.Status,
-		); err != nil {
+		if err := rows.Scan(b.scanFields()...); err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, &b)
@@ -217,19 +198,36 @@ func (ds *dataStore) GetCompletedBookings() ([]*Booking, error) {
 }
 
 func (ds *dataStore) AddUser(user *User) (int, error) {
-	query := "INSERT INTO public.user (username, password) VALUES ($1, $2) RETURNING id"
+	if user.Role == "" {
+		user.Role = constants.ROLE_GUEST
+	}
+	query := fmt.Sprintf("INSERT INTO %s.%s (username, password, role, email) VALUES ($1, $2, $3, $4) RETURNING id", SchemaName, UserTableName)
 	var userID int
-	err := ds.db.QueryRow(query, user.Username, user.Password).Scan(&userID)
+	err := ds.db.QueryRow(query, user.Username, user.Password, user.Role, user.Email).Scan(&userID)
 	if err != nil {
 		return 0, err
 	}
 	return userID, nil
 }
 
+// UpdateUserRole changes a user's role, returning false if the user does not exist
+func (ds *dataStore) UpdateUserRole(userId int, role constants.UserRole) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s.%s SET role = $1 WHERE id = $2", SchemaName, UserTableName)
+	result, err := ds.db.Exec(query, role, userId)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
 func (ds *dataStore) GetUserByUsername(username string) (*User, error) {
-	query := "SELECT id, username, password FROM public.user WHERE username = $1"
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE username = $1", strings.Join(UserTableColumns, ", "), SchemaName, UserTableName)
 	var user User
-	err := ds.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password)
+	err := ds.db.QueryRow(query, username).Scan(user.scanFields()...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // username does not exist
@@ -252,17 +250,7 @@ func (ds *dataStore) GetExpiredBookings() ([]*Booking, error) {
 	var bookings []*Booking
 	for rows.Next() {
 		var b Booking
-		if err = rows.Scan(
-			&b.BookingID,
-			&b.HotelID,
-			&b.UserID,
-			&b.NumberOfRooms,
-			&b.NumberOfDays,
-			&b.BookingTime,
-			&b.CheckInDate,
-			&b.CheckOutDate,
-			&b.Status,
-		); err != nil {
+		if err = rows.Scan(b.scanFields()...); err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, &b)
@@ -293,60 +281,40 @@ func (ds *dataStore) UpdateBookingStatusTx(tx *sql.Tx, bookingId int64, status B
 	return nil
 }
 
+// SetBookingLatePaymentResolutionTx records how a payment that arrived after the booking lapsed was settled
+func (ds *dataStore) SetBookingLatePaymentResolutionTx(tx *sql.Tx, bookingId int64, resolution constants.LatePaymentResolution) error {
+	query := "UPDATE public.booking SET late_payment_resolution = $1 WHERE booking_id = $2"
+	_, err := tx.Exec(query, resolution, bookingId)
+	return err
+}
+
+// UpdateBookingStayTx moves a booking to new dates and a new room count at a new total cost
+func (ds *dataStore) UpdateBookingStayTx(tx *sql.Tx, bookingId int64, checkIn, checkOut time.Time, numRooms int, totalCost float32) error {
+	query := `
+		UPDATE public.booking
+		SET check_in_date = $1, check_out_date = $2, number_of_days = $2::date - $1::date, number_of_rooms = $3, total_cost = $4
+		WHERE booking_id = $5`
+	_, err := tx.Exec(query, checkIn, checkOut, numRooms, totalCost, bookingId)
+	return err
+}
+
 func (ds *dataStore) GetHotelByIdTx(tx *sql.Tx, hotelId int64) (Hotel, error) {
 	query := "SELECT " + strings.Join(HotelTableColumns, ", ") + " FROM public.hotel WHERE id = $1"
-	var id, availableRooms, totalRooms, pincode int
-	var costPerNight float32
-	var imageUrls []string
-	var name, description, street, landmark, locality, city, state string
-	err := tx.QueryRow(query, hotelId).Scan(&id, &name, &description, &availableRooms, &totalRooms, &street, &landmark, &locality, &city, &pincode, &state, pq.Array(&imageUrls), &costPerNight)
+	var hotel Hotel
+	err := tx.QueryRow(query, hotelId).Scan(hotel.scanFields()...)
 	if err != nil {
 		return Hotel{}, err
 	}
-	hotel := NewHotel(
-		id,
-		name,
-		description,
-		availableRooms,
-		totalRooms,
-		street,
-		landmark,
-		locality,
-		city,
-		pincode,
-		state,
-		imageUrls,
-		costPerNight,
-	)
-
 	return hotel, nil
 }
 
 func (ds *dataStore) GetHotelForUpdate(tx *sql.Tx, hotelId int64) (Hotel, error) {
 	query := "SELECT " + strings.Join(HotelTableColumns, ", ") + " FROM public.hotel WHERE id = $1 FOR UPDATE"
-	var id, availableRooms, totalRooms, pincode int
-	var costPerNight float32
-	var imageUrls []string
-	var name, description, street, landmark, locality, city, state string
-	err := tx.QueryRow(query, hotelId).Scan(&id, &name, &description, &availableRooms, &totalRooms, &street, &landmark, &locality, &city, &pincode, &state, pq.Array(&imageUrls), &costPerNight)
+	var hotel Hotel
+	err := tx.QueryRow(query, hotelId).Scan(hotel.scanFields()...)
 	if err != nil {
 		return Hotel{}, err
 	}
-	hotel := NewHotel(
-		id,
-		name,
-		description,
-		availableRooms,
-		totalRooms,
-		street,
-		landmark,
-		locality,
-		city,
-		pincode,
-		state,
-		imageUrls,
-		costPerNight,
-	)
 	return hotel, nil
 }
 
@@ -359,45 +327,12 @@ func (ds *dataStore) UpdateHotelRoomsTx(tx *sql.Tx, hotelId int64, newRoomCount
 	return nil
 }
 
-func (ds *dataStore) DecrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error {
-	query := "UPDATE public.hotel SET available_rooms = available_rooms - $1 WHERE id = $2"
-	_, err := tx.Exec(query, numRooms, hotelId)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (ds *dataStore) IncrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error {
-	query := "UPDATE public.hotel SET available_rooms = available_rooms + $1 WHERE id = $2"
-	_, err := tx.Exec(query, numRooms, hotelId)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func (ds *dataStore) CreateBookingTx(tx *sql.Tx, booking *Booking) (int64, error) {
-	query := `
-		INSERT INTO public.booking 
-		(hotel_id, user_id, number_of_rooms, number_of_days, booking_time, check_in_date, check_out_date, status) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING booking_id
-	`
-
 	var bookingID int64
-	err := tx.QueryRow(
-		query,
-		booking.HotelID,
-		booking.UserID,
-		booking.NumberOfRooms,
-		booking.NumberOfDays,
-		booking.BookingTime,
-		booking.CheckInDate,
-		booking.CheckOutDate,
-		booking.Status,
-	).Scan(&bookingID)
-
+	err := tx.QueryRow(createBookingQuery, booking.createArgs()...).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrBookingReferenceTaken
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -406,19 +341,9 @@ func (ds *dataStore) CreateBookingTx(tx *sql.Tx, booking *Booking) (int64, error
 }
 
 func (ds *dataStore) GetBookingByIdTx(tx *sql.Tx, bookingId int64) (Booking, error) {
-	query := "SELECT booking_id, hotel_id, user_id, number_of_rooms, number_of_days, booking_time, check_in_date, check_out_date, status FROM public.booking WHERE booking_id = $1 FOR UPDATE"
+	query := "SELECT " + strings.Join(BookingsTableColumns, ", ") + " FROM public.booking WHERE booking_id = $1 FOR UPDATE"
 	var b Booking
-	err := tx.QueryRow(query, bookingId).Scan(
-		&b.BookingID,
-		&b.HotelID,
-		&b.UserID,
-		&b.NumberOfRooms,
-		&b.NumberOfDays,
-		&b.BookingTime,
-		&b.CheckInDate,
-		&b.CheckOutDate,
-		&b.Status,
-	)
+	err := tx.QueryRow(query, bookingId).Scan(b.scanFields()...)
 	if err != nil {
 		return Booking{}, err
 	}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"hotel-system/src/constants"
 	"hotel-system/src/types/hotelsystem"
+	"time"
 )
 
 //go:generate mockgen -source=types.go -destination=mocks/mock_storage.go -package=mocks
@@ -12,34 +13,165 @@ type StorageService interface {
 	AddUser(user *User) (int, error)
 	GetUserById(id int) (User, error)
 	GetUserByUsername(username string) (*User, error)
+	GetUserByEmail(email string) (*User, error)
+	UpdateUserRole(userId int, role constants.UserRole) (bool, error)
+	MarkUserEmailVerifiedTx(tx *sql.Tx, userId int, email string) (bool, error)
+	UpdateUserPasswordTx(tx *sql.Tx, userId int, passwordHash string) error
+
+	CreateUserTokenTx(tx *sql.Tx, token *UserToken) error
+	GetUserTokenByHashTx(tx *sql.Tx, purpose constants.UserTokenPurpose, tokenHash string) (*UserToken, error)
+	UseUserTokensTx(tx *sql.Tx, userId int, purpose constants.UserTokenPurpose) error
+
+	AddHotelStaff(hotelId int64, userId int) error
+	AddHotelStaffTx(tx *sql.Tx, hotelId int64, userId int) error
+	RemoveHotelStaff(hotelId int64, userId int) (bool, error)
+	IsHotelStaff(hotelId int64, userId int) (bool, error)
+
+	CreateRefreshToken(token *RefreshToken) error
+	CreateRefreshTokenTx(tx *sql.Tx, token *RefreshToken) error
+	GetRefreshTokenByHashTx(tx *sql.Tx, tokenHash string) (*RefreshToken, error)
+	RevokeRefreshTokenTx(tx *sql.Tx, tokenId string) error
+	RevokeUserRefreshTokensTx(tx *sql.Tx, userId int) error
+	RevokeRefreshTokenFamilyTx(tx *sql.Tx, familyId string) error
 	GetHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) ([]Hotel, error)
-	AddHotel(addHotelRequest hotelsystem.AddHotelRequest) error
+	CountHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) (int64, error)
+	GetAvailableHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) ([]*AvailableHotel, error)
+	CountAvailableHotels(getHotelsListReq *hotelsystem.GetHotelsListRequest) (int64, error)
 	GetHotelById(id int64) (Hotel, error)
 	UpdateHotelRooms(hotelId int64, newRoomCount int) error
 	CreateBooking(booking *Booking) (int64, error)
 	UpdateBookingStatus(bookingId int64, status BookingStatus) error
 	GetBookingById(bookingId int64) (*Booking, error)
+	GetBookingByReference(reference string) (*Booking, error)
 	GetCompletedBookings() ([]*Booking, error)
 	GetExpiredBookings() ([]*Booking, error)
+	GetBookingsByHotelId(hotelId int64, status BookingStatus, limit int32, offset int32) ([]*Booking, error)
+	GetUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) ([]*UserBooking, error)
+	CountUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) (int64, error)
 	CreatePayment(payment *Payment) error
 	GetPaymentByCheckoutSessionId(checkoutSessionId string) (*Payment, error)
 	GetPaymentByBookingId(bookingId int64) (*Payment, error)
+	GetPaymentById(paymentId string) (*Payment, error)
 	UpdatePaymentStatus(paymentId string, status constants.PaymentStatus) error
-	GetExpiredPayments() ([]*Payment, error)
+	GetPendingPaymentsCreatedBefore(createdBefore time.Time) ([]*Payment, error)
 
 	UpdateBookingStatusTx(tx *sql.Tx, bookingId int64, status BookingStatus) error
+	SetBookingLatePaymentResolutionTx(tx *sql.Tx, bookingId int64, resolution constants.LatePaymentResolution) error
 	GetBookingByIdTx(tx *sql.Tx, bookingId int64) (Booking, error)
 	CreateBookingTx(tx *sql.Tx, booking *Booking) (int64, error)
+	UpdateBookingStayTx(tx *sql.Tx, bookingId int64, checkIn, checkOut time.Time, numRooms int, totalCost float32) error
+
+	CreateBookingModificationTx(tx *sql.Tx, m *BookingModification) error
+	GetPendingBookingModificationTx(tx *sql.Tx, bookingId int64) (*BookingModification, error)
+	GetBookingModificationByPaymentIdTx(tx *sql.Tx, paymentId string) (*BookingModification, error)
+	GetPendingBookingModificationsCreatedBefore(createdBefore time.Time) ([]*BookingModification, error)
+	ResolveBookingModificationTx(tx *sql.Tx, modificationId int, status ModificationStatus) error
 
 	GetHotelForUpdate(tx *sql.Tx, hotelId int64) (Hotel, error)
 	UpdateHotelRoomsTx(tx *sql.Tx, hotelId int64, newRoomCount int) error
-	DecrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error
-	IncrementHotelRoomsTx(tx *sql.Tx, hotelId int64, numRooms int) error
 	GetHotelByIdTx(tx *sql.Tx, hotelId int64) (Hotel, error)
+	AddHotelTx(tx *sql.Tx, addHotelRequest hotelsystem.AddHotelRequest) (int64, error)
+	UpdateHotelDetailsTx(tx *sql.Tx, hotel *Hotel) error
+	RefreshHotelRoomSummaryTx(tx *sql.Tx, hotelId int64) error
+	UpdateHotelStatusTx(tx *sql.Tx, hotelId int64, status constants.HotelStatus) error
+	CountUpcomingBookingsTx(tx *sql.Tx, hotelId int64, from time.Time) (int, error)
+
+	CreateRoomTypeTx(tx *sql.Tx, roomType *RoomType) (int64, error)
+	GetRoomTypesByHotelId(hotelId int64) ([]*RoomType, error)
+	GetRoomTypeByIdTx(tx *sql.Tx, hotelId int64, roomTypeId int64) (*RoomType, error)
+	UpdateRoomTypeTx(tx *sql.Tx, roomType *RoomType) error
+
+	CreateAmenity(amenity *Amenity) (bool, error)
+	UpdateAmenity(amenity *Amenity) (bool, error)
+	DeleteAmenity(code string) (bool, error)
+	GetAmenities() ([]*Amenity, error)
+	GetAmenitiesByCodes(codes []string) ([]*Amenity, error)
+	SetHotelAmenitiesTx(tx *sql.Tx, hotelId int64, amenityIds []int64) error
+	SetRoomTypeAmenitiesTx(tx *sql.Tx, roomTypeId int64, amenityIds []int64) error
+	GetHotelAmenities(hotelIds []int64) (map[int][]*Amenity, error)
+	GetRoomTypeAmenities(hotelId int64) (map[int][]*Amenity, error)
+
+	CreateReviewTx(tx *sql.Tx, review *Review) (bool, error)
+	UpdateHotelRatingTx(tx *sql.Tx, hotelId int64, countDelta int, ratingDelta int) error
+	GetReviewById(reviewId int64) (*Review, error)
+	SetReviewReply(reviewId int64, reply string, userId int) (bool, error)
+	SetReviewHiddenTx(tx *sql.Tx, reviewId int64, hidden bool) (*Review, error)
+	GetHotelReviews(hotelId int64, includeHidden bool, limit int32, offset int32) ([]*Review, error)
+	CountHotelReviews(hotelId int64, includeHidden bool) (int64, error)
+
+	CreateLandmark(landmark *Landmark) (int64, error)
+	GetLandmarkByName(name string, city string) (*Landmark, error)
+	GetLandmarks(city string) ([]*Landmark, error)
+
+	CreateHotelImageTx(tx *sql.Tx, image *HotelImage) error
+	GetHotelImages(hotelId int64) ([]*HotelImage, error)
+	GetHotelImagesTx(tx *sql.Tx, hotelId int64) ([]*HotelImage, error)
+	DeleteHotelImageTx(tx *sql.Tx, hotelId int64, imageId string) (*HotelImage, error)
+	ReorderHotelImagesTx(tx *sql.Tx, hotelId int64, imageIds []string) error
+	SyncHotelImageUrlsTx(tx *sql.Tx, hotelId int64) error
+
+	GetMaxRoomsSoldByRoomType(hotelId int64, checkIn, checkOut time.Time) (map[int]int, error)
+	GetMaxRoomsSoldTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time) (int, error)
+	GetPeakRoomsSoldSinceTx(tx *sql.Tx, roomTypeId int64, from time.Time) (int, error)
+	ReserveHotelInventoryTx(tx *sql.Tx, hotelId int64, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error
+	ReleaseHotelInventoryTx(tx *sql.Tx, roomTypeId int64, checkIn, checkOut time.Time, numRooms int) error
 
 	GetPaymentByCheckoutSessionIdTx(tx *sql.Tx, checkoutSessionId string) (*Payment, error)
 	UpdatePaymentStatusTx(tx *sql.Tx, paymentId string, status constants.PaymentStatus) error
+	SetPaymentLatePaymentResolutionTx(tx *sql.Tx, paymentId string, resolution constants.LatePaymentResolution) error
 	GetPaymentByIdTx(tx *sql.Tx, paymentId string) (*Payment, error)
+	GetPaymentsByBookingIdTx(tx *sql.Tx, bookingId int64) ([]*Payment, error)
+	CreatePaymentTx(tx *sql.Tx, payment *Payment) error
+
+	GetCancellationPolicy(hotelId int64) (*CancellationPolicy, error)
+	UpsertCancellationPolicy(policy *CancellationPolicy) error
+	UpsertCancellationPolicyTx(tx *sql.Tx, policy *CancellationPolicy) error
+
+	CreateRefund(refund *Refund) error
+	CreateRefundTx(tx *sql.Tx, refund *Refund) error
+	GetRefundById(refundId string) (*Refund, error)
+	GetRefundByIdTx(tx *sql.Tx, refundId string) (*Refund, error)
+	GetRefundsByBookingId(bookingId int64) ([]*Refund, error)
+	GetPendingRefunds() ([]*Refund, error)
+	SumRefundsByPaymentIdTx(tx *sql.Tx, paymentId string, statuses []constants.RefundStatus) (float32, error)
+	UpdateRefund(refund *Refund, fromStatus constants.RefundStatus) (bool, error)
+	UpdateRefundTx(tx *sql.Tx, refund *Refund) error
+
+	CreateWebhookEvent(event *WebhookEvent) (bool, error)
+	GetWebhookEventById(eventId string) (*WebhookEvent, error)
+	GetWebhookEvents(status constants.WebhookEventStatus, limit int32, offset int32) ([]*WebhookEvent, error)
+	GetDueWebhookEvents(now time.Time, limit int) ([]*WebhookEvent, error)
+	ClaimWebhookEvent(eventId string, now time.Time, leaseUntil time.Time) (bool, error)
+	ResetWebhookEvent(eventId string, now time.Time) (bool, error)
+	UpdateWebhookEvent(event *WebhookEvent) error
+
+	GetUserTOTP(userId int) (*UserTOTP, error)
+	UpsertPendingUserTOTP(userId int, secret string) (bool, error)
+	EnableUserTOTPTx(tx *sql.Tx, userId int, lastUsedStep int64) (bool, error)
+	UpdateUserTOTPLastUsedStep(userId int, step int64) (bool, error)
+	DeleteUserTOTPTx(tx *sql.Tx, userId int) error
+	CreateRecoveryCodesTx(tx *sql.Tx, userId int, codeHashes []string) error
+	DeleteRecoveryCodesTx(tx *sql.Tx, userId int) error
+	UseRecoveryCode(userId int, codeHash string) (bool, error)
+
+	CreateAPIKey(key *APIKey) error
+	GetAPIKeyByHash(keyHash string) (*APIKey, error)
+	GetAPIKeysByUserId(userId int) ([]*APIKey, error)
+	RevokeAPIKey(keyId string, userId int) (bool, error)
+	TouchAPIKey(keyId string, now time.Time) error
+
+	GetLoginThrottles(keys []string) ([]*LoginThrottle, error)
+	RecordLoginFailure(key string, now time.Time, windowStart time.Time) (int, error)
+	LockLoginKey(key string, until time.Time) error
+	ClearLoginThrottle(key string) (bool, error)
+	CreateAuthAuditEvent(event *AuthAuditEvent) error
+	GetAuthAuditEvents(username string, limit int32, offset int32) ([]*AuthAuditEvent, error)
+
+	CreateOutboxMessage(message *OutboxMessage) error
+	CreateOutboxMessageTx(tx *sql.Tx, message *OutboxMessage) error
+	GetDueOutboxMessages(now time.Time, limit int) ([]*OutboxMessage, error)
+	ClaimOutboxMessage(messageId string, now time.Time, leaseUntil time.Time) (bool, error)
+	UpdateOutboxMessage(message *OutboxMessage) error
 
 	GetIdempotentPayloadByKey(key string) (*IdempotencyKey, error)
 	CreateIdempotencyKey(ik *IdempotencyKey) error
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"fmt"
+	"hotel-system/src/constants"
+	"hotel-system/src/types/hotelsystem"
+	"strings"
+	"time"
+)
+
+// userBookingsFrom builds the FROM and WHERE clauses listing a user's bookings for the filters of the
+// request, along with the query arguments. Each booking is joined with its original payment, so that a
+// page of bookings takes a single query. today decides which stays are upcoming and which are past.
+func userBookingsFrom(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) (string, []any) {
+	args := []any{userId}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	conditions := []string{"b.user_id = $1"}
+	if req.Status != "" {
+		conditions = append(conditions, "b.status = "+arg(req.Status))
+	}
+	switch constants.BookingPeriod(req.Period) {
+	case constants.PERIOD_UPCOMING:
+		conditions = append(conditions, "b.check_out_date > "+arg(today)+"::date")
+	case constants.PERIOD_PAST:
+		conditions = append(conditions, "b.check_out_date <= "+arg(today)+"::date")
+	}
+	// Stays overlapping the date range
+	if req.FromDate != "" {
+		conditions = append(conditions, "b.check_out_date > "+arg(req.FromDate)+"::date")
+	}
+	if req.ToDate != "" {
+		conditions = append(conditions, "b.check_in_date <= "+arg(req.ToDate)+"::date")
+	}
+	from := fmt.Sprintf(`
+		FROM %[1]s.%[2]s b
+		JOIN %[1]s.%[3]s h ON h.id = b.hotel_id
+		LEFT JOIN %[1]s.%[4]s r ON r.id = b.room_type_id
+		LEFT JOIN LATERAL (
+			-- The booking's original payment, not a later modification top-up
+			SELECT p.status FROM %[1]s.%[5]s p
+			WHERE p.booking_id = b.booking_id
+			ORDER BY p.created_at, p.id
+			LIMIT 1
+		) p ON TRUE
+		WHERE %[6]s`,
+		SchemaName,
+		BookingTableName,
+		HotelTableName,
+		RoomTypeTableName,
+		PaymentsTableName,
+		strings.Join(conditions, " AND "),
+	)
+	return from, args
+}
+
+// GetUserBookings returns a page of the bookings a user made, with their hotel, room type and payment.
+// Upcoming stays are listed soonest first, anything else latest first.
+func (ds *dataStore) GetUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) ([]*UserBooking, error) {
+	from, args := userBookingsFrom(userId, req, today)
+	orderBy := "b.check_in_date DESC, b.booking_id DESC"
+	if constants.BookingPeriod(req.Period) == constants.PERIOD_UPCOMING {
+		orderBy = "b.check_in_date, b.booking_id"
+	}
+	query := fmt.Sprintf(
+		"SELECT %s, h.name, COALESCE(r.name, ''), p.status %s ORDER BY %s LIMIT $%d OFFSET $%d",
+		prefixColumns("b", BookingsTableColumns),
+		from,
+		orderBy,
+		len(args)+1,
+		len(args)+2,
+	)
+	rows, err := ds.db.Query(query, append(args, req.Limit, req.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*UserBooking
+	for rows.Next() {
+		var ub UserBooking
+		fields := append(ub.Booking.scanFields(), &ub.HotelName, &ub.RoomTypeName, &ub.PaymentStatus)
+		if err = rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, &ub)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// CountUserBookings returns how many bookings GetUserBookings pages through
+func (ds *dataStore) CountUserBookings(userId int, req *hotelsystem.GetMyBookingsRequest, today time.Time) (int64, error) {
+	from, args := userBookingsFrom(userId, req, today)
+	var count int64
+	err := ds.db.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&count)
+	return count, err
+}
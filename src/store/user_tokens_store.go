@@ -0,0 +1,84 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"strings"
+)
+
+// GetUserByEmail returns the user with the given email, or nil if there is none. Emails are stored
+// lower-cased.
+func (ds *dataStore) GetUserByEmail(email string) (*User, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE email = $1", strings.Join(UserTableColumns, ", "), SchemaName, UserTableName)
+	var user User
+	err := ds.db.QueryRow(query, email).Scan(user.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// MarkUserEmailVerifiedTx marks a user's email verified, provided it is still the given address. It
+// returns false if the user has changed their email since.
+func (ds *dataStore) MarkUserEmailVerifiedTx(tx *sql.Tx, userId int, email string) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s.%s SET email_verified_at = NOW() WHERE id = $1 AND email = $2", SchemaName, UserTableName)
+	result, err := tx.Exec(query, userId, email)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func (ds *dataStore) UpdateUserPasswordTx(tx *sql.Tx, userId int, passwordHash string) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET password = $1 WHERE id = $2", SchemaName, UserTableName)
+	_, err := tx.Exec(query, passwordHash, userId)
+	return err
+}
+
+func (ds *dataStore) CreateUserTokenTx(tx *sql.Tx, token *UserToken) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		SchemaName,
+		UserTokensTableName,
+		strings.Join(UserTokensTableColumns, ", "),
+	)
+	_, err := tx.Exec(query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt, token.CreatedAt, token.UsedAt)
+	return err
+}
+
+// GetUserTokenByHashTx fetches and locks a token issued for the given purpose, returning nil if there
+// is none
+func (ds *dataStore) GetUserTokenByHashTx(tx *sql.Tx, purpose constants.UserTokenPurpose, tokenHash string) (*UserToken, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE purpose = $1 AND token_hash = $2 FOR UPDATE",
+		strings.Join(UserTokensTableColumns, ", "),
+		SchemaName,
+		UserTokensTableName,
+	)
+	var token UserToken
+	err := tx.QueryRow(query, purpose, tokenHash).Scan(token.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// UseUserTokensTx marks every unused token of a user issued for the given purpose as used, so that
+// issuing a new token or redeeming one voids the others
+func (ds *dataStore) UseUserTokensTx(tx *sql.Tx, userId int, purpose constants.UserTokenPurpose) error {
+	query := fmt.Sprintf("UPDATE %s.%s SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL", SchemaName, UserTokensTableName)
+	_, err := tx.Exec(query, userId, purpose)
+	return err
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// GetUserTOTP returns the TOTP secret of a user, enabled or still being set up, or nil if they have none
+func (ds *dataStore) GetUserTOTP(userId int) (*UserTOTP, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE user_id = $1",
+		strings.Join(UserTOTPTableColumns, ", "),
+		SchemaName,
+		UserTOTPTableName,
+	)
+	var totp UserTOTP
+	err := ds.db.QueryRow(query, userId).Scan(totp.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// UpsertPendingUserTOTP stores a new secret for a user who has not enabled TOTP yet, replacing the
+// secret of an earlier unfinished setup. It returns false if TOTP is already enabled.
+func (ds *dataStore) UpsertPendingUserTOTP(userId int, secret string) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s.%[2]s (user_id, secret, created_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET secret = $2, last_used_step = 0, created_at = NOW()
+		WHERE %[2]s.enabled_at IS NULL`,
+		SchemaName,
+		UserTOTPTableName,
+	)
+	result, err := ds.db.Exec(query, userId, secret)
+	if err != nil {
+		return false, err
+	}
+	stored, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return stored > 0, nil
+}
+
+// EnableUserTOTPTx turns two-factor login on for a pending secret. It returns false if there is no
+// pending secret, e.g. because TOTP was enabled concurrently.
+func (ds *dataStore) EnableUserTOTPTx(tx *sql.Tx, userId int, lastUsedStep int64) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET enabled_at = NOW(), last_used_step = $1 WHERE user_id = $2 AND enabled_at IS NULL",
+		SchemaName,
+		UserTOTPTableName,
+	)
+	result, err := tx.Exec(query, lastUsedStep, userId)
+	if err != nil {
+		return false, err
+	}
+	enabled, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return enabled > 0, nil
+}
+
+// UpdateUserTOTPLastUsedStep records the step of an accepted code. It returns false if a code of the
+// same or a later step was accepted in the meantime, in which case the code is a replay.
+func (ds *dataStore) UpdateUserTOTPLastUsedStep(userId int, step int64) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET last_used_step = $1 WHERE user_id = $2 AND last_used_step < $1",
+		SchemaName,
+		UserTOTPTableName,
+	)
+	result, err := ds.db.Exec(query, step, userId)
+	if err != nil {
+		return false, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func (ds *dataStore) DeleteUserTOTPTx(tx *sql.Tx, userId int) error {
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE user_id = $1", SchemaName, UserTOTPTableName)
+	_, err := tx.Exec(query, userId)
+	return err
+}
+
+// CreateRecoveryCodesTx stores the hashes of a user's recovery codes
+func (ds *dataStore) CreateRecoveryCodesTx(tx *sql.Tx, userId int, codeHashes []string) error {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (user_id, code_hash) SELECT $1, UNNEST($2::text[])",
+		SchemaName,
+		RecoveryCodesTableName,
+	)
+	_, err := tx.Exec(query, userId, pq.Array(codeHashes))
+	return err
+}
+
+func (ds *dataStore) DeleteRecoveryCodesTx(tx *sql.Tx, userId int) error {
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE user_id = $1", SchemaName, RecoveryCodesTableName)
+	_, err := tx.Exec(query, userId)
+	return err
+}
+
+// UseRecoveryCode uses up a recovery code of a user. It returns false if the code does not exist or
+// was used already.
+func (ds *dataStore) UseRecoveryCode(userId int, codeHash string) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL",
+		SchemaName,
+		RecoveryCodesTableName,
+	)
+	result, err := ds.db.Exec(query, userId, codeHash)
+	if err != nil {
+		return false, err
+	}
+	used, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return used > 0, nil
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hotel-system/src/constants"
+	"strings"
+	"time"
+)
+
+// CreateWebhookEvent adds an event to the webhook inbox. It returns false without error when the
+// event is already there, i.e. the gateway delivered it again.
+func (ds *dataStore) CreateWebhookEvent(event *WebhookEvent) (bool, error) {
+	query := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) ON CONFLICT (id) DO NOTHING",
+		SchemaName,
+		WebhookEventsTableName,
+		strings.Join(WebhookEventsTableColumns, ", "),
+	)
+	result, err := ds.db.Exec(query,
+		event.ID,
+		event.Type,
+		string(event.Payload), // as text, pq would send a []byte as bytea
+		event.GatewayType,
+		string(event.RawBody),
+		event.Status,
+		event.Attempts,
+		event.LastError,
+		event.NextAttemptAt,
+		event.ReceivedAt,
+		event.ProcessedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+func (ds *dataStore) GetWebhookEventById(eventId string) (*WebhookEvent, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE id = $1", strings.Join(WebhookEventsTableColumns, ", "), SchemaName, WebhookEventsTableName)
+	var event WebhookEvent
+	err := ds.db.QueryRow(query, eventId).Scan(event.scanFields()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetWebhookEvents lists inbox events, newest first, optionally only those in the given status
+func (ds *dataStore) GetWebhookEvents(status constants.WebhookEventStatus, limit int32, offset int32) ([]*WebhookEvent, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE ($1 = '' OR status = $1) ORDER BY received_at DESC LIMIT $2 OFFSET $3",
+		strings.Join(WebhookEventsTableColumns, ", "),
+		SchemaName,
+		WebhookEventsTableName,
+	)
+	return ds.queryWebhookEvents(query, status, limit, offset)
+}
+
+// GetDueWebhookEvents returns events waiting for an attempt, including ones whose worker lease ran out
+func (ds *dataStore) GetDueWebhookEvents(now time.Time, limit int) ([]*WebhookEvent, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE status IN ($1, $2) AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT $4",
+		strings.Join(WebhookEventsTableColumns, ", "),
+		SchemaName,
+		WebhookEventsTableName,
+	)
+	return ds.queryWebhookEvents(query, constants.WEBHOOK_PENDING, constants.WEBHOOK_PROCESSING, now, limit)
+}
+
+func (ds *dataStore) queryWebhookEvents(query string, args ...any) ([]*WebhookEvent, error) {
+	rows, err := ds.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*WebhookEvent
+	for rows.Next() {
+		var event WebhookEvent
+		if err = rows.Scan(event.scanFields()...); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ClaimWebhookEvent marks a due event as being processed until leaseUntil, so that other workers skip
+// it. It returns false if the event is not due or another worker holds it.
+func (ds *dataStore) ClaimWebhookEvent(eventId string, now time.Time, leaseUntil time.Time) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, next_attempt_at = $2 WHERE id = $3 AND status IN ($4, $1) AND next_attempt_at <= $5",
+		SchemaName,
+		WebhookEventsTableName,
+	)
+	result, err := ds.db.Exec(query, constants.WEBHOOK_PROCESSING, leaseUntil, eventId, constants.WEBHOOK_PENDING, now)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// ResetWebhookEvent makes an event due again with a fresh set of attempts. It returns false if the
+// event is missing or a worker is processing it and its lease has not run out.
+func (ds *dataStore) ResetWebhookEvent(eventId string, now time.Time) (bool, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, attempts = 0, processed_at = NULL, next_attempt_at = $2 WHERE id = $3 AND NOT (status = $4 AND next_attempt_at > $2)",
+		SchemaName,
+		WebhookEventsTableName,
+	)
+	result, err := ds.db.Exec(query, constants.WEBHOOK_PENDING, now, eventId, constants.WEBHOOK_PROCESSING)
+	if err != nil {
+		return false, err
+	}
+	reset, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return reset == 1, nil
+}
+
+// UpdateWebhookEvent saves the outcome of an attempt
+func (ds *dataStore) UpdateWebhookEvent(event *WebhookEvent) error {
+	query := fmt.Sprintf(
+		"UPDATE %s.%s SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, processed_at = $5 WHERE id = $6",
+		SchemaName,
+		WebhookEventsTableName,
+	)
+	_, err := ds.db.Exec(query, event.Status, event.Attempts, event.LastError, event.NextAttemptAt, event.ProcessedAt, event.ID)
+	return err
+}
@@ -2,18 +2,46 @@ package hotelsystem
 
 // GetHotelsListRequest corresponds to proto GetHotelsListRequest.
 type GetHotelsListRequest struct {
-	SearchQuery string `json:"search_query"`
-	Limit       int32  `json:"limit"`
-	Offset      int32  `json:"offset"`
+	SearchQuery  string `json:"search_query"`
+	Limit        int32  `json:"limit"`
+	Offset       int32  `json:"offset"`
+	City         string `json:"city"`
+	CheckInDate  string `json:"check_in_date"`  // YYYY-MM-DD
+	CheckOutDate string `json:"check_out_date"` // YYYY-MM-DD
+	NumRooms     int32  `json:"num_rooms"`
+	NumGuests    int32  `json:"num_guests"`
+	State        string `json:"state"`
+	// Only hotels with a room type priced within the range, per night; a search offers the cheapest of them
+	MinPrice *float32 `json:"min_price,omitempty"`
+	MaxPrice *float32 `json:"max_price,omitempty"`
+	// Rooms free tonight across all room types, or for a search, free for the whole stay in the room type offered
+	MinAvailableRooms int32  `json:"min_available_rooms"`
+	SortBy            string `json:"sort_by"` // relevance (default), price_asc, price_desc, popularity, distance or rating
+	// Radius search: hotels within radius_km of a point, or of a landmark added with /addLandmark
+	NearLatitude  *float64 `json:"near_latitude,omitempty"`
+	NearLongitude *float64 `json:"near_longitude,omitempty"`
+	NearLandmark  string   `json:"near_landmark"` // looked up in city, if given
+	RadiusKm      float64  `json:"radius_km"`
+	// Amenity codes the hotel must all offer, itself or, for a search, in the room type offered
+	Amenities []string `json:"amenities"`
+	MinRating float64  `json:"min_rating"` // only hotels with reviews averaging at least this
 }
 
 // HotelData corresponds to proto HotelData.
 type HotelData struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Description  string   `json:"description"`
-	Images       []string `json:"images"`
-	CostPerNight float32  `json:"cost_per_night"`
+	ID             string         `json:"id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	Images         []string       `json:"images"`
+	CostPerNight   float32        `json:"cost_per_night"`
+	RoomTypeID     int64          `json:"room_type_id"`
+	RoomTypeName   string         `json:"room_type_name"`
+	AvailableRooms int64          `json:"available_rooms"`
+	TotalPrice     float32        `json:"total_price"`
+	DistanceKm     *float64       `json:"distance_km,omitempty"` // from the point searched near
+	Amenities      []*AmenityData `json:"amenities"`             // of the hotel itself
+	Rating         *float64       `json:"rating,omitempty"`      // average overall score; unset without reviews
+	ReviewCount    int64          `json:"review_count"`
 }
 
 // GetImages returns a non-nil slice of images.
@@ -45,13 +73,21 @@ type GetHotelByIdRequest struct {
 
 // GetHotelByIdResponse corresponds to proto GetHotelByIdResponse.
 type GetHotelByIdResponse struct {
-	ID             int64    `json:"id"`
-	Name           string   `json:"name"`
-	Description    string   `json:"description"`
-	Images         []string `json:"images"`
-	CostPerNight   int64    `json:"cost_per_night"`
-	AvailableRooms int64    `json:"available_rooms"`
-	Address        string   `json:"address"`
+	ID                 int64               `json:"id"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	Images             []string            `json:"images"`
+	CostPerNight       int64               `json:"cost_per_night"`
+	AvailableRooms     int64               `json:"available_rooms"`
+	Address            string              `json:"address"`
+	RoomTypes          []*RoomTypeData     `json:"room_types"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy"`
+	Status             string              `json:"status"` // active or inactive
+	Latitude           *float64            `json:"latitude,omitempty"`
+	Longitude          *float64            `json:"longitude,omitempty"`
+	Amenities          []*AmenityData      `json:"amenities"`
+	Rating             *float64            `json:"rating,omitempty"` // average overall score; unset without reviews
+	ReviewCount        int64               `json:"review_count"`
 }
 
 // GetImages returns a non-nil slice of images.
@@ -62,35 +98,142 @@ func (r *GetHotelByIdResponse) GetImages() []string {
 	return r.Images
 }
 
+// GetRoomTypes returns a non-nil slice of room types.
+func (r *GetHotelByIdResponse) GetRoomTypes() []*RoomTypeData {
+	if r == nil || r.RoomTypes == nil {
+		return []*RoomTypeData{}
+	}
+	return r.RoomTypes
+}
+
+// GetCancellationPolicy returns a non-nil cancellation policy struct.
+func (r *GetHotelByIdResponse) GetCancellationPolicy() *CancellationPolicy {
+	if r == nil || r.CancellationPolicy == nil {
+		return &CancellationPolicy{}
+	}
+	return r.CancellationPolicy
+}
+
+// CancellationPolicy corresponds to proto CancellationPolicy.
+type CancellationPolicy struct {
+	FreeCancellationHours int32   `json:"free_cancellation_hours"`
+	PenaltyPercent        float32 `json:"penalty_percent"`
+	NonRefundable         bool    `json:"non_refundable"`
+}
+
+// SetCancellationPolicyRequest corresponds to proto SetCancellationPolicyRequest.
+type SetCancellationPolicyRequest struct {
+	HotelID            int64               `json:"hotel_id"`
+	CancellationPolicy *CancellationPolicy `json:"cancellation_policy"`
+}
+
+// RoomTypeData corresponds to proto RoomTypeData.
+type RoomTypeData struct {
+	ID             int64          `json:"id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description"`
+	TotalRooms     int64          `json:"total_rooms"`
+	MaxOccupancy   int32          `json:"max_occupancy"`
+	CostPerNight   float32        `json:"cost_per_night"`
+	Images         []string       `json:"images"`
+	AvailableRooms int64          `json:"available_rooms"`
+	Amenities      []*AmenityData `json:"amenities"`
+}
+
+// GetImages returns a non-nil slice of images.
+func (r *RoomTypeData) GetImages() []string {
+	if r == nil || r.Images == nil {
+		return []string{}
+	}
+	return r.Images
+}
+
 // AddHotelRequest corresponds to proto AddHotelRequest.
 type AddHotelRequest struct {
+	Name               string                `json:"name"`
+	Description        string                `json:"description"`
+	Images             []string              `json:"images"`
+	CostPerNight       int64                 `json:"cost_per_night"`
+	TotalRooms         int64                 `json:"total_rooms"`
+	Street             string                `json:"street"`
+	Landmark           string                `json:"landmark"`
+	Locality           string                `json:"locality"`
+	City               string                `json:"city"`
+	State              string                `json:"state"`
+	Pincode            string                `json:"pincode"`
+	RoomTypes          []*AddRoomTypeRequest `json:"room_types"`
+	CancellationPolicy *CancellationPolicy   `json:"cancellation_policy"`
+	Latitude           *float64              `json:"latitude,omitempty"`
+	Longitude          *float64              `json:"longitude,omitempty"`
+	Amenities          []string              `json:"amenities"` // amenity codes
+}
+
+// GetImages returns a non-nil slice of images.
+func (r *AddHotelRequest) GetImages() []string {
+	if r == nil || r.Images == nil {
+		return []string{}
+	}
+	return r.Images
+}
+
+// GetRoomTypes returns a non-nil slice of room types.
+func (r *AddHotelRequest) GetRoomTypes() []*AddRoomTypeRequest {
+	if r == nil || r.RoomTypes == nil {
+		return []*AddRoomTypeRequest{}
+	}
+	return r.RoomTypes
+}
+
+// GetAmenities returns a non-nil slice of amenity codes.
+func (r *AddHotelRequest) GetAmenities() []string {
+	if r == nil || r.Amenities == nil {
+		return []string{}
+	}
+	return r.Amenities
+}
+
+// AddRoomTypeRequest corresponds to proto AddRoomTypeRequest.
+type AddRoomTypeRequest struct {
 	Name         string   `json:"name"`
 	Description  string   `json:"description"`
-	Images       []string `json:"images"`
-	CostPerNight int64    `json:"cost_per_night"`
 	TotalRooms   int64    `json:"total_rooms"`
-	Street       string   `json:"street"`
-	Landmark     string   `json:"landmark"`
-	Locality     string   `json:"locality"`
-	City         string   `json:"city"`
-	State        string   `json:"state"`
-	Pincode      string   `json:"pincode"`
+	MaxOccupancy int32    `json:"max_occupancy"`
+	CostPerNight float32  `json:"cost_per_night"`
+	Images       []string `json:"images"`
+	Amenities    []string `json:"amenities"` // amenity codes
 }
 
 // GetImages returns a non-nil slice of images.
-func (r *AddHotelRequest) GetImages() []string {
+func (r *AddRoomTypeRequest) GetImages() []string {
 	if r == nil || r.Images == nil {
 		return []string{}
 	}
 	return r.Images
 }
 
+// GetAmenities returns a non-nil slice of amenity codes.
+func (r *AddRoomTypeRequest) GetAmenities() []string {
+	if r == nil || r.Amenities == nil {
+		return []string{}
+	}
+	return r.Amenities
+}
+
+// AddHotelResponse corresponds to proto AddHotelResponse.
+type AddHotelResponse struct {
+	Status    string          `json:"status"`
+	Message   string          `json:"message"`
+	HotelID   int64           `json:"hotel_id"`
+	RoomTypes []*RoomTypeData `json:"room_types"`
+}
+
 // BookHotelRequest corresponds to proto BookHotelRequest.
 type BookHotelRequest struct {
 	HotelID     int64  `json:"hotel_id"`
 	NumRooms    int32  `json:"num_rooms"`
 	NumDays     int32  `json:"num_days"`
 	CheckInDate string `json:"check_in_date"` // YYYY-MM-DD
+	RoomTypeID  int64  `json:"room_type_id"`
 }
 
 // BookHotelResponse corresponds to proto BookHotelResponse.
@@ -118,6 +261,9 @@ type BookHotelResponseBookingDetails struct {
 	NumDays     int32   `json:"num_days"`
 	CheckInDate string  `json:"check_in_date"`
 	TotalCost   float32 `json:"total_cost"`
+	RoomTypeID  int64   `json:"room_type_id"`
+	// BookingReference is quoted instead of BookingID when looking the booking up
+	BookingReference string `json:"booking_reference"`
 }
 
 // GenericSuccessResponse corresponds to proto GenericSuccessResponse.
@@ -177,8 +323,9 @@ func (p *PaymentDetails) GetMetadata() map[string]string {
 
 // PaymentStatusRequest corresponds to proto PaymentStatusRequest.
 type PaymentStatusRequest struct {
-	OrderID   string `json:"order_id"`
-	BookingID int64  `json:"booking_id"`
+	OrderID          string `json:"order_id"`
+	BookingID        int64  `json:"booking_id"`
+	BookingReference string `json:"booking_reference"` // used instead of BookingID when set
 }
 
 // PaymentStatusResponse corresponds to proto PaymentStatusResponse.
@@ -187,11 +334,14 @@ type PaymentStatusResponse struct {
 	PaymentStatus string `json:"payment_status"`
 	BookingStatus string `json:"booking_status"`
 	Message       string `json:"message"`
+	// LatePaymentResolution is "reinstated" or "refunded" when the payment arrived after the booking lapsed
+	LatePaymentResolution string `json:"late_payment_resolution"`
 }
 
 // GetBookingDetailsRequest corresponds to proto GetBookingDetailsRequest.
 type GetBookingDetailsRequest struct {
-	BookingID int64 `json:"booking_id"`
+	BookingID        int64  `json:"booking_id"`
+	BookingReference string `json:"booking_reference"` // used instead of BookingID when set
 }
 
 // GetBookingDetailsResponse corresponds to proto GetBookingDetailsResponse.
@@ -205,4 +355,481 @@ type GetBookingDetailsResponse struct {
 	Status        string  `json:"status"`
 	PaymentStatus string  `json:"payment_status"`
 	BookingTime   string  `json:"booking_time"`
+	RoomTypeID    int64   `json:"room_type_id"`
+	// LatePaymentResolution is "reinstated" or "refunded" when the payment arrived after the booking lapsed
+	LatePaymentResolution string `json:"late_payment_resolution"`
+	BookingReference      string `json:"booking_reference"`
+}
+
+// CancelBookingRequest corresponds to proto CancelBookingRequest.
+type CancelBookingRequest struct {
+	BookingID int64  `json:"booking_id"`
+	Reason    string `json:"reason"`
+}
+
+// CancelBookingResponse corresponds to proto CancelBookingResponse.
+type CancelBookingResponse struct {
+	BookingID     int64   `json:"booking_id"`
+	Status        string  `json:"status"`
+	AmountPaid    float32 `json:"amount_paid"`
+	RefundAmount  float32 `json:"refund_amount"`
+	PenaltyAmount float32 `json:"penalty_amount"`
+	Message       string  `json:"message"`
+	RefundID      string  `json:"refund_id"`
+	RefundStatus  string  `json:"refund_status"`
+}
+
+// ModifyBookingRequest corresponds to proto ModifyBookingRequest. Fields left empty keep the booking's
+// current value.
+type ModifyBookingRequest struct {
+	BookingID        int64  `json:"booking_id"`
+	BookingReference string `json:"booking_reference"`
+	CheckInDate      string `json:"check_in_date"` // YYYY-MM-DD
+	NumDays          int32  `json:"num_days"`
+	NumRooms         int32  `json:"num_rooms"`
+}
+
+// ModifyBookingResponse corresponds to proto ModifyBookingResponse.
+type ModifyBookingResponse struct {
+	BookingID        int64  `json:"booking_id"`
+	BookingReference string `json:"booking_reference"`
+	// Status is "applied", or "pending" until the difference is paid through CheckoutURL
+	Status        string  `json:"status"`
+	CheckInDate   string  `json:"check_in_date"`
+	CheckOutDate  string  `json:"check_out_date"`
+	NumRooms      int32   `json:"num_rooms"`
+	NumDays       int32   `json:"num_days"`
+	OldTotalCost  float32 `json:"old_total_cost"`
+	NewTotalCost  float32 `json:"new_total_cost"`
+	AmountDue     float32 `json:"amount_due"`
+	CheckoutURL   string  `json:"checkout_url"`
+	RefundAmount  float32 `json:"refund_amount"`
+	PenaltyAmount float32 `json:"penalty_amount"`
+	RefundStatus  string  `json:"refund_status"`
+	Message       string  `json:"message"`
+}
+
+// RequestRefundRequest corresponds to proto RequestRefundRequest.
+type RequestRefundRequest struct {
+	BookingID int64   `json:"booking_id"`
+	Amount    float32 `json:"amount"` // 0 refunds everything not refunded yet
+	Reason    string  `json:"reason"`
+}
+
+// RefundData corresponds to proto RefundData.
+type RefundData struct {
+	RefundID      string  `json:"refund_id"`
+	BookingID     int64   `json:"booking_id"`
+	PaymentID     string  `json:"payment_id"`
+	Amount        float32 `json:"amount"`
+	Status        string  `json:"status"`
+	Reason        string  `json:"reason"`
+	Attempts      int32   `json:"attempts"`
+	FailureReason string  `json:"failure_reason"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// GetRefundsRequest corresponds to proto GetRefundsRequest.
+type GetRefundsRequest struct {
+	BookingID int64 `json:"booking_id"`
+}
+
+// GetRefundsResponse corresponds to proto GetRefundsResponse.
+type GetRefundsResponse struct {
+	Refunds []*RefundData `json:"refunds"`
+}
+
+// GetRefunds returns a non-nil slice of refunds.
+func (r *GetRefundsResponse) GetRefunds() []*RefundData {
+	if r == nil || r.Refunds == nil {
+		return []*RefundData{}
+	}
+	return r.Refunds
+}
+
+// WebhookEventData corresponds to proto WebhookEventData.
+type WebhookEventData struct {
+	EventID       string `json:"event_id"`
+	Type          string `json:"type"`
+	GatewayType   string `json:"gateway_type"`
+	Status        string `json:"status"`
+	Attempts      int32  `json:"attempts"`
+	LastError     string `json:"last_error"`
+	Payload       string `json:"payload"`
+	ReceivedAt    string `json:"received_at"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	ProcessedAt   string `json:"processed_at"`
+}
+
+// GetWebhookEventsRequest corresponds to proto GetWebhookEventsRequest.
+type GetWebhookEventsRequest struct {
+	Status string `json:"status"` // empty for all statuses
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+// GetWebhookEventsResponse corresponds to proto GetWebhookEventsResponse.
+type GetWebhookEventsResponse struct {
+	Events []*WebhookEventData `json:"events"`
+}
+
+// GetEvents returns a non-nil slice of webhook events.
+func (r *GetWebhookEventsResponse) GetEvents() []*WebhookEventData {
+	if r == nil || r.Events == nil {
+		return []*WebhookEventData{}
+	}
+	return r.Events
+}
+
+// ReplayWebhookEventRequest corresponds to proto ReplayWebhookEventRequest.
+type ReplayWebhookEventRequest struct {
+	EventID string `json:"event_id"`
+}
+
+// SetUserRoleRequest corresponds to proto SetUserRoleRequest.
+type SetUserRoleRequest struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"` // guest, hotel_staff, admin or partner
+}
+
+// HotelStaffRequest corresponds to proto HotelStaffRequest.
+type HotelStaffRequest struct {
+	HotelID int64 `json:"hotel_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+// BookingData corresponds to proto BookingData.
+type BookingData struct {
+	BookingID        int64  `json:"booking_id"`
+	HotelID          int64  `json:"hotel_id"`
+	RoomTypeID       int64  `json:"room_type_id"`
+	UserID           int64  `json:"user_id"`
+	NumRooms         int32  `json:"num_rooms"`
+	NumDays          int32  `json:"num_days"`
+	CheckInDate      string `json:"check_in_date"`
+	CheckOutDate     string `json:"check_out_date"`
+	Status           string `json:"status"`
+	BookingTime      string `json:"booking_time"`
+	BookingReference string `json:"booking_reference"`
+}
+
+// GetHotelBookingsRequest corresponds to proto GetHotelBookingsRequest.
+type GetHotelBookingsRequest struct {
+	HotelID int64  `json:"hotel_id"`
+	Status  string `json:"status"` // empty for all statuses
+	Limit   int32  `json:"limit"`
+	Offset  int32  `json:"offset"`
+}
+
+// GetHotelBookingsResponse corresponds to proto GetHotelBookingsResponse.
+type GetHotelBookingsResponse struct {
+	Bookings []*BookingData `json:"bookings"`
+}
+
+// GetBookings returns a non-nil slice of bookings.
+func (r *GetHotelBookingsResponse) GetBookings() []*BookingData {
+	if r == nil || r.Bookings == nil {
+		return []*BookingData{}
+	}
+	return r.Bookings
+}
+
+// GetMyBookingsRequest corresponds to proto GetMyBookingsRequest.
+type GetMyBookingsRequest struct {
+	Status   string `json:"status"`
+	Period   string `json:"period"`    // upcoming (checking out after today) or past
+	FromDate string `json:"from_date"` // stays overlapping from_date to to_date, YYYY-MM-DD
+	ToDate   string `json:"to_date"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
+}
+
+// MyBookingData corresponds to proto MyBookingData.
+type MyBookingData struct {
+	BookingID             int64   `json:"booking_id"`
+	BookingReference      string  `json:"booking_reference"`
+	HotelID               int64   `json:"hotel_id"`
+	HotelName             string  `json:"hotel_name"`
+	RoomTypeID            int64   `json:"room_type_id"`
+	RoomTypeName          string  `json:"room_type_name"`
+	NumRooms              int32   `json:"num_rooms"`
+	NumDays               int32   `json:"num_days"`
+	CheckInDate           string  `json:"check_in_date"`
+	CheckOutDate          string  `json:"check_out_date"`
+	Status                string  `json:"status"`
+	TotalCost             float32 `json:"total_cost"`
+	PaymentStatus         string  `json:"payment_status"` // empty if no payment was started
+	BookingTime           string  `json:"booking_time"`
+	LatePaymentResolution string  `json:"late_payment_resolution"`
+}
+
+// GetMyBookingsResponse corresponds to proto GetMyBookingsResponse.
+type GetMyBookingsResponse struct {
+	TotalRecords int64            `json:"total_records"`
+	Bookings     []*MyBookingData `json:"bookings"`
+}
+
+// GetBookings returns a non-nil slice of bookings.
+func (r *GetMyBookingsResponse) GetBookings() []*MyBookingData {
+	if r == nil || r.Bookings == nil {
+		return []*MyBookingData{}
+	}
+	return r.Bookings
+}
+
+// UpdateHotelRequest corresponds to proto UpdateHotelRequest. Only the fields that are set are changed.
+type UpdateHotelRequest struct {
+	HotelID     int64                    `json:"hotel_id"`
+	Name        *string                  `json:"name,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+	Street      *string                  `json:"street,omitempty"`
+	Landmark    *string                  `json:"landmark,omitempty"`
+	Locality    *string                  `json:"locality,omitempty"`
+	City        *string                  `json:"city,omitempty"`
+	State       *string                  `json:"state,omitempty"`
+	Pincode     *string                  `json:"pincode,omitempty"`
+	RoomTypes   []*UpdateRoomTypeRequest `json:"room_types"`         // the hotel's cost_per_night and total_rooms follow its room types
+	Latitude    *float64                 `json:"latitude,omitempty"` // set together with longitude
+	Longitude   *float64                 `json:"longitude,omitempty"`
+}
+
+// GetRoomTypes returns a non-nil slice of room types.
+func (r *UpdateHotelRequest) GetRoomTypes() []*UpdateRoomTypeRequest {
+	if r == nil || r.RoomTypes == nil {
+		return []*UpdateRoomTypeRequest{}
+	}
+	return r.RoomTypes
+}
+
+// UpdateRoomTypeRequest corresponds to proto UpdateRoomTypeRequest.
+type UpdateRoomTypeRequest struct {
+	RoomTypeID   int64    `json:"room_type_id"`
+	Name         *string  `json:"name,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	TotalRooms   *int64   `json:"total_rooms,omitempty"` // cannot go below the rooms sold on any upcoming night
+	MaxOccupancy *int32   `json:"max_occupancy,omitempty"`
+	CostPerNight *float32 `json:"cost_per_night,omitempty"`
+}
+
+// HotelStatusRequest corresponds to proto HotelStatusRequest.
+type HotelStatusRequest struct {
+	HotelID int64 `json:"hotel_id"`
+}
+
+// HotelImageData corresponds to proto HotelImageData.
+type HotelImageData struct {
+	ImageID      string `json:"image_id"`
+	HotelID      int64  `json:"hotel_id"`
+	Position     int32  `json:"position"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	SizeBytes    int64  `json:"size_bytes"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// UploadHotelImageResponse corresponds to proto UploadHotelImageResponse.
+type UploadHotelImageResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Image   *HotelImageData `json:"image"`
+}
+
+// GetHotelImagesRequest corresponds to proto GetHotelImagesRequest.
+type GetHotelImagesRequest struct {
+	HotelID int64 `json:"hotel_id"`
+}
+
+// GetHotelImagesResponse corresponds to proto GetHotelImagesResponse.
+type GetHotelImagesResponse struct {
+	Images []*HotelImageData `json:"images"`
+}
+
+// GetImages returns a non-nil slice of images.
+func (r *GetHotelImagesResponse) GetImages() []*HotelImageData {
+	if r == nil || r.Images == nil {
+		return []*HotelImageData{}
+	}
+	return r.Images
+}
+
+// ReorderHotelImagesRequest corresponds to proto ReorderHotelImagesRequest.
+type ReorderHotelImagesRequest struct {
+	HotelID  int64    `json:"hotel_id"`
+	ImageIDs []string `json:"image_ids"`
+}
+
+// GetImageIDs returns a non-nil slice of image ids.
+func (r *ReorderHotelImagesRequest) GetImageIDs() []string {
+	if r == nil || r.ImageIDs == nil {
+		return []string{}
+	}
+	return r.ImageIDs
+}
+
+// DeleteHotelImageRequest corresponds to proto DeleteHotelImageRequest.
+type DeleteHotelImageRequest struct {
+	HotelID int64  `json:"hotel_id"`
+	ImageID string `json:"image_id"`
+}
+
+// LandmarkData corresponds to proto LandmarkData.
+type LandmarkData struct {
+	LandmarkID int64   `json:"landmark_id"`
+	Name       string  `json:"name"`
+	City       string  `json:"city"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+}
+
+// AddLandmarkRequest corresponds to proto AddLandmarkRequest.
+type AddLandmarkRequest struct {
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// AddLandmarkResponse corresponds to proto AddLandmarkResponse.
+type AddLandmarkResponse struct {
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	LandmarkID int64  `json:"landmark_id"`
+}
+
+// GetLandmarksRequest corresponds to proto GetLandmarksRequest.
+type GetLandmarksRequest struct {
+	City string `json:"city"` // all landmarks when empty
+}
+
+// GetLandmarksResponse corresponds to proto GetLandmarksResponse.
+type GetLandmarksResponse struct {
+	Landmarks []*LandmarkData `json:"landmarks"`
+}
+
+// GetLandmarks returns a non-nil slice of landmarks.
+func (r *GetLandmarksResponse) GetLandmarks() []*LandmarkData {
+	if r == nil || r.Landmarks == nil {
+		return []*LandmarkData{}
+	}
+	return r.Landmarks
+}
+
+// AmenityData corresponds to proto AmenityData.
+type AmenityData struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+}
+
+// AmenityRequest corresponds to proto AmenityRequest.
+type AmenityRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Icon     string `json:"icon"`
+}
+
+// DeleteAmenityRequest corresponds to proto DeleteAmenityRequest.
+type DeleteAmenityRequest struct {
+	Code string `json:"code"`
+}
+
+// GetAmenitiesResponse corresponds to proto GetAmenitiesResponse.
+type GetAmenitiesResponse struct {
+	Amenities []*AmenityData `json:"amenities"`
+}
+
+// GetAmenities returns a non-nil slice of amenities.
+func (r *GetAmenitiesResponse) GetAmenities() []*AmenityData {
+	if r == nil || r.Amenities == nil {
+		return []*AmenityData{}
+	}
+	return r.Amenities
+}
+
+// SetHotelAmenitiesRequest corresponds to proto SetHotelAmenitiesRequest.
+type SetHotelAmenitiesRequest struct {
+	HotelID   int64                          `json:"hotel_id"`
+	Amenities []string                       `json:"amenities"`  // replaces the hotel's amenities
+	RoomTypes []*SetRoomTypeAmenitiesRequest `json:"room_types"` // room types left out keep their amenities
+}
+
+// SetRoomTypeAmenitiesRequest corresponds to proto SetRoomTypeAmenitiesRequest.
+type SetRoomTypeAmenitiesRequest struct {
+	RoomTypeID int64    `json:"room_type_id"`
+	Amenities  []string `json:"amenities"`
+}
+
+// AddReviewRequest corresponds to proto AddReviewRequest.
+type AddReviewRequest struct {
+	BookingID        int64  `json:"booking_id"`
+	BookingReference string `json:"booking_reference"` // used instead of booking_id if given
+	Overall          int32  `json:"overall"`
+	Cleanliness      int32  `json:"cleanliness"`
+	Service          int32  `json:"service"`
+	Location         int32  `json:"location"`
+	Value            int32  `json:"value"`
+	Comment          string `json:"comment"`
+}
+
+// AddReviewResponse corresponds to proto AddReviewResponse.
+type AddReviewResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	ReviewID int64  `json:"review_id"`
+}
+
+// ReviewData corresponds to proto ReviewData.
+type ReviewData struct {
+	ReviewID    int64  `json:"review_id"`
+	HotelID     int64  `json:"hotel_id"`
+	Overall     int32  `json:"overall"`
+	Cleanliness int32  `json:"cleanliness"`
+	Service     int32  `json:"service"`
+	Location    int32  `json:"location"`
+	Value       int32  `json:"value"`
+	Comment     string `json:"comment"`
+	Reply       string `json:"reply"` // the hotel's public reply
+	RepliedAt   string `json:"replied_at"`
+	Hidden      bool   `json:"hidden"` // only shown to the hotel's staff and admins
+	CreatedAt   string `json:"created_at"`
+}
+
+// GetHotelReviewsRequest corresponds to proto GetHotelReviewsRequest.
+type GetHotelReviewsRequest struct {
+	HotelID int64 `json:"hotel_id"`
+	Limit   int32 `json:"limit"`
+	Offset  int32 `json:"offset"`
+}
+
+// GetHotelReviewsResponse corresponds to proto GetHotelReviewsResponse.
+type GetHotelReviewsResponse struct {
+	Rating       *float64      `json:"rating,omitempty"`
+	ReviewCount  int64         `json:"review_count"`
+	TotalRecords int64         `json:"total_records"`
+	Reviews      []*ReviewData `json:"reviews"`
+}
+
+// GetReviews returns a non-nil slice of reviews.
+func (r *GetHotelReviewsResponse) GetReviews() []*ReviewData {
+	if r == nil || r.Reviews == nil {
+		return []*ReviewData{}
+	}
+	return r.Reviews
+}
+
+// ReplyToReviewRequest corresponds to proto ReplyToReviewRequest.
+type ReplyToReviewRequest struct {
+	ReviewID int64  `json:"review_id"`
+	Reply    string `json:"reply"` // replaces an earlier reply
+}
+
+// HideReviewRequest corresponds to proto HideReviewRequest.
+type HideReviewRequest struct {
+	ReviewID int64 `json:"review_id"`
+	Hidden   bool  `json:"hidden"` // false shows a hidden review again
 }
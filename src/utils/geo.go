@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"hotel-system/src/constants"
+	"math"
+)
+
+// BoundingBox is the range of latitudes and longitudes around a point that contains every point within
+// a radius of it. It is a cheap, index-friendly prefilter for radius searches; distances are still
+// checked exactly with HaversineKm.
+type BoundingBox struct {
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+	// AllLongitudes is set when the box reaches a pole or crosses the 180th meridian, where a longitude
+	// range cannot describe it; only the latitudes should be checked then
+	AllLongitudes bool
+}
+
+// NewBoundingBox returns the bounding box of the circle of radiusKm around a point
+func NewBoundingBox(latitude, longitude, radiusKm float64) BoundingBox {
+	deltaLat := radiusKm / constants.KmPerDegreeOfLatitude
+	box := BoundingBox{
+		MinLatitude:  math.Max(latitude-deltaLat, -90),
+		MaxLatitude:  math.Min(latitude+deltaLat, 90),
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+	if box.MinLatitude == -90 || box.MaxLatitude == 90 {
+		box.AllLongitudes = true
+		return box
+	}
+	// Degrees of longitude shrink towards the poles; the edge nearest the pole is where they are smallest
+	widestLat := math.Max(math.Abs(box.MinLatitude), math.Abs(box.MaxLatitude))
+	deltaLng := radiusKm / (constants.KmPerDegreeOfLatitude * math.Cos(widestLat*math.Pi/180))
+	if longitude-deltaLng < -180 || longitude+deltaLng > 180 {
+		box.AllLongitudes = true
+		return box
+	}
+	box.MinLongitude = longitude - deltaLng
+	box.MaxLongitude = longitude + deltaLng
+	return box
+}
+
+// HaversineKm returns the great-circle distance between two points, in km
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLng := (lng2 - lng1) * toRadians
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * constants.EarthRadiusKm * math.Asin(math.Sqrt(math.Min(a, 1)))
+}
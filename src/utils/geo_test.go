@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaversineKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 12.9716, 77.5946, 12.9716, 77.5946, 0},
+		{"bengaluru to chennai", 12.9716, 77.5946, 13.0827, 80.2707, 290.2},
+		{"mumbai to delhi", 19.0760, 72.8777, 28.7041, 77.1025, 1153.2},
+		{"across the 180th meridian", 0, 179.5, 0, -179.5, 111.2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, HaversineKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2), 1)
+		})
+	}
+}
+
+func TestNewBoundingBoxContainsCircle(t *testing.T) {
+	lat, lng, radius := 12.9716, 77.5946, 25.0
+	box := NewBoundingBox(lat, lng, radius)
+	assert.False(t, box.AllLongitudes)
+
+	// Points just inside the radius in every direction must be in the box
+	for _, bearing := range [][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		pLat := lat + bearing[0]*(radius-0.1)/111.045
+		pLng := lng + bearing[1]*(radius-0.1)/(111.045*0.9744) // cos(12.97 degrees)
+		assert.Less(t, HaversineKm(lat, lng, pLat, pLng), radius)
+		assert.True(t, pLat >= box.MinLatitude && pLat <= box.MaxLatitude, "latitude %v outside box", pLat)
+		assert.True(t, pLng >= box.MinLongitude && pLng <= box.MaxLongitude, "longitude %v outside box", pLng)
+	}
+}
+
+func TestNewBoundingBoxEdges(t *testing.T) {
+	t.Run("near a pole", func(t *testing.T) {
+		box := NewBoundingBox(89.95, 10, 50)
+		assert.True(t, box.AllLongitudes)
+		assert.Equal(t, 90.0, box.MaxLatitude)
+	})
+	t.Run("across the 180th meridian", func(t *testing.T) {
+		box := NewBoundingBox(0, 179.9, 50)
+		assert.True(t, box.AllLongitudes)
+		assert.InDelta(t, -0.45, box.MinLatitude, 0.01)
+	})
+}
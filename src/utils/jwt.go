@@ -1,29 +1,75 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"os"
+	"hotel-system/src/constants"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET")) // Ensure JWT_SECRET is set in the environment
+var (
+	ErrMissingToken = errors.New("missing token")
+	ErrExpiredToken = errors.New("token expired")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+var jwtSecret []byte
+
+// SetJWTSecret sets the key access tokens are signed with. It must be called once the environment is
+// loaded and before any token is generated or validated.
+func SetJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
 // Claims struct for JWT payload
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID    int                `json:"user_id"`
+	Role      constants.UserRole `json:"role"`
+	SessionID string             `json:"sid"`           // refresh token family the access token was issued for
+	MFA       bool               `json:"mfa,omitempty"` // the session was started with a second factor
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT creates a signed JWT for the given user ID
-func GenerateJWT(userID int) (string, error) {
-	// Set claims
-	claims := Claims{
+// preAuthAudience marks pre-auth tokens, which ValidateJWT refuses as access tokens
+const preAuthAudience = "pre_auth"
+
+// GenerateJWT creates a signed, short-lived access token for the given user, role and session
+func GenerateJWT(userID int, role constants.UserRole, sessionID string, mfa bool) (string, error) {
+	now := time.Now()
+	return signClaims(Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		MFA:       mfa,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(constants.AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+}
+
+// GeneratePreAuthToken creates the token a user whose password checked out trades, together with a
+// TOTP code, for an access token. It grants nothing by itself.
+func GeneratePreAuthToken(userID int) (string, error) {
+	now := time.Now()
+	return signClaims(Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(2400 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Audience:  jwt.ClaimStrings{preAuthAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(constants.PreAuthTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
+	})
+}
+
+func signClaims(claims Claims) (string, error) {
+	if len(jwtSecret) == 0 {
+		return "", errors.New("jwt secret not set")
 	}
 
 	// Create token
@@ -33,26 +79,63 @@ func GenerateJWT(userID int) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+// ValidateJWT checks the signature and expiry of an access token. Errors are ErrMissingToken,
+// ErrExpiredToken or ErrInvalidToken.
 func ValidateJWT(tokenStr string) (*Claims, error) {
+	claims, err := parseClaims(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(claims.Audience) > 0 {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ValidatePreAuthToken checks a token made by GeneratePreAuthToken and returns its claims
+func ValidatePreAuthToken(tokenStr string) (*Claims, error) {
+	return parseClaims(tokenStr, jwt.WithAudience(preAuthAudience))
+}
+
+func parseClaims(tokenStr string, options ...jwt.ParserOption) (*Claims, error) {
 	if tokenStr == "" {
-		return nil, errors.New("missing token")
+		return nil, ErrMissingToken
+	}
+	if len(jwtSecret) == 0 {
+		return nil, ErrInvalidToken
 	}
 
+	options = append(options, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
-	})
+	}, options...)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return nil, errors.New("invalid token")
+		return nil, ErrInvalidToken
 	}
+	return claims, nil
+}
 
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, errors.New("token expired")
+// GenerateOpaqueToken returns a random opaque token, such as a refresh token or an emailed link token,
+// and the hash it is stored under
+func GenerateOpaqueToken() (token string, tokenHash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
 	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
 
-	return claims, nil
+// HashToken hashes a random token for storage. Tokens carry enough entropy that a fast hash is safe.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
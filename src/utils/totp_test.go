@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA1 test key of RFC 6238, "12345678901234567890", in base32
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; the 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.code, code, "time %d", tt.unix)
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	matched, ok := VerifyTOTP(rfc6238Secret, "081804", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, step, matched)
+
+	previous, _ := TOTPCode(rfc6238Secret, step-1)
+	_, ok = VerifyTOTP(rfc6238Secret, previous, now, 0)
+	assert.True(t, ok, "a code of the previous step is accepted for clock drift")
+
+	_, ok = VerifyTOTP(rfc6238Secret, "081804", now, step)
+	assert.False(t, ok, "a code cannot be used twice")
+
+	_, ok = VerifyTOTP(rfc6238Secret, "000000", now, 0)
+	assert.False(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, code, NormalizeRecoveryCode(" "+code[:5]+code[6:]+" "))
+	}
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"hotel-system/src/constants"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -34,3 +36,20 @@ func RollbackOrCommitTransaction(tx *sql.Tx, err *error) {
 func NewUuid() string {
 	return uuid.New().String()
 }
+
+// bookingReferenceAlphabet leaves out 0, O, 1 and I, which are easily confused when read out
+const bookingReferenceAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NewBookingReference returns a random PNR-style booking reference that guests can quote instead of
+// the serial booking id
+func NewBookingReference() (string, error) {
+	b := make([]byte, constants.BookingReferenceLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 256 is a multiple of the alphabet size, so every character is equally likely
+		b[i] = bookingReferenceAlphabet[int(b[i])%len(bookingReferenceAlphabet)]
+	}
+	return string(b), nil
+}
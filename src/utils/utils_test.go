@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"errors"
+	"hotel-system/src/constants"
+	"strings"
 	"testing"
 )
 
@@ -24,3 +27,64 @@ func TestHashPasswordAndCheckPasswordHash(t *testing.T) {
 		t.Error("Password check passed unexpectedly with wrong password")
 	}
 }
+
+func TestGenerateAndValidateJWT(t *testing.T) {
+	SetJWTSecret("test-secret")
+
+	token, err := GenerateJWT(42, constants.ROLE_HOTEL_STAFF, "session-1", false)
+	if err != nil {
+		t.Fatalf("Error generating token: %v", err)
+	}
+	claims, err := ValidateJWT(token)
+	if err != nil {
+		t.Fatalf("Error validating token: %v", err)
+	}
+	if claims.UserID != 42 || claims.Role != constants.ROLE_HOTEL_STAFF || claims.SessionID != "session-1" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err = ValidateJWT(""); !errors.Is(err, ErrMissingToken) {
+		t.Errorf("Expected ErrMissingToken, got %v", err)
+	}
+
+	// Pre-auth tokens only work for the second login step
+	preAuthToken, err := GeneratePreAuthToken(42)
+	if err != nil {
+		t.Fatalf("Error generating pre-auth token: %v", err)
+	}
+	if _, err = ValidateJWT(preAuthToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected pre-auth token to be refused as access token, got %v", err)
+	}
+	if claims, err = ValidatePreAuthToken(preAuthToken); err != nil || claims.UserID != 42 {
+		t.Errorf("Unexpected pre-auth claims %+v, error %v", claims, err)
+	}
+	if _, err = ValidatePreAuthToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected access token to be refused as pre-auth token, got %v", err)
+	}
+
+	// A token signed with another secret must be rejected
+	SetJWTSecret("other-secret")
+	if _, err = ValidateJWT(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestNewBookingReference(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		reference, err := NewBookingReference()
+		if err != nil {
+			t.Fatalf("Error generating booking reference: %v", err)
+		}
+		if len(reference) != constants.BookingReferenceLength {
+			t.Errorf("Expected %d characters, got %q", constants.BookingReferenceLength, reference)
+		}
+		if strings.ContainsAny(reference, "0O1I") || strings.Trim(reference, bookingReferenceAlphabet) != "" {
+			t.Errorf("Unexpected characters in %q", reference)
+		}
+		seen[reference] = true
+	}
+	if len(seen) < 99 {
+		t.Errorf("Expected distinct references, got %d of 100", len(seen))
+	}
+}
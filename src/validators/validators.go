@@ -3,8 +3,14 @@ package validators
 import (
 	"errors"
 	"fmt"
+	"hotel-system/src/constants"
 	pb2 "hotel-system/src/pb"
+	"hotel-system/src/store"
 	"hotel-system/src/types/hotelsystem"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,23 +32,354 @@ func ValidateLoginRequest(req *pb2.LoginRequest) error {
 }
 
 func ValidateRegisterRequest(req *pb2.RegisterRequest) error {
-	return validateUserInfo(req.Username, req.Password)
+	if err := validateUserInfo(req.Username, req.Password); err != nil {
+		return err
+	}
+	return validateEmail(req.Email)
+}
+
+func validateEmail(email string) error {
+	if email == "" {
+		return errors.New("email cannot be empty")
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return fmt.Errorf("invalid email %q", email)
+	}
+	return nil
+}
+
+func ValidateVerifyEmailRequest(req *pb2.VerifyEmailRequest) error {
+	if req.Token == "" {
+		return errors.New("token cannot be empty")
+	}
+	return nil
+}
+
+func ValidateForgotPasswordRequest(req *pb2.ForgotPasswordRequest) error {
+	return validateEmail(req.Email)
+}
+
+func ValidateResetPasswordRequest(req *pb2.ResetPasswordRequest) error {
+	if req.Token == "" {
+		return errors.New("token cannot be empty")
+	}
+	if len(req.NewPassword) < 6 {
+		return errors.New("password must be at least 6 characters long")
+	}
+	return nil
+}
+
+func ValidateRefreshTokenRequest(req *pb2.RefreshTokenRequest) error {
+	if req.RefreshToken == "" {
+		return errors.New("refresh_token cannot be empty")
+	}
+	return nil
+}
+
+func ValidateLogoutRequest(req *pb2.LogoutRequest) error {
+	if req.RefreshToken == "" {
+		return errors.New("refresh_token cannot be empty")
+	}
+	return nil
 }
 
 func ValidateGetHotelsListRequest(req *hotelsystem.GetHotelsListRequest) error {
+	// An empty search query lists every hotel
+	return validateHotelFilters(req)
+}
+
+// validateHotelFilters checks the paging, filters and sort key shared by the hotel listing and search
+func validateHotelFilters(req *hotelsystem.GetHotelsListRequest) error {
 	if req.Limit < 0 {
 		return errors.New("limit must be >= 0")
 	}
+	if req.Limit > constants.MaxPageSize {
+		return fmt.Errorf("limit cannot exceed %d, got %d", constants.MaxPageSize, req.Limit)
+	}
 	if req.Offset < 0 {
 		return errors.New("offset must be >= 0")
 	}
-	if req.SearchQuery == "" {
-		return errors.New("search_query cannot be empty")
+	if len(req.SearchQuery) > constants.MaxSearchQueryLength {
+		return fmt.Errorf("search_query cannot be longer than %d characters", constants.MaxSearchQueryLength)
+	}
+	if req.MinPrice != nil && *req.MinPrice < 0 {
+		return fmt.Errorf("min_price must be >= 0, got %v", *req.MinPrice)
+	}
+	if req.MaxPrice != nil && *req.MaxPrice < 0 {
+		return fmt.Errorf("max_price must be >= 0, got %v", *req.MaxPrice)
+	}
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		return errors.New("min_price cannot be more than max_price")
+	}
+	if req.MinAvailableRooms < 0 {
+		return fmt.Errorf("min_available_rooms must be >= 0, got %d", req.MinAvailableRooms)
+	}
+	if req.SortBy != "" && !slices.Contains(constants.HotelSorts, constants.HotelSort(req.SortBy)) {
+		return fmt.Errorf("sort_by must be one of %v, got %s", constants.HotelSorts, req.SortBy)
+	}
+	if err := validateAmenityCodes("amenities", req.Amenities); err != nil {
+		return err
+	}
+	if req.MinRating < 0 || req.MinRating > constants.MaxReviewScore {
+		return fmt.Errorf("min_rating must be between 0 and %d, got %v", constants.MaxReviewScore, req.MinRating)
+	}
+	return validateRadiusSearch(req)
+}
+
+// validateRadiusSearch checks the point or landmark a search is centred on, if any
+func validateRadiusSearch(req *hotelsystem.GetHotelsListRequest) error {
+	hasPoint := req.NearLatitude != nil || req.NearLongitude != nil
+	if hasPoint && (req.NearLatitude == nil || req.NearLongitude == nil) {
+		return errors.New("near_latitude and near_longitude must be given together")
+	}
+	if hasPoint && req.NearLandmark != "" {
+		return errors.New("search near either a point or a landmark, not both")
+	}
+	if !hasPoint && req.NearLandmark == "" {
+		if req.RadiusKm != 0 {
+			return errors.New("radius_km needs near_latitude and near_longitude, or near_landmark")
+		}
+		if req.SortBy == string(constants.SORT_DISTANCE) {
+			return errors.New("sort_by distance needs near_latitude and near_longitude, or near_landmark")
+		}
+		return nil
+	}
+	if hasPoint {
+		if err := validateCoordinates(*req.NearLatitude, *req.NearLongitude); err != nil {
+			return err
+		}
+	}
+	if req.RadiusKm < 0 || req.RadiusKm > constants.MaxSearchRadiusKm {
+		return fmt.Errorf("radius_km must be between 0 and %v, got %v", constants.MaxSearchRadiusKm, req.RadiusKm)
+	}
+	return nil
+}
+
+func validateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90, got %v", latitude)
+	}
+	if longitude < -180 || longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180, got %v", longitude)
+	}
+	return nil
+}
+
+func ValidateAddLandmarkRequest(req *hotelsystem.AddLandmarkRequest) error {
+	if req.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if req.City == "" {
+		return errors.New("city cannot be empty")
+	}
+	return validateCoordinates(req.Latitude, req.Longitude)
+}
+
+func ValidateAddHotelRequest(req *hotelsystem.AddHotelRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if req.CancellationPolicy != nil {
+		if err := ValidateCancellationPolicy(req.CancellationPolicy); err != nil {
+			return err
+		}
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if req.Latitude != nil {
+		if err := validateCoordinates(*req.Latitude, *req.Longitude); err != nil {
+			return err
+		}
+	}
+	if err := validateAmenityCodes("amenities", req.Amenities); err != nil {
+		return err
+	}
+	if len(req.Images) > constants.MaxHotelImages {
+		return fmt.Errorf("a hotel can have at most %d images, got %d", constants.MaxHotelImages, len(req.Images))
+	}
+	for i, image := range req.Images {
+		if !strings.HasPrefix(image, "https://") && !strings.HasPrefix(image, "http://") {
+			return fmt.Errorf("images[%d] must be an http(s) URL", i)
+		}
+	}
+	if len(req.RoomTypes) == 0 {
+		if req.TotalRooms <= 0 {
+			return fmt.Errorf("total_rooms must be > 0, got %d", req.TotalRooms)
+		}
+		if req.CostPerNight < 0 {
+			return fmt.Errorf("cost_per_night must be >= 0, got %d", req.CostPerNight)
+		}
+		return nil
+	}
+	for i, rt := range req.RoomTypes {
+		if rt == nil || rt.Name == "" {
+			return fmt.Errorf("room_types[%d].name cannot be empty", i)
+		}
+		if rt.TotalRooms <= 0 {
+			return fmt.Errorf("room_types[%d].total_rooms must be > 0, got %d", i, rt.TotalRooms)
+		}
+		if rt.MaxOccupancy <= 0 {
+			return fmt.Errorf("room_types[%d].max_occupancy must be > 0, got %d", i, rt.MaxOccupancy)
+		}
+		if rt.CostPerNight < 0 {
+			return fmt.Errorf("room_types[%d].cost_per_night must be >= 0, got %v", i, rt.CostPerNight)
+		}
+		if err := validateAmenityCodes(fmt.Sprintf("room_types[%d].amenities", i), rt.Amenities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateCancellationPolicy(policy *hotelsystem.CancellationPolicy) error {
+	if policy == nil {
+		return errors.New("cancellation_policy cannot be empty")
+	}
+	if policy.FreeCancellationHours < 0 {
+		return fmt.Errorf("free_cancellation_hours must be >= 0, got %d", policy.FreeCancellationHours)
+	}
+	if policy.PenaltyPercent < 0 || policy.PenaltyPercent > 100 {
+		return fmt.Errorf("penalty_percent must be between 0 and 100, got %v", policy.PenaltyPercent)
+	}
+	return nil
+}
+
+func ValidateSetCancellationPolicyRequest(req *hotelsystem.SetCancellationPolicyRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	return ValidateCancellationPolicy(req.CancellationPolicy)
+}
+
+func ValidateUpdateHotelRequest(req *hotelsystem.UpdateHotelRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if req.Name != nil && *req.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if req.Pincode != nil {
+		if _, err := strconv.Atoi(*req.Pincode); err != nil {
+			return fmt.Errorf("pincode must be a number, got %q", *req.Pincode)
+		}
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return errors.New("latitude and longitude must be given together")
+	}
+	if req.Latitude != nil {
+		if err := validateCoordinates(*req.Latitude, *req.Longitude); err != nil {
+			return err
+		}
+	}
+	seen := make(map[int64]bool)
+	for i, rt := range req.RoomTypes {
+		if rt == nil || rt.RoomTypeID <= 0 {
+			return fmt.Errorf("room_types[%d].room_type_id must be > 0", i)
+		}
+		if seen[rt.RoomTypeID] {
+			return fmt.Errorf("room type %d is listed more than once", rt.RoomTypeID)
+		}
+		seen[rt.RoomTypeID] = true
+		if rt.Name != nil && *rt.Name == "" {
+			return fmt.Errorf("room_types[%d].name cannot be empty", i)
+		}
+		if rt.TotalRooms != nil && *rt.TotalRooms <= 0 {
+			return fmt.Errorf("room_types[%d].total_rooms must be > 0, got %d", i, *rt.TotalRooms)
+		}
+		if rt.MaxOccupancy != nil && *rt.MaxOccupancy <= 0 {
+			return fmt.Errorf("room_types[%d].max_occupancy must be > 0, got %d", i, *rt.MaxOccupancy)
+		}
+		if rt.CostPerNight != nil && *rt.CostPerNight < 0 {
+			return fmt.Errorf("room_types[%d].cost_per_night must be >= 0, got %v", i, *rt.CostPerNight)
+		}
+	}
+	return nil
+}
+
+func ValidateHotelStatusRequest(req *hotelsystem.HotelStatusRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	return nil
+}
+
+func ValidateGetHotelImagesRequest(req *hotelsystem.GetHotelImagesRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	return nil
+}
+
+// ValidateReorderHotelImagesRequest checks that image_ids names no image twice; that it names every
+// image of the hotel is checked against the gallery
+func ValidateReorderHotelImagesRequest(req *hotelsystem.ReorderHotelImagesRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if len(req.ImageIDs) == 0 {
+		return errors.New("image_ids cannot be empty")
+	}
+	seen := make(map[string]bool, len(req.ImageIDs))
+	for i, id := range req.ImageIDs {
+		if id == "" {
+			return fmt.Errorf("image_ids[%d] cannot be empty", i)
+		}
+		if seen[id] {
+			return fmt.Errorf("image %s is listed more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+func ValidateDeleteHotelImageRequest(req *hotelsystem.DeleteHotelImageRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if req.ImageID == "" {
+		return errors.New("image_id cannot be empty")
+	}
+	return nil
+}
+
+// ValidateSearchHotelsRequest checks an availability search: the same filters as the hotel listing,
+// plus the stay dates, rooms and guests the listing does not take.
+func ValidateSearchHotelsRequest(req *hotelsystem.GetHotelsListRequest) error {
+	if err := validateHotelFilters(req); err != nil {
+		return err
+	}
+	if req.NumRooms <= 0 {
+		return fmt.Errorf("num_rooms must be > 0, got %d", req.NumRooms)
+	}
+	if req.NumGuests <= 0 {
+		return fmt.Errorf("num_guests must be > 0, got %d", req.NumGuests)
+	}
+	checkIn, err := time.Parse(constants.DateFormat, req.CheckInDate)
+	if err != nil {
+		return fmt.Errorf("check_in_date must be in YYYY-MM-DD format, got %s", req.CheckInDate)
+	}
+	checkOut, err := time.Parse(constants.DateFormat, req.CheckOutDate)
+	if err != nil {
+		return fmt.Errorf("check_out_date must be in YYYY-MM-DD format, got %s", req.CheckOutDate)
+	}
+	today := time.Now().Truncate(24 * time.Hour)
+	if checkIn.Before(today) {
+		return fmt.Errorf("check_in_date cannot be in the past")
+	}
+	if !checkOut.After(checkIn) {
+		return fmt.Errorf("check_out_date must be after check_in_date")
+	}
+	if checkOut.Sub(checkIn) > constants.MaxStayNights*24*time.Hour {
+		return fmt.Errorf("stay cannot be longer than %d nights", constants.MaxStayNights)
 	}
-	// (Optionally) enforce a maximum for limit, e.g.:
-	// if req.Limit > 100 {
-	//     return fmt.Errorf("limit cannot exceed 100, got %d", req.Limit)
-	// }
 	return nil
 }
 
@@ -53,6 +390,9 @@ func ValidateBookHotelRequest(req *hotelsystem.BookHotelRequest) error {
 	if req == nil {
 		return errors.New("request cannot be nil")
 	}
+	if req.RoomTypeID <= 0 {
+		return fmt.Errorf("room_type_id must be > 0, got %d", req.RoomTypeID)
+	}
 	if req.NumRooms <= 0 {
 		return fmt.Errorf("num_rooms must be > 0, got %d", req.NumRooms)
 	}
@@ -74,6 +414,34 @@ func ValidateBookHotelRequest(req *hotelsystem.BookHotelRequest) error {
 }
 
 func ValidateGetBookingDetailsByIdRequest(req *hotelsystem.GetBookingDetailsRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	return validateBookingLookup(req.BookingID, req.BookingReference)
+}
+
+func ValidatePaymentStatusRequest(req *hotelsystem.PaymentStatusRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	return validateBookingLookup(req.BookingID, req.BookingReference)
+}
+
+// validateBookingLookup checks a booking identified by its reference or, failing that, its id
+func validateBookingLookup(bookingId int64, bookingReference string) error {
+	if bookingReference != "" {
+		if len(bookingReference) != constants.BookingReferenceLength {
+			return fmt.Errorf("booking_reference must be %d characters", constants.BookingReferenceLength)
+		}
+		return nil
+	}
+	if bookingId <= 0 {
+		return fmt.Errorf("booking_id must be > 0, got %d", bookingId)
+	}
+	return nil
+}
+
+func ValidateCancelBookingRequest(req *hotelsystem.CancelBookingRequest) error {
 	if req == nil {
 		return errors.New("request cannot be nil")
 	}
@@ -82,3 +450,392 @@ func ValidateGetBookingDetailsByIdRequest(req *hotelsystem.GetBookingDetailsRequ
 	}
 	return nil
 }
+
+// ValidateModifyBookingRequest checks the booking and whichever of the stay's check-in date, length and
+// room count are being changed
+func ValidateModifyBookingRequest(req *hotelsystem.ModifyBookingRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if err := validateBookingLookup(req.BookingID, req.BookingReference); err != nil {
+		return err
+	}
+	if req.CheckInDate == "" && req.NumDays == 0 && req.NumRooms == 0 {
+		return errors.New("at least one of check_in_date, num_days and num_rooms must be set")
+	}
+	if req.NumRooms < 0 {
+		return fmt.Errorf("num_rooms must be > 0, got %d", req.NumRooms)
+	}
+	if req.NumDays < 0 {
+		return fmt.Errorf("num_days must be > 0, got %d", req.NumDays)
+	}
+	if req.NumDays > constants.MaxStayNights {
+		return fmt.Errorf("stay cannot be longer than %d nights", constants.MaxStayNights)
+	}
+	if req.CheckInDate != "" {
+		checkInDate, err := time.Parse(constants.DateFormat, req.CheckInDate)
+		if err != nil {
+			return fmt.Errorf("check_in_date must be in YYYY-MM-DD format, got %s", req.CheckInDate)
+		}
+		if checkInDate.Before(time.Now().Truncate(24 * time.Hour)) {
+			return errors.New("check_in_date cannot be in the past")
+		}
+	}
+	return nil
+}
+
+func ValidateRequestRefundRequest(req *hotelsystem.RequestRefundRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.BookingID <= 0 {
+		return fmt.Errorf("booking_id must be > 0, got %d", req.BookingID)
+	}
+	if req.Amount < 0 {
+		return fmt.Errorf("amount must be >= 0, got %v", req.Amount)
+	}
+	if req.Reason == "" {
+		return errors.New("reason cannot be empty")
+	}
+	return nil
+}
+
+func ValidateGetRefundsRequest(req *hotelsystem.GetRefundsRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.BookingID <= 0 {
+		return fmt.Errorf("booking_id must be > 0, got %d", req.BookingID)
+	}
+	return nil
+}
+
+func ValidateGetWebhookEventsRequest(req *hotelsystem.GetWebhookEventsRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	switch constants.WebhookEventStatus(req.Status) {
+	case "", constants.WEBHOOK_PENDING, constants.WEBHOOK_PROCESSING, constants.WEBHOOK_PROCESSED, constants.WEBHOOK_DEAD:
+	default:
+		return fmt.Errorf("unknown status %q", req.Status)
+	}
+	if req.Limit <= 0 {
+		return fmt.Errorf("limit must be > 0, got %d", req.Limit)
+	}
+	if req.Offset < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+func ValidateReplayWebhookEventRequest(req *hotelsystem.ReplayWebhookEventRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.EventID == "" {
+		return errors.New("event_id cannot be empty")
+	}
+	return nil
+}
+
+func ValidateSetUserRoleRequest(req *hotelsystem.SetUserRoleRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.UserID <= 0 {
+		return fmt.Errorf("user_id must be > 0, got %d", req.UserID)
+	}
+	switch constants.UserRole(req.Role) {
+	case constants.ROLE_GUEST, constants.ROLE_HOTEL_STAFF, constants.ROLE_ADMIN, constants.ROLE_PARTNER:
+	default:
+		return fmt.Errorf("unknown role %q", req.Role)
+	}
+	return nil
+}
+
+func ValidateHotelStaffRequest(req *hotelsystem.HotelStaffRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if req.UserID <= 0 {
+		return fmt.Errorf("user_id must be > 0, got %d", req.UserID)
+	}
+	return nil
+}
+
+func ValidateGetHotelBookingsRequest(req *hotelsystem.GetHotelBookingsRequest) error {
+	if req == nil {
+		return errors.New("request cannot be nil")
+	}
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	switch store.BookingStatus(req.Status) {
+	case "", store.BOOKING_PENDING, store.BOOKING_CONFIRMED, store.BOOKING_CANCELLED, store.BOOKING_EXPIRED,
+		store.BOOKING_FAILED, store.BOOKING_COMPLETED:
+	default:
+		return fmt.Errorf("unknown status %q", req.Status)
+	}
+	if req.Limit <= 0 {
+		return fmt.Errorf("limit must be > 0, got %d", req.Limit)
+	}
+	if req.Offset < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+func ValidateGetMyBookingsRequest(req *hotelsystem.GetMyBookingsRequest) error {
+	switch store.BookingStatus(req.Status) {
+	case "", store.BOOKING_PENDING, store.BOOKING_CONFIRMED, store.BOOKING_CANCELLED, store.BOOKING_EXPIRED,
+		store.BOOKING_FAILED, store.BOOKING_COMPLETED:
+	default:
+		return fmt.Errorf("unknown status %q", req.Status)
+	}
+	switch constants.BookingPeriod(req.Period) {
+	case "", constants.PERIOD_UPCOMING, constants.PERIOD_PAST:
+	default:
+		return fmt.Errorf("period must be %s or %s, got %q", constants.PERIOD_UPCOMING, constants.PERIOD_PAST, req.Period)
+	}
+	var fromDate, toDate time.Time
+	var err error
+	if req.FromDate != "" {
+		if fromDate, err = time.Parse(constants.DateFormat, req.FromDate); err != nil {
+			return errors.New("from_date must be in YYYY-MM-DD format")
+		}
+	}
+	if req.ToDate != "" {
+		if toDate, err = time.Parse(constants.DateFormat, req.ToDate); err != nil {
+			return errors.New("to_date must be in YYYY-MM-DD format")
+		}
+	}
+	if req.FromDate != "" && req.ToDate != "" && toDate.Before(fromDate) {
+		return errors.New("to_date cannot be before from_date")
+	}
+	if req.Limit <= 0 || req.Limit > constants.MaxPageSize {
+		return fmt.Errorf("limit must be between 1 and %d, got %d", constants.MaxPageSize, req.Limit)
+	}
+	if req.Offset < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+func ValidateUnlockAccountRequest(req *pb2.UnlockAccountRequest) error {
+	if req.Username == "" {
+		return errors.New("username cannot be empty")
+	}
+	return nil
+}
+
+func ValidateGetAuthAuditEventsRequest(req *pb2.GetAuthAuditEventsRequest) error {
+	if req.Limit <= 0 {
+		return fmt.Errorf("limit must be > 0, got %d", req.Limit)
+	}
+	if req.Offset < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+// validateSecondFactor checks that exactly one of a TOTP code and a recovery code is given
+func validateSecondFactor(code, recoveryCode string) error {
+	if code == "" && recoveryCode == "" {
+		return errors.New("code or recovery_code is required")
+	}
+	if code != "" && recoveryCode != "" {
+		return errors.New("only one of code and recovery_code can be given")
+	}
+	if code != "" && len(code) != constants.TOTPDigits {
+		return fmt.Errorf("code must be %d digits", constants.TOTPDigits)
+	}
+	return nil
+}
+
+func ValidateConfirmTotpRequest(req *pb2.ConfirmTotpRequest) error {
+	if len(req.Code) != constants.TOTPDigits {
+		return fmt.Errorf("code must be %d digits", constants.TOTPDigits)
+	}
+	return nil
+}
+
+func ValidateDisableTotpRequest(req *pb2.DisableTotpRequest) error {
+	return validateSecondFactor(req.Code, req.RecoveryCode)
+}
+
+func ValidateLoginTotpRequest(req *pb2.LoginTotpRequest) error {
+	if req.PreAuthToken == "" {
+		return errors.New("pre_auth_token cannot be empty")
+	}
+	return validateSecondFactor(req.Code, req.RecoveryCode)
+}
+
+func ValidateCreateApiKeyRequest(req *pb2.CreateApiKeyRequest) error {
+	if req.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if len(req.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(constants.APIKeyScopes, constants.APIKeyScope(scope)) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if req.PartnerUserId < 0 {
+		return fmt.Errorf("partner_user_id must be >= 0, got %d", req.PartnerUserId)
+	}
+	return nil
+}
+
+func ValidateGetApiKeysRequest(req *pb2.GetApiKeysRequest) error {
+	if req.PartnerUserId < 0 {
+		return fmt.Errorf("partner_user_id must be >= 0, got %d", req.PartnerUserId)
+	}
+	return nil
+}
+
+func ValidateRevokeApiKeyRequest(req *pb2.RevokeApiKeyRequest) error {
+	if req.KeyId == "" {
+		return errors.New("key_id cannot be empty")
+	}
+	if req.PartnerUserId < 0 {
+		return fmt.Errorf("partner_user_id must be >= 0, got %d", req.PartnerUserId)
+	}
+	return nil
+}
+
+// isAmenityCode reports whether code is made of lowercase letters, digits and underscores
+func isAmenityCode(code string) bool {
+	if code == "" {
+		return false
+	}
+	for _, c := range code {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// validateAmenityCodes checks a list of amenity codes; that they are in the catalog is checked against it
+func validateAmenityCodes(field string, codes []string) error {
+	if len(codes) > constants.MaxAmenities {
+		return fmt.Errorf("%s cannot list more than %d amenities", field, constants.MaxAmenities)
+	}
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if !isAmenityCode(code) {
+			return fmt.Errorf("%s: invalid amenity code %q", field, code)
+		}
+		if seen[code] {
+			return fmt.Errorf("%s: amenity %s is listed more than once", field, code)
+		}
+		seen[code] = true
+	}
+	return nil
+}
+
+func ValidateAmenityRequest(req *hotelsystem.AmenityRequest) error {
+	if !isAmenityCode(req.Code) {
+		return fmt.Errorf("code must be lowercase letters, digits and underscores, got %q", req.Code)
+	}
+	if req.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if req.Category == "" {
+		return errors.New("category cannot be empty")
+	}
+	return nil
+}
+
+func ValidateDeleteAmenityRequest(req *hotelsystem.DeleteAmenityRequest) error {
+	if req.Code == "" {
+		return errors.New("code cannot be empty")
+	}
+	return nil
+}
+
+func ValidateSetHotelAmenitiesRequest(req *hotelsystem.SetHotelAmenitiesRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if err := validateAmenityCodes("amenities", req.Amenities); err != nil {
+		return err
+	}
+	seen := make(map[int64]bool)
+	for i, rt := range req.RoomTypes {
+		if rt == nil || rt.RoomTypeID <= 0 {
+			return fmt.Errorf("room_types[%d].room_type_id must be > 0", i)
+		}
+		if seen[rt.RoomTypeID] {
+			return fmt.Errorf("room type %d is listed more than once", rt.RoomTypeID)
+		}
+		seen[rt.RoomTypeID] = true
+		if err := validateAmenityCodes(fmt.Sprintf("room_types[%d].amenities", i), rt.Amenities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateAddReviewRequest(req *hotelsystem.AddReviewRequest) error {
+	if err := validateBookingLookup(req.BookingID, req.BookingReference); err != nil {
+		return err
+	}
+	scores := []struct {
+		name  string
+		score int32
+	}{
+		{"overall", req.Overall},
+		{"cleanliness", req.Cleanliness},
+		{"service", req.Service},
+		{"location", req.Location},
+		{"value", req.Value},
+	}
+	for _, s := range scores {
+		if s.score < constants.MinReviewScore || s.score > constants.MaxReviewScore {
+			return fmt.Errorf("%s must be between %d and %d, got %d", s.name, constants.MinReviewScore, constants.MaxReviewScore, s.score)
+		}
+	}
+	if len(req.Comment) > constants.MaxReviewCommentLength {
+		return fmt.Errorf("comment cannot be longer than %d characters", constants.MaxReviewCommentLength)
+	}
+	return nil
+}
+
+func ValidateGetHotelReviewsRequest(req *hotelsystem.GetHotelReviewsRequest) error {
+	if req.HotelID <= 0 {
+		return fmt.Errorf("hotel_id must be > 0, got %d", req.HotelID)
+	}
+	if req.Limit <= 0 || req.Limit > constants.MaxPageSize {
+		return fmt.Errorf("limit must be between 1 and %d, got %d", constants.MaxPageSize, req.Limit)
+	}
+	if req.Offset < 0 {
+		return errors.New("offset must be >= 0")
+	}
+	return nil
+}
+
+func ValidateReplyToReviewRequest(req *hotelsystem.ReplyToReviewRequest) error {
+	if req.ReviewID <= 0 {
+		return fmt.Errorf("review_id must be > 0, got %d", req.ReviewID)
+	}
+	if strings.TrimSpace(req.Reply) == "" {
+		return errors.New("reply cannot be empty")
+	}
+	if len(req.Reply) > constants.MaxReviewReplyLength {
+		return fmt.Errorf("reply cannot be longer than %d characters", constants.MaxReviewReplyLength)
+	}
+	return nil
+}
+
+func ValidateHideReviewRequest(req *hotelsystem.HideReviewRequest) error {
+	if req.ReviewID <= 0 {
+		return fmt.Errorf("review_id must be > 0, got %d", req.ReviewID)
+	}
+	return nil
+}